@@ -0,0 +1,35 @@
+// Command reindex rebuilds the embedded search index at SEARCH_INDEX_PATH
+// from the database, without starting the server. Stop the server first:
+// a running one keeps its own copy of the index and would save over the
+// rebuilt file.
+package main
+
+import (
+    "log"
+
+    "devlink-backend/internal/config"
+    "devlink-backend/internal/searchindex"
+    "devlink-backend/internal/services"
+)
+
+func main() {
+    cfg := config.LoadConfig()
+    db := config.ConnectDatabase(cfg)
+
+    // Opened directly rather than through SEARCH_BACKEND, so an index can be
+    // built before switching the server over to it
+    index, err := searchindex.Open(cfg.SearchIndexPath)
+    if err != nil {
+        log.Fatalf("Cannot open SEARCH_INDEX_PATH %q: %v", cfg.SearchIndexPath, err)
+    }
+
+    indexed, err := services.RebuildSearchIndex(db, index)
+    if err != nil {
+        log.Fatal("Rebuilding the search index failed:", err)
+    }
+    if err := index.Flush(); err != nil {
+        log.Fatal("Saving the search index failed:", err)
+    }
+
+    log.Printf("Indexed %d resource(s) into %s", indexed, cfg.SearchIndexPath)
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+    "context"
+    "errors"
     "log"
     "net/http"
     "os"
+    "os/signal"
     "strings"
+    "syscall"
     "time"
 
     "devlink-backend/internal/config"
     "devlink-backend/internal/handlers"
     "devlink-backend/internal/middleware"
+    "devlink-backend/internal/models"
     "devlink-backend/internal/services"
     "github.com/gin-gonic/gin"
     "github.com/gin-contrib/cors"
@@ -19,16 +24,117 @@ func main() {
     // Load configuration
     cfg := config.LoadConfig()
     
+    // Cancelled on SIGINT or SIGTERM to stop the background workers
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    
     // Connect to database
     db := config.ConnectDatabase(cfg)
     
+    // One-time move of the old free-text tags column into the tags table
+    if err := services.MigrateLegacyTags(db); err != nil {
+        log.Fatal("Failed to migrate resource tags:", err)
+    }
+    
+    // Owner of the public resources of deleted accounts
+    if err := services.EnsureDeletedUser(db); err != nil {
+        log.Fatal("Failed to create the deleted-user placeholder:", err)
+    }
+    
+    // Outgoing mail (SMTP or local outbox)
+    mail := config.NewMailer(cfg)
+    
+    // Search in Postgres, or in the embedded index when configured
+    var searchIndex services.SearchIndex
+    embeddedIndex := config.SearchIndex(cfg)
+    if embeddedIndex != nil {
+        // Only assign a non-nil index, a nil pointer would make a non-nil interface
+        searchIndex = embeddedIndex
+    } else if err := services.BackfillSearchVectors(db); err != nil {
+        // Index resources saved before full-text search was added, or while
+        // the embedded index was in use
+        log.Fatal("Failed to build search index:", err)
+    }
+    
     // Initialize services
-    authService := services.NewAuthService(db, cfg.JWTSecret)
-    resourceService := services.NewResourceService(db)
+    authService := services.NewAuthService(db, config.LoadKeySet(cfg), mail, cfg.AppURL, services.RegistrationPolicy{
+        Mode:           config.RegistrationMode(cfg),
+        AllowedDomains: config.AllowedEmailDomains(cfg),
+    })
+    throttleStore := config.NewLoginGuardStore(cfg)
+    loginGuard := services.NewLoginGuard(throttleStore, services.DefaultLoginGuardPolicy(), db, mail)
+    accessTokenService := services.NewAccessTokenService(db, authService)
+    oauthService := services.NewOAuthService(db, authService, config.OAuthProviders(cfg)...)
+    pageFetcher := config.MetadataFetcher(cfg)
+    resourceService := services.NewResourceService(db, services.NewVerifiedEmailPolicy(db), pageFetcher, searchIndex)
+    tagService := services.NewTagService(db, searchIndex)
+    collectionService := services.NewCollectionService(db, mail, cfg.AppURL)
+    workspaceService := services.NewWorkspaceService(db, searchIndex)
+    shareService := services.NewShareService(db, services.NewShareLinkGuard(throttleStore, services.DefaultLoginGuardPolicy()))
+    snapshotPolicy := services.DefaultSnapshotPolicy()
+    snapshotPolicy.RefreshAfter = config.SnapshotRefreshAfter(cfg)
+    snapshotService := services.NewSnapshotService(db, resourceService, pageFetcher, config.SnapshotStore(cfg), snapshotPolicy)
+    adminService := services.NewAdminService(db, authService, searchIndex)
+    accountService := services.NewAccountService(db, authService, searchIndex, services.AccountDeletionPolicy{
+        GracePeriod:           config.AccountDeletionGrace(cfg),
+        DeletePublicResources: config.DeletePublicResources(cfg),
+    })
+    
+    // Bootstrap administrators from configuration
+    if err := adminService.EnsureAdmins(config.AdminEmailList(cfg)); err != nil {
+        log.Fatal("Failed to promote admin accounts:", err)
+    }
+    
+    // Fill a new search index from the database and keep saving its changes.
+    // It stops after the HTTP server, so changes from the last requests are
+    // saved too.
+    indexCtx, stopIndex := context.WithCancel(context.Background())
+    indexDone := make(chan struct{})
+    if embeddedIndex != nil {
+        if embeddedIndex.Len() == 0 {
+            if indexed, err := adminService.RebuildSearchIndex(); err != nil {
+                log.Printf("Building the search index failed: %v", err)
+            } else {
+                log.Printf("Indexed %d resource(s) for search", indexed)
+            }
+        }
+        go func() {
+            embeddedIndex.Run(indexCtx, 10*time.Second)
+            close(indexDone)
+        }()
+    } else {
+        close(indexDone)
+    }
+    
+    // Hard-delete accounts whose deletion grace period has run out
+    go accountService.RunPurger(ctx, time.Hour)
+    
+    // Keep an eye on saved links going dead or moving
+    if interval := config.LinkCheckInterval(cfg); interval > 0 {
+        linkPolicy := services.DefaultLinkCheckPolicy()
+        linkPolicy.RecheckAfter = config.LinkRecheckAfter(cfg)
+        linkPolicy.HostDelay = config.LinkCheckHostDelay(cfg)
+        go services.NewLinkChecker(db, nil, linkPolicy).Run(ctx, interval)
+    }
+    
+    // Snapshot pages of resources that opted in to archiving
+    go snapshotService.RunArchiver(ctx, time.Hour)
     
     // Initialize handlers
-    authHandler := handlers.NewAuthHandler(authService)
+    authHandler := handlers.NewAuthHandler(authService, loginGuard)
     resourceHandler := handlers.NewResourceHandler(resourceService)
+    tagHandler := handlers.NewTagHandler(tagService)
+    collectionHandler := handlers.NewCollectionHandler(collectionService)
+    workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
+    shareHandler := handlers.NewShareHandler(shareService, cfg.AppURL)
+    snapshotHandler := handlers.NewSnapshotHandler(snapshotService)
+    sessionHandler := handlers.NewSessionHandler(authService)
+    oauthHandler := handlers.NewOAuthHandler(oauthService, cfg.AppURL)
+    mfaHandler := handlers.NewMFAHandler(authService)
+    accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService)
+    adminHandler := handlers.NewAdminHandler(adminService)
+    profileHandler := handlers.NewProfileHandler(authService)
+    accountHandler := handlers.NewAccountHandler(accountService)
     
     // Set Gin mode
     gin.SetMode(cfg.GinMode)
@@ -39,8 +145,8 @@ func main() {
     // CORS middleware
     router.Use(cors.New(cors.Config{
         AllowOrigins:     []string{"http://localhost:5173"},
-        AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+        AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+        AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.WorkspaceHeader, handlers.SharePasswordHeader},
         AllowCredentials: true,
         MaxAge: 12 * time.Hour,
     }))
@@ -53,35 +159,190 @@ func main() {
         })
     })
     
+    // Public token verification keys for other services
+    router.GET("/.well-known/jwks.json", authHandler.GetJWKS)
+    
     // API routes (these MUST come before static file serving)
     api := router.Group("/api/v1")
     {
         // Public routes
         auth := api.Group("/auth")
         {
+            auth.GET("/registration", authHandler.GetRegistration)
             auth.POST("/register", authHandler.Register)
             auth.POST("/login", authHandler.Login)
+            auth.POST("/login/mfa", authHandler.LoginMFA)
+            auth.POST("/refresh", authHandler.Refresh)
+            auth.POST("/logout", authHandler.Logout)
+            auth.POST("/password/forgot", authHandler.ForgotPassword)
+            auth.POST("/password/reset", authHandler.ResetPassword)
+            auth.POST("/email/verify", authHandler.VerifyEmail)
+            
+            // External login providers
+            auth.GET("/oauth/providers", oauthHandler.GetProviders)
+            auth.GET("/oauth/:provider/start", oauthHandler.Start)
+            auth.GET("/oauth/:provider/callback", oauthHandler.Callback)
         }
         
         // Public resources (no auth required)
         api.GET("/resources/public", resourceHandler.GetPublicResources)
         api.POST("/resources/:id/click", resourceHandler.ClickResource)
+        api.GET("/tags/popular", tagHandler.GetPopularTags)
+        
+        // Unlisted share links (no auth required)
+        api.GET("/s/:token", shareHandler.OpenShareLink)
         
         // Protected routes
         protected := api.Group("/")
         protected.Use(middleware.JWTAuth(authService))
         {
             // User profile
-            protected.GET("/profile", authHandler.GetProfile)
+            protected.GET("/profile", profileHandler.GetProfile)
+            protected.PATCH("/profile", profileHandler.UpdateProfile)
+            protected.POST("/profile/password", profileHandler.ChangePassword)
+            protected.POST("/profile/email", profileHandler.ChangeEmail)
+            
+            // Data export and account deletion
+            protected.POST("/account/export", accountHandler.Export)
+            protected.DELETE("/account", accountHandler.DeleteAccount)
+            protected.POST("/account/cancel-deletion", accountHandler.CancelDeletion)
+            protected.POST("/email/resend-verification", authHandler.ResendVerification)
+            
+            // Two-factor authentication
+            mfa := protected.Group("/2fa")
+            {
+                mfa.POST("/setup", mfaHandler.Setup)
+                mfa.POST("/enable", mfaHandler.Enable)
+                mfa.POST("/disable", mfaHandler.Disable)
+                mfa.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+            }
+            
+            // Active logins
+            sessions := protected.Group("/sessions")
+            {
+                sessions.GET("/", sessionHandler.GetSessions)
+                sessions.DELETE("/:id", sessionHandler.RevokeSession)
+                sessions.POST("/revoke-others", sessionHandler.RevokeOtherSessions)
+            }
+            
+            // Personal access tokens
+            tokens := protected.Group("/tokens")
+            {
+                tokens.GET("/", accessTokenHandler.GetTokens)
+                tokens.POST("/", accessTokenHandler.CreateToken)
+                tokens.DELETE("/:id", accessTokenHandler.RevokeToken)
+            }
             
-            // Resource management
-            resources := protected.Group("/resources")
+            // Team workspaces
+            workspaces := protected.Group("/workspaces")
             {
-                resources.POST("/", resourceHandler.CreateResource)
-                resources.GET("/", resourceHandler.GetUserResources)
-                resources.GET("/:id", resourceHandler.GetResource)
-                resources.PUT("/:id", resourceHandler.UpdateResource)
-                resources.DELETE("/:id", resourceHandler.DeleteResource)
+                workspaces.GET("/", workspaceHandler.GetWorkspaces)
+                workspaces.POST("/", workspaceHandler.CreateWorkspace)
+                workspaces.GET("/:id", workspaceHandler.GetWorkspace)
+                workspaces.PATCH("/:id", workspaceHandler.UpdateWorkspace)
+                workspaces.DELETE("/:id", workspaceHandler.DeleteWorkspace)
+                workspaces.GET("/:id/members", workspaceHandler.GetMembers)
+                workspaces.POST("/:id/members", workspaceHandler.AddMember)
+                workspaces.PATCH("/:id/members/:userId", workspaceHandler.UpdateMember)
+                workspaces.DELETE("/:id/members/:userId", workspaceHandler.RemoveMember)
+            }
+        }
+        
+        // Resource management (also reachable with personal access tokens), in the
+        // workspace named by the X-Workspace header or the personal space
+        resources := api.Group("/resources")
+        resources.Use(middleware.APIAuth(authService, accessTokenService), middleware.Workspace(workspaceService))
+        {
+            resources.POST("/", middleware.RequireScope(services.ScopeResourcesWrite), resourceHandler.CreateResource)
+            resources.POST("/preview", middleware.RequireScope(services.ScopeResourcesWrite), resourceHandler.PreviewResource)
+            resources.GET("/", middleware.RequireScope(services.ScopeResourcesRead), resourceHandler.GetUserResources)
+            resources.GET("/:id", middleware.RequireScope(services.ScopeResourcesRead), resourceHandler.GetResource)
+            resources.PUT("/:id", middleware.RequireScope(services.ScopeResourcesWrite), resourceHandler.UpdateResource)
+            resources.DELETE("/:id", middleware.RequireScope(services.ScopeResourcesWrite), resourceHandler.DeleteResource)
+            resources.POST("/:id/fix-redirect", middleware.RequireScope(services.ScopeResourcesWrite), resourceHandler.FixRedirect)
+            resources.GET("/:id/snapshots", middleware.RequireScope(services.ScopeResourcesRead), snapshotHandler.GetSnapshots)
+            resources.POST("/:id/snapshots", middleware.RequireScope(services.ScopeResourcesWrite), snapshotHandler.TakeSnapshot)
+            resources.GET("/:id/snapshots/:snapshotId", middleware.RequireScope(services.ScopeResourcesRead), snapshotHandler.ViewSnapshot)
+            resources.DELETE("/:id/snapshots/:snapshotId", middleware.RequireScope(services.ScopeResourcesWrite), snapshotHandler.DeleteSnapshot)
+        }
+        
+        // Tag curation
+        tags := api.Group("/tags")
+        tags.Use(middleware.APIAuth(authService, accessTokenService), middleware.Workspace(workspaceService))
+        {
+            tags.GET("/", middleware.RequireScope(services.ScopeResourcesRead), tagHandler.GetTags)
+            tags.PATCH("/:name", middleware.RequireScope(services.ScopeResourcesWrite), tagHandler.RenameTag)
+            tags.POST("/merge", middleware.RequireScope(services.ScopeResourcesWrite), tagHandler.MergeTags)
+        }
+        
+        // Collections
+        collections := api.Group("/collections")
+        collections.Use(middleware.APIAuth(authService, accessTokenService))
+        {
+            collections.GET("/", middleware.RequireScope(services.ScopeResourcesRead), collectionHandler.GetCollections)
+            collections.POST("/", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.CreateCollection)
+            collections.GET("/:id", middleware.RequireScope(services.ScopeResourcesRead), collectionHandler.GetCollection)
+            collections.PATCH("/:id", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.UpdateCollection)
+            collections.DELETE("/:id", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.DeleteCollection)
+            collections.POST("/:id/resources", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.AddResources)
+            collections.DELETE("/:id/resources/:resourceId", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.RemoveResource)
+            collections.GET("/:id/members", middleware.RequireScope(services.ScopeResourcesRead), collectionHandler.GetMembers)
+            collections.PATCH("/:id/members/:userId", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.UpdateMember)
+            collections.DELETE("/:id/members/:userId", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.RemoveMember)
+            collections.GET("/:id/invites", middleware.RequireScope(services.ScopeResourcesRead), collectionHandler.GetInvites)
+            collections.POST("/:id/invites", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.InviteMember)
+            collections.DELETE("/:id/invites/:inviteId", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.RevokeInvite)
+            collections.POST("/invites/accept", middleware.RequireScope(services.ScopeResourcesWrite), collectionHandler.AcceptInvite)
+        }
+        
+        // Managing share links
+        shares := api.Group("/shares")
+        shares.Use(middleware.APIAuth(authService, accessTokenService))
+        {
+            shares.GET("/", middleware.RequireScope(services.ScopeResourcesRead), shareHandler.GetShareLinks)
+            shares.POST("/", middleware.RequireScope(services.ScopeResourcesWrite), shareHandler.CreateShareLink)
+            shares.DELETE("/:id", middleware.RequireScope(services.ScopeResourcesWrite), shareHandler.RevokeShareLink)
+        }
+        
+        // Administration
+        admin := api.Group("/admin")
+        admin.Use(middleware.JWTAuth(authService))
+        {
+            // Account management is admin only
+            users := admin.Group("/users")
+            users.Use(middleware.RequireRole(models.RoleAdmin))
+            {
+                users.GET("/", adminHandler.GetUsers)
+                users.PATCH("/:id/role", adminHandler.SetUserRole)
+                users.POST("/:id/disable", adminHandler.DisableUser)
+                users.POST("/:id/enable", adminHandler.EnableUser)
+                users.POST("/:id/force-password-reset", adminHandler.ForcePasswordReset)
+            }
+            
+            // Invite codes for invite-only registration
+            invites := admin.Group("/invites")
+            invites.Use(middleware.RequireRole(models.RoleAdmin))
+            {
+                invites.GET("/", adminHandler.GetInvites)
+                invites.POST("/", adminHandler.CreateInvite)
+                invites.DELETE("/:id", adminHandler.RevokeInvite)
+            }
+            
+            // Moderators can act on public resources
+            moderation := admin.Group("/resources")
+            moderation.Use(middleware.RequireRole(models.RoleModerator))
+            {
+                moderation.GET("/", adminHandler.GetResources)
+                moderation.POST("/:id/hide", adminHandler.HideResource)
+                moderation.POST("/:id/unhide", adminHandler.UnhideResource)
+                moderation.DELETE("/:id", adminHandler.DeleteResource)
+            }
+            
+            // Maintenance of the embedded search index is admin only
+            indexing := admin.Group("/search-index")
+            indexing.Use(middleware.RequireRole(models.RoleAdmin))
+            {
+                indexing.POST("/rebuild", adminHandler.RebuildSearchIndex)
             }
         }
     }
@@ -116,6 +377,33 @@ func main() {
         }
     })
     
-    log.Printf("Server starting on port %s", cfg.Port)
-    log.Fatal(router.Run(":" + cfg.Port))
+    server := &http.Server{Addr: ":" + cfg.Port, Handler: router}
+    serverErr := make(chan error, 1)
+    go func() {
+        log.Printf("Server starting on port %s", cfg.Port)
+        serverErr <- server.ListenAndServe()
+    }()
+    
+    failed := false
+    select {
+    case <-ctx.Done():
+        log.Println("Shutting down")
+    case err := <-serverErr:
+        log.Printf("Server stopped: %v", err)
+        failed = !errors.Is(err, http.ErrServerClosed)
+    }
+    stop()
+    
+    // Let in-flight requests finish, then save the search index
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+    defer cancel()
+    if err := server.Shutdown(shutdownCtx); err != nil {
+        log.Printf("Server shutdown failed: %v", err)
+    }
+    stopIndex()
+    <-indexDone
+    
+    if failed {
+        os.Exit(1)
+    }
 }
\ No newline at end of file
@@ -0,0 +1,30 @@
+package config
+
+import (
+    "log"
+    "time"
+)
+
+// AccountDeletionGrace is how long a deleted account waits before it is
+// purged for good.
+func AccountDeletionGrace(config *Config) time.Duration {
+    grace, err := time.ParseDuration(config.AccountDeletionGrace)
+    if err != nil || grace < 0 {
+        log.Fatalf("Invalid ACCOUNT_DELETION_GRACE %q (expected a duration such as 720h)", config.AccountDeletionGrace)
+    }
+    return grace
+}
+
+// DeletePublicResources reports whether public resources of purged accounts
+// are deleted rather than kept under an anonymous owner.
+func DeletePublicResources(config *Config) bool {
+    switch config.DeletedPublicResources {
+    case "delete":
+        return true
+    case "anonymize":
+        return false
+    default:
+        log.Fatalf("Unknown DELETED_PUBLIC_RESOURCES %q (expected anonymize or delete)", config.DeletedPublicResources)
+        return false
+    }
+}
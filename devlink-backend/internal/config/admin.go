@@ -0,0 +1,7 @@
+package config
+
+// AdminEmailList returns the addresses from ADMIN_EMAILS that should hold
+// the admin role.
+func AdminEmailList(config *Config) []string {
+    return splitList(config.AdminEmails)
+}
@@ -3,19 +3,79 @@ package config
 import (
     "log"
     "os"
+    "strings"
 
     "github.com/joho/godotenv"
 )
 
+// FallbackJWTSecret is only acceptable for local development.
+const FallbackJWTSecret = "fallback-secret"
+
 type Config struct {
-    Port        string
-    DBHost      string
-    DBPort      string
-    DBUser      string
-    DBPassword  string
-    DBName      string
-    JWTSecret   string
-    GinMode     string
+    Port         string
+    DBHost       string
+    DBPort       string
+    DBUser       string
+    DBPassword   string
+    DBName       string
+    JWTSecret    string
+    JWTKeysDir   string // directory of PEM keys; when set, tokens are signed with RS256/EdDSA
+    JWTActiveKID string
+    GinMode      string
+    RedisURL     string
+    AppURL       string // public URL of the frontend, used in emailed links
+    APIURL       string // public URL of this server, used for OAuth callbacks
+
+    // Outgoing mail
+    MailDriver    string // "smtp" or "outbox"
+    MailFrom      string
+    MailOutboxDir string
+    SMTPHost      string
+    SMTPPort      string
+    SMTPUsername  string
+    SMTPPassword  string
+
+    // Login throttling backend: "memory" or "redis"
+    LoginGuardBackend string
+
+    // Comma-separated emails promoted to admin at startup
+    AdminEmails string
+
+    // Who may sign up: "open", "closed", "invite" or "domain"
+    RegistrationMode    string
+    AllowedEmailDomains string // comma-separated, for the "domain" mode
+
+    // Account deletion: grace period and "anonymize" or "delete" for public resources
+    AccountDeletionGrace   string
+    DeletedPublicResources string
+
+    // Fetching pages to fill in resource titles, descriptions and favicons
+    MetadataFetchTimeout string
+
+    // Background link checking
+    LinkCheckInterval  string // "0" disables the checker
+    LinkRecheckAfter   string
+    LinkCheckHostDelay string
+
+    // Page snapshots of resources that opted in to archiving
+    SnapshotDir          string
+    SnapshotRefreshAfter string
+
+    // Resource search: "postgres" or "index" for the embedded search index
+    SearchBackend   string
+    SearchIndexPath string
+
+    // External login providers, each enabled by setting its client ID
+    GitHubClientID     string
+    GitHubClientSecret string
+    GitHubBaseURL      string
+    GitHubAPIURL       string
+    GoogleClientID     string
+    GoogleClientSecret string
+    OIDCName           string
+    OIDCIssuer         string
+    OIDCClientID       string
+    OIDCClientSecret   string
 }
 
 func LoadConfig() *Config {
@@ -25,14 +85,60 @@ func LoadConfig() *Config {
     }
 
     return &Config{
-        Port:        getEnv("PORT", "8080"),
-        DBHost:      getEnv("DB_HOST", "localhost"),
-        DBPort:      getEnv("DB_PORT", "5432"),
-        DBUser:      getEnv("DB_USER", "postgres"),
-        DBPassword:  getEnv("DB_PASSWORD", ""),
-        DBName:      getEnv("DB_NAME", "devlink"),
-        JWTSecret:   getEnv("JWT_SECRET", "fallback-secret"),
-        GinMode:     getEnv("GIN_MODE", "debug"),
+        Port:         getEnv("PORT", "8080"),
+        DBHost:       getEnv("DB_HOST", "localhost"),
+        DBPort:       getEnv("DB_PORT", "5432"),
+        DBUser:       getEnv("DB_USER", "postgres"),
+        DBPassword:   getEnv("DB_PASSWORD", ""),
+        DBName:       getEnv("DB_NAME", "devlink"),
+        JWTSecret:    getEnv("JWT_SECRET", FallbackJWTSecret),
+        JWTKeysDir:   getEnv("JWT_KEYS_DIR", ""),
+        JWTActiveKID: getEnv("JWT_ACTIVE_KID", ""),
+        GinMode:      getEnv("GIN_MODE", "debug"),
+        RedisURL:     getEnv("REDIS_URL", "redis://localhost:6379/0"),
+        AppURL:       getEnv("APP_URL", "http://localhost:5173"),
+        APIURL:       getEnv("API_URL", "http://localhost:8080"),
+
+        MailDriver:    getEnv("MAIL_DRIVER", "outbox"),
+        MailFrom:      getEnv("MAIL_FROM", "DevLink <no-reply@devlink.local>"),
+        MailOutboxDir: getEnv("MAIL_OUTBOX_DIR", "./outbox"),
+        SMTPHost:      getEnv("SMTP_HOST", "localhost"),
+        SMTPPort:      getEnv("SMTP_PORT", "587"),
+        SMTPUsername:  getEnv("SMTP_USERNAME", ""),
+        SMTPPassword:  getEnv("SMTP_PASSWORD", ""),
+
+        LoginGuardBackend: getEnv("LOGIN_GUARD_BACKEND", "memory"),
+
+        AdminEmails: getEnv("ADMIN_EMAILS", ""),
+
+        RegistrationMode:    getEnv("REGISTRATION_MODE", "open"),
+        AllowedEmailDomains: getEnv("ALLOWED_EMAIL_DOMAINS", ""),
+
+        AccountDeletionGrace:   getEnv("ACCOUNT_DELETION_GRACE", "720h"),
+        DeletedPublicResources: getEnv("DELETED_PUBLIC_RESOURCES", "anonymize"),
+
+        MetadataFetchTimeout: getEnv("METADATA_FETCH_TIMEOUT", "5s"),
+
+        LinkCheckInterval:  getEnv("LINK_CHECK_INTERVAL", "15m"),
+        LinkRecheckAfter:   getEnv("LINK_RECHECK_AFTER", "24h"),
+        LinkCheckHostDelay: getEnv("LINK_CHECK_HOST_DELAY", "2s"),
+
+        SnapshotDir:          getEnv("SNAPSHOT_DIR", "./data/snapshots"),
+        SnapshotRefreshAfter: getEnv("SNAPSHOT_REFRESH_AFTER", "168h"),
+
+        SearchBackend:   getEnv("SEARCH_BACKEND", "postgres"),
+        SearchIndexPath: getEnv("SEARCH_INDEX_PATH", "./data/search.index"),
+
+        GitHubClientID:     getEnv("GITHUB_CLIENT_ID", ""),
+        GitHubClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
+        GitHubBaseURL:      getEnv("GITHUB_BASE_URL", "https://github.com"),
+        GitHubAPIURL:       getEnv("GITHUB_API_URL", "https://api.github.com"),
+        GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+        GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+        OIDCName:           getEnv("OIDC_NAME", "oidc"),
+        OIDCIssuer:         getEnv("OIDC_ISSUER", ""),
+        OIDCClientID:       getEnv("OIDC_CLIENT_ID", ""),
+        OIDCClientSecret:   getEnv("OIDC_CLIENT_SECRET", ""),
     }
 }
 
@@ -41,4 +147,15 @@ func getEnv(key, defaultValue string) string {
         return value
     }
     return defaultValue
+}
+
+// splitList parses a comma-separated setting, dropping empty entries.
+func splitList(value string) []string {
+    var items []string
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
 }
\ No newline at end of file
@@ -21,7 +21,29 @@ func ConnectDatabase(config *Config) *gorm.DB {
     }
 
     // Auto-migrate models
-    err = db.AutoMigrate(&models.User{}, &models.Resource{})
+    err = db.AutoMigrate(
+        &models.User{},
+        &models.Resource{},
+        &models.Tag{},
+        &models.Collection{},
+        &models.RefreshToken{},
+        &models.Session{},
+        &models.PasswordResetToken{},
+        &models.EmailVerificationToken{},
+        &models.UserIdentity{},
+        &models.OAuthState{},
+        &models.MFAChallenge{},
+        &models.RecoveryCode{},
+        &models.PersonalAccessToken{},
+        &models.ResourceClick{},
+        &models.InviteCode{},
+        &models.CollectionMember{},
+        &models.CollectionInvite{},
+        &models.Workspace{},
+        &models.WorkspaceMember{},
+        &models.ShareLink{},
+        &models.Snapshot{},
+    )
     if err != nil {
         log.Fatal("Failed to migrate database:", err)
     }
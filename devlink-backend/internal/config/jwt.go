@@ -0,0 +1,31 @@
+package config
+
+import (
+    "log"
+
+    "devlink-backend/internal/jwtkeys"
+)
+
+// LoadKeySet returns the token signing keys. With JWT_KEYS_DIR set, keys are
+// read from PEM files and JWT_ACTIVE_KID picks the one that signs; otherwise
+// tokens fall back to HS256 with JWT_SECRET.
+func LoadKeySet(config *Config) *jwtkeys.KeySet {
+    if config.JWTKeysDir != "" {
+        keys, err := jwtkeys.LoadDir(config.JWTKeysDir, config.JWTActiveKID)
+        if err != nil {
+            log.Fatal("Failed to load JWT keys:", err)
+        }
+        log.Printf("Signing tokens with key %q from %s", config.JWTActiveKID, config.JWTKeysDir)
+        return keys
+    }
+
+    // Never run a release build with a secret that is published in the source
+    if config.JWTSecret == FallbackJWTSecret {
+        if config.GinMode == "release" {
+            log.Fatal("Refusing to start in release mode with the fallback JWT secret: set JWT_KEYS_DIR or JWT_SECRET")
+        }
+        log.Println("WARNING: using the fallback JWT secret, do not use this outside development")
+    }
+
+    return jwtkeys.NewHMACKeySet(config.JWTSecret)
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+    "log"
+    "time"
+)
+
+// LinkCheckInterval is how often the link checker wakes up; 0 turns it off.
+func LinkCheckInterval(config *Config) time.Duration {
+    return parseDuration("LINK_CHECK_INTERVAL", config.LinkCheckInterval)
+}
+
+// LinkRecheckAfter is how long a link check stays fresh.
+func LinkRecheckAfter(config *Config) time.Duration {
+    return parseDuration("LINK_RECHECK_AFTER", config.LinkRecheckAfter)
+}
+
+// LinkCheckHostDelay is the minimum gap between two checks on one host.
+func LinkCheckHostDelay(config *Config) time.Duration {
+    return parseDuration("LINK_CHECK_HOST_DELAY", config.LinkCheckHostDelay)
+}
+
+func parseDuration(name, value string) time.Duration {
+    d, err := time.ParseDuration(value)
+    if err != nil || d < 0 {
+        log.Fatalf("Invalid %s %q (expected a duration such as 15m)", name, value)
+    }
+    return d
+}
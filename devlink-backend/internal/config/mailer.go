@@ -0,0 +1,21 @@
+package config
+
+import (
+    "log"
+
+    "devlink-backend/internal/mailer"
+)
+
+func NewMailer(config *Config) mailer.Mailer {
+    switch config.MailDriver {
+    case "smtp":
+        log.Printf("Sending mail via SMTP %s:%s", config.SMTPHost, config.SMTPPort)
+        return mailer.NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+    case "outbox":
+        log.Printf("Writing outgoing mail to %s", config.MailOutboxDir)
+        return mailer.NewOutboxMailer(config.MailOutboxDir, config.MailFrom)
+    default:
+        log.Fatalf("Unknown MAIL_DRIVER %q (expected smtp or outbox)", config.MailDriver)
+        return nil
+    }
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+    "log"
+
+    "devlink-backend/internal/oauth"
+)
+
+// OAuthProviders builds the login providers that have credentials configured.
+func OAuthProviders(config *Config) []oauth.Provider {
+    var providers []oauth.Provider
+
+    callback := func(name string) string {
+        return config.APIURL + "/api/v1/auth/oauth/" + name + "/callback"
+    }
+
+    if config.GitHubClientID != "" {
+        providers = append(providers, oauth.NewGitHubProvider(oauth.Config{
+            ClientID:     config.GitHubClientID,
+            ClientSecret: config.GitHubClientSecret,
+            RedirectURL:  callback("github"),
+        }, config.GitHubBaseURL, config.GitHubAPIURL, nil))
+    }
+
+    if config.GoogleClientID != "" {
+        providers = append(providers, oauth.NewGoogleProvider(oauth.Config{
+            ClientID:     config.GoogleClientID,
+            ClientSecret: config.GoogleClientSecret,
+            RedirectURL:  callback("google"),
+        }, nil))
+    }
+
+    if config.OIDCIssuer != "" && config.OIDCClientID != "" {
+        providers = append(providers, oauth.NewOIDCProvider(config.OIDCName, config.OIDCIssuer, oauth.Config{
+            ClientID:     config.OIDCClientID,
+            ClientSecret: config.OIDCClientSecret,
+            RedirectURL:  callback(config.OIDCName),
+        }, nil))
+    }
+
+    for _, provider := range providers {
+        log.Printf("OAuth login enabled for %s", provider.Name())
+    }
+    return providers
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+    "log"
+)
+
+// RegistrationMode validates REGISTRATION_MODE. The "domain" mode needs at
+// least one entry in ALLOWED_EMAIL_DOMAINS.
+func RegistrationMode(config *Config) string {
+    switch config.RegistrationMode {
+    case "open", "closed", "invite":
+        return config.RegistrationMode
+    case "domain":
+        if len(AllowedEmailDomains(config)) == 0 {
+            log.Fatal("REGISTRATION_MODE=domain requires ALLOWED_EMAIL_DOMAINS")
+        }
+        return config.RegistrationMode
+    default:
+        log.Fatalf("Unknown REGISTRATION_MODE %q (expected open, closed, invite or domain)", config.RegistrationMode)
+        return ""
+    }
+}
+
+func AllowedEmailDomains(config *Config) []string {
+    return splitList(config.AllowedEmailDomains)
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+    "log"
+
+    "devlink-backend/internal/searchindex"
+)
+
+// SearchIndex opens the embedded search index when SEARCH_BACKEND is
+// "index". It returns nil for "postgres", which searches in the database.
+func SearchIndex(config *Config) *searchindex.Index {
+    switch config.SearchBackend {
+    case "index":
+        index, err := searchindex.Open(config.SearchIndexPath)
+        if err != nil {
+            log.Fatalf("Cannot open SEARCH_INDEX_PATH %q: %v", config.SearchIndexPath, err)
+        }
+        log.Printf("Searching resources with the embedded index at %s", config.SearchIndexPath)
+        return index
+    case "postgres":
+        return nil
+    default:
+        log.Fatalf("Unknown SEARCH_BACKEND %q (expected postgres or index)", config.SearchBackend)
+        return nil
+    }
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+    "log"
+    "time"
+
+    "devlink-backend/internal/storage"
+)
+
+// SnapshotStore opens the local directory page snapshots are kept in.
+func SnapshotStore(config *Config) storage.Store {
+    store, err := storage.NewLocalStore(config.SnapshotDir)
+    if err != nil {
+        log.Fatalf("Cannot use SNAPSHOT_DIR %q: %v", config.SnapshotDir, err)
+    }
+    return store
+}
+
+// SnapshotRefreshAfter is how old an archived page's latest snapshot must be
+// before another one is taken.
+func SnapshotRefreshAfter(config *Config) time.Duration {
+    return parseDuration("SNAPSHOT_REFRESH_AFTER", config.SnapshotRefreshAfter)
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "devlink-backend/internal/throttle"
+    "github.com/redis/go-redis/v9"
+)
+
+// NewLoginGuardStore picks the counter backend for login throttling.
+func NewLoginGuardStore(config *Config) throttle.Store {
+    switch config.LoginGuardBackend {
+    case "redis":
+        options, err := redis.ParseURL(config.RedisURL)
+        if err != nil {
+            log.Fatal("Invalid REDIS_URL:", err)
+        }
+
+        client := redis.NewClient(options)
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        if err := client.Ping(ctx).Err(); err != nil {
+            log.Fatal("Failed to connect to Redis:", err)
+        }
+
+        log.Println("Login throttling backed by Redis")
+        return throttle.NewRedisStore(client, "devlink:")
+    case "memory":
+        return throttle.NewMemoryStore()
+    default:
+        log.Fatalf("Unknown LOGIN_GUARD_BACKEND %q (expected memory or redis)", config.LoginGuardBackend)
+        return nil
+    }
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+    "log"
+    "time"
+
+    "devlink-backend/internal/webpage"
+)
+
+// MetadataFetcher builds the page fetcher used for link previews. It only
+// connects to public addresses.
+func MetadataFetcher(config *Config) *webpage.Fetcher {
+    timeout, err := time.ParseDuration(config.MetadataFetchTimeout)
+    if err != nil || timeout <= 0 {
+        log.Fatalf("Invalid METADATA_FETCH_TIMEOUT %q (expected a duration such as 5s)", config.MetadataFetchTimeout)
+    }
+    return webpage.NewFetcher(nil, timeout)
+}
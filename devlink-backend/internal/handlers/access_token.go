@@ -0,0 +1,125 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type AccessTokenHandler struct {
+    accessTokenService *services.AccessTokenService
+}
+
+type AccessTokenResponse struct {
+    ID         uint     `json:"id"`
+    Name       string   `json:"name"`
+    Prefix     string   `json:"prefix"`
+    Scopes     []string `json:"scopes"`
+    ExpiresAt  *string  `json:"expires_at"`
+    LastUsedAt *string  `json:"last_used_at"`
+    CreatedAt  string   `json:"created_at"`
+}
+
+// CreatedAccessTokenResponse is the only response that includes the raw token.
+type CreatedAccessTokenResponse struct {
+    AccessTokenResponse
+    Token string `json:"token"`
+}
+
+func NewAccessTokenHandler(accessTokenService *services.AccessTokenService) *AccessTokenHandler {
+    return &AccessTokenHandler{
+        accessTokenService: accessTokenService,
+    }
+}
+
+func (h *AccessTokenHandler) CreateToken(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.CreateAccessTokenRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    token, raw, err := h.accessTokenService.CreateToken(userID.(uint), req)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusCreated, CreatedAccessTokenResponse{
+        AccessTokenResponse: h.toAccessTokenResponse(*token),
+        Token:               raw,
+    })
+}
+
+func (h *AccessTokenHandler) GetTokens(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    tokens, err := h.accessTokenService.GetUserTokens(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := make([]AccessTokenResponse, 0, len(tokens))
+    for _, token := range tokens {
+        response = append(response, h.toAccessTokenResponse(token))
+    }
+
+    c.JSON(http.StatusOK, gin.H{"tokens": response})
+}
+
+func (h *AccessTokenHandler) RevokeToken(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+        return
+    }
+
+    if err := h.accessTokenService.RevokeToken(uint(tokenID), userID.(uint)); err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
+
+// Helper methods
+func (h *AccessTokenHandler) toAccessTokenResponse(token models.PersonalAccessToken) AccessTokenResponse {
+    response := AccessTokenResponse{
+        ID:        token.ID,
+        Name:      token.Name,
+        Prefix:    token.Prefix,
+        Scopes:    services.TokenScopes(&token),
+        CreatedAt: token.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+
+    if token.ExpiresAt != nil {
+        expiresAt := token.ExpiresAt.Format("2006-01-02T15:04:05Z")
+        response.ExpiresAt = &expiresAt
+    }
+    if token.LastUsedAt != nil {
+        lastUsedAt := token.LastUsedAt.Format("2006-01-02T15:04:05Z")
+        response.LastUsedAt = &lastUsedAt
+    }
+
+    return response
+}
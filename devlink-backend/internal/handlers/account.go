@@ -0,0 +1,96 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "time"
+
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+    accountService *services.AccountService
+}
+
+type DeleteAccountRequest struct {
+    Password string `json:"password" binding:"required"`
+    Code     string `json:"code"` // TOTP or recovery code, required when 2FA is on
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+    return &AccountHandler{
+        accountService: accountService,
+    }
+}
+
+func (h *AccountHandler) Export(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    archive, err := h.accountService.Export(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export account data"})
+        return
+    }
+
+    filename := fmt.Sprintf("devlink-export-%s.zip", time.Now().UTC().Format("2006-01-02"))
+    c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+    c.Header("Cache-Control", "no-store")
+    c.Data(http.StatusOK, "application/zip", archive)
+}
+
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req DeleteAccountRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    user, err := h.accountService.ScheduleDeletion(userID.(uint), req.Password, req.Code)
+    if err != nil {
+        switch {
+        case errors.Is(err, services.ErrInvalidPassword), errors.Is(err, services.ErrInvalidMFACode):
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        case errors.Is(err, services.ErrDeletionAlreadyScheduled):
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+        }
+        return
+    }
+
+    c.JSON(http.StatusAccepted, gin.H{
+        "message":      "Account scheduled for deletion, log in and cancel before then to keep it",
+        "delete_after": user.DeleteAfter.Format("2006-01-02T15:04:05Z"),
+    })
+}
+
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    if err := h.accountService.CancelDeletion(userID.(uint)); err != nil {
+        if errors.Is(err, services.ErrDeletionNotScheduled) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
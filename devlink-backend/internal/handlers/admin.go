@@ -0,0 +1,348 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+    adminService *services.AdminService
+}
+
+type SetRoleRequest struct {
+    Role string `json:"role" binding:"required"`
+}
+
+type AdminUserResponse struct {
+    ID            uint   `json:"id"`
+    Name          string `json:"name"`
+    Email         string `json:"email"`
+    Role          string `json:"role"`
+    EmailVerified bool   `json:"email_verified"`
+    TOTPEnabled   bool   `json:"totp_enabled"`
+    Disabled      bool   `json:"disabled"`
+    CreatedAt     string `json:"created_at"`
+}
+
+type AdminResourceResponse struct {
+    ID         uint   `json:"id"`
+    Title      string `json:"title"`
+    URL        string `json:"url"`
+    Category   string `json:"category"`
+    Hidden     bool   `json:"hidden"`
+    ClickCount int    `json:"click_count"`
+    UserID     uint   `json:"user_id"`
+    OwnerName  string `json:"owner_name"`
+    OwnerEmail string `json:"owner_email"`
+    CreatedAt  string `json:"created_at"`
+}
+
+type InviteResponse struct {
+    ID        uint    `json:"id"`
+    Prefix    string  `json:"prefix"`
+    Note      string  `json:"note"`
+    MaxUses   int     `json:"max_uses"`
+    Uses      int     `json:"uses"`
+    ExpiresAt *string `json:"expires_at"`
+    Revoked   bool    `json:"revoked"`
+    CreatedAt string  `json:"created_at"`
+}
+
+type CreateInviteResponse struct {
+    InviteResponse
+    Code string `json:"code"` // only returned once, at creation
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+    return &AdminHandler{
+        adminService: adminService,
+    }
+}
+
+func (h *AdminHandler) GetUsers(c *gin.Context) {
+    var filters services.AdminUserFilters
+    if err := c.ShouldBindQuery(&filters); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    users, total, err := h.adminService.GetUsers(filters)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := make([]AdminUserResponse, 0, len(users))
+    for _, user := range users {
+        response = append(response, h.toAdminUserResponse(user))
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "users": response,
+        "total": total,
+        "page":  filters.Page,
+        "limit": filters.Limit,
+        "pages": int((total + int64(filters.Limit) - 1) / int64(filters.Limit)),
+    })
+}
+
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+    userID, ok := h.parseID(c)
+    if !ok {
+        return
+    }
+
+    var req SetRoleRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    user, err := h.adminService.SetUserRole(c.GetUint("user_id"), userID, req.Role)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toAdminUserResponse(*user))
+}
+
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+    userID, ok := h.parseID(c)
+    if !ok {
+        return
+    }
+
+    if _, err := h.adminService.DisableUser(c.GetUint("user_id"), userID); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "User disabled"})
+}
+
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+    userID, ok := h.parseID(c)
+    if !ok {
+        return
+    }
+
+    if _, err := h.adminService.EnableUser(userID); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "User enabled"})
+}
+
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+    userID, ok := h.parseID(c)
+    if !ok {
+        return
+    }
+
+    if err := h.adminService.ForcePasswordReset(userID); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Password reset forced, the user has been emailed a reset link"})
+}
+
+func (h *AdminHandler) GetResources(c *gin.Context) {
+    var filters services.AdminResourceFilters
+    if err := c.ShouldBindQuery(&filters); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    resources, total, err := h.adminService.GetPublicResources(filters)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := make([]AdminResourceResponse, 0, len(resources))
+    for _, resource := range resources {
+        response = append(response, h.toAdminResourceResponse(resource))
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "resources": response,
+        "total":     total,
+        "page":      filters.Page,
+        "limit":     filters.Limit,
+        "pages":     int((total + int64(filters.Limit) - 1) / int64(filters.Limit)),
+    })
+}
+
+func (h *AdminHandler) HideResource(c *gin.Context) {
+    h.setResourceHidden(c, true)
+}
+
+func (h *AdminHandler) UnhideResource(c *gin.Context) {
+    h.setResourceHidden(c, false)
+}
+
+func (h *AdminHandler) DeleteResource(c *gin.Context) {
+    resourceID, ok := h.parseID(c)
+    if !ok {
+        return
+    }
+
+    if err := h.adminService.DeleteResource(resourceID); err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Resource deleted successfully"})
+}
+
+func (h *AdminHandler) GetInvites(c *gin.Context) {
+    invites, err := h.adminService.GetInvites()
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := make([]InviteResponse, 0, len(invites))
+    for _, invite := range invites {
+        response = append(response, h.toInviteResponse(invite))
+    }
+
+    c.JSON(http.StatusOK, gin.H{"invites": response})
+}
+
+func (h *AdminHandler) CreateInvite(c *gin.Context) {
+    var req services.CreateInviteRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    invite, code, err := h.adminService.CreateInvite(c.GetUint("user_id"), req)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusCreated, CreateInviteResponse{
+        InviteResponse: h.toInviteResponse(*invite),
+        Code:           code,
+    })
+}
+
+func (h *AdminHandler) RevokeInvite(c *gin.Context) {
+    inviteID, ok := h.parseID(c)
+    if !ok {
+        return
+    }
+
+    if err := h.adminService.RevokeInvite(inviteID); err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+}
+
+// RebuildSearchIndex refills the search index from the database.
+func (h *AdminHandler) RebuildSearchIndex(c *gin.Context) {
+    indexed, err := h.adminService.RebuildSearchIndex()
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Search index rebuilt", "indexed": indexed})
+}
+
+// Helper methods
+func (h *AdminHandler) setResourceHidden(c *gin.Context, hidden bool) {
+    resourceID, ok := h.parseID(c)
+    if !ok {
+        return
+    }
+
+    resource, err := h.adminService.SetResourceHidden(resourceID, hidden)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toAdminResourceResponse(*resource))
+}
+
+func (h *AdminHandler) parseID(c *gin.Context) (uint, bool) {
+    id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return 0, false
+    }
+    return uint(id), true
+}
+
+func (h *AdminHandler) handleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, services.ErrInvalidRole):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrCannotModifySelf), errors.Is(err, services.ErrSearchIndexOff):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrUserNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
+func (h *AdminHandler) toAdminUserResponse(user models.User) AdminUserResponse {
+    return AdminUserResponse{
+        ID:            user.ID,
+        Name:          user.Name,
+        Email:         user.Email,
+        Role:          user.Role,
+        EmailVerified: user.EmailVerifiedAt != nil,
+        TOTPEnabled:   user.TOTPEnabledAt != nil,
+        Disabled:      user.DisabledAt != nil,
+        CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
+
+func (h *AdminHandler) toAdminResourceResponse(resource models.Resource) AdminResourceResponse {
+    return AdminResourceResponse{
+        ID:         resource.ID,
+        Title:      resource.Title,
+        URL:        resource.URL,
+        Category:   resource.Category,
+        Hidden:     resource.HiddenAt != nil,
+        ClickCount: resource.ClickCount,
+        UserID:     resource.UserID,
+        OwnerName:  resource.User.Name,
+        OwnerEmail: resource.User.Email,
+        CreatedAt:  resource.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
+
+func (h *AdminHandler) toInviteResponse(invite models.InviteCode) InviteResponse {
+    response := InviteResponse{
+        ID:        invite.ID,
+        Prefix:    invite.Prefix,
+        Note:      invite.Note,
+        MaxUses:   invite.MaxUses,
+        Uses:      invite.Uses,
+        Revoked:   invite.RevokedAt != nil,
+        CreatedAt: invite.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+
+    if invite.ExpiresAt != nil {
+        expiresAt := invite.ExpiresAt.Format("2006-01-02T15:04:05Z")
+        response.ExpiresAt = &expiresAt
+    }
+
+    return response
+}
@@ -1,20 +1,26 @@
 package handlers
 
 import (
+    "errors"
+    "math"
     "net/http"
+    "strconv"
 
+    "devlink-backend/internal/models"
     "devlink-backend/internal/services"
     "github.com/gin-gonic/gin"
 )
 
 type AuthHandler struct {
     authService *services.AuthService
+    loginGuard  *services.LoginGuard
 }
 
 type RegisterRequest struct {
-    Name     string `json:"name" binding:"required,min=2"`
-    Email    string `json:"email" binding:"required,email"`
-    Password string `json:"password" binding:"required,min=6"`
+    Name       string `json:"name" binding:"required,min=2"`
+    Email      string `json:"email" binding:"required,email"`
+    Password   string `json:"password" binding:"required,min=6"`
+    InviteCode string `json:"invite_code"` // required when registration is invite-only
 }
 
 type LoginRequest struct {
@@ -22,20 +28,57 @@ type LoginRequest struct {
     Password string `json:"password" binding:"required"`
 }
 
+type LoginMFARequest struct {
+    MFAToken string `json:"mfa_token" binding:"required"`
+    Code     string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type RefreshRequest struct {
+    RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+    Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+    Token    string `json:"token" binding:"required"`
+    Password string `json:"password" binding:"required,min=6"`
+}
+
 type AuthResponse struct {
-    User  UserResponse `json:"user"`
-    Token string       `json:"token"`
+    User         UserResponse `json:"user"`
+    Token        string       `json:"token"`
+    RefreshToken string       `json:"refresh_token"`
+    ExpiresIn    int64        `json:"expires_in"`
+}
+
+type MFAChallengeResponse struct {
+    MFARequired bool   `json:"mfa_required"`
+    MFAToken    string `json:"mfa_token"`
+}
+
+type TokenResponse struct {
+    Token        string `json:"token"`
+    RefreshToken string `json:"refresh_token"`
+    ExpiresIn    int64  `json:"expires_in"`
+}
+
+type VerifyEmailRequest struct {
+    Token string `json:"token" binding:"required"`
 }
 
 type UserResponse struct {
-    ID    uint   `json:"id"`
-    Name  string `json:"name"`
-    Email string `json:"email"`
+    ID            uint   `json:"id"`
+    Name          string `json:"name"`
+    Email         string `json:"email"`
+    EmailVerified bool   `json:"email_verified"`
 }
 
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, loginGuard *services.LoginGuard) *AuthHandler {
     return &AuthHandler{
         authService: authService,
+        loginGuard:  loginGuard,
     }
 }
 
@@ -46,29 +89,43 @@ func (h *AuthHandler) Register(c *gin.Context) {
         return
     }
 
-    user, err := h.authService.Register(req.Name, req.Email, req.Password)
+    user, err := h.authService.Register(req.Name, req.Email, req.Password, req.InviteCode)
     if err != nil {
-        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        switch {
+        case errors.Is(err, services.ErrEmailTaken):
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        case errors.Is(err, services.ErrRegistrationClosed), errors.Is(err, services.ErrInviteRequired),
+            errors.Is(err, services.ErrInvalidInviteCode), errors.Is(err, services.ErrEmailDomainNotAllowed),
+            errors.Is(err, services.ErrReservedEmail):
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
         return
     }
 
     // Generate token for immediate login
-    _, token, err := h.authService.Login(user.Email, req.Password)
+    tokens, err := h.authService.StartSession(user, clientInfo(c))
+    if errors.Is(err, services.ErrUnverifiedLogin) {
+        c.JSON(http.StatusCreated, gin.H{
+            "user":                  h.toUserResponse(user),
+            "verification_required": true,
+            "message":               "Check your email and confirm your address to sign in",
+        })
+        return
+    }
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
         return
     }
 
-    response := AuthResponse{
-        User: UserResponse{
-            ID:    user.ID,
-            Name:  user.Name,
-            Email: user.Email,
-        },
-        Token: token,
-    }
+    c.JSON(http.StatusCreated, h.toAuthResponse(user, tokens))
+}
 
-    c.JSON(http.StatusCreated, response)
+// GetRegistration tells the frontend which sign-up form to show.
+func (h *AuthHandler) GetRegistration(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{"mode": h.authService.RegistrationMode()})
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -78,33 +135,246 @@ func (h *AuthHandler) Login(c *gin.Context) {
         return
     }
 
-    user, token, err := h.authService.Login(req.Email, req.Password)
+    ctx := c.Request.Context()
+    if err := h.loginGuard.Check(ctx, c.ClientIP(), req.Email); err != nil {
+        h.respondLockedOut(c, err)
+        return
+    }
+
+    result, err := h.authService.Login(req.Email, req.Password, clientInfo(c))
     if err != nil {
+        if errors.Is(err, services.ErrAccountDisabled) || errors.Is(err, services.ErrUnverifiedLogin) {
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        }
+        if !errors.Is(err, services.ErrInvalidCredentials) {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+            return
+        }
+        if lockErr := h.loginGuard.RecordFailure(ctx, c.ClientIP(), req.Email); lockErr != nil {
+            h.respondLockedOut(c, lockErr)
+            return
+        }
         c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
         return
     }
 
-    response := AuthResponse{
-        User: UserResponse{
-            ID:    user.ID,
-            Name:  user.Name,
-            Email: user.Email,
-        },
-        Token: token,
+    // Accounts with 2FA get a challenge instead of tokens. Failures are only
+    // forgotten once the second factor is in too, or every correct password
+    // would buy another round of code guesses.
+    if result.MFAToken != "" {
+        c.JSON(http.StatusOK, MFAChallengeResponse{
+            MFARequired: true,
+            MFAToken:    result.MFAToken,
+        })
+        return
     }
+    h.loginGuard.RecordSuccess(ctx, req.Email)
 
-    c.JSON(http.StatusOK, response)
+    c.JSON(http.StatusOK, h.toAuthResponse(result.User, result.Tokens))
 }
 
-func (h *AuthHandler) GetProfile(c *gin.Context) {
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+    var req LoginMFARequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    // Wrong codes count against the same account as wrong passwords
+    email, err := h.authService.MFAChallengeEmail(req.MFAToken)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    ctx := c.Request.Context()
+    if err := h.loginGuard.Check(ctx, c.ClientIP(), email); err != nil {
+        h.respondLockedOut(c, err)
+        return
+    }
+
+    result, err := h.authService.VerifyMFA(req.MFAToken, req.Code, clientInfo(c))
+    if err != nil {
+        if errors.Is(err, services.ErrAccountDisabled) || errors.Is(err, services.ErrUnverifiedLogin) {
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        }
+        if errors.Is(err, services.ErrInvalidMFACode) {
+            if lockErr := h.loginGuard.RecordFailure(ctx, c.ClientIP(), email); lockErr != nil {
+                h.respondLockedOut(c, lockErr)
+                return
+            }
+        }
+        if errors.Is(err, services.ErrInvalidMFAChallenge) || errors.Is(err, services.ErrInvalidMFACode) {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete login"})
+        return
+    }
+    h.loginGuard.RecordSuccess(ctx, email)
+
+    c.JSON(http.StatusOK, h.toAuthResponse(result.User, result.Tokens))
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+    var req RefreshRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    tokens, err := h.authService.Refresh(req.RefreshToken)
+    if err != nil {
+        if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+        return
+    }
+
+    c.JSON(http.StatusOK, TokenResponse{
+        Token:        tokens.AccessToken,
+        RefreshToken: tokens.RefreshToken,
+        ExpiresIn:    tokens.ExpiresIn,
+    })
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+    var req RefreshRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.authService.Logout(req.RefreshToken); err != nil {
+        if errors.Is(err, services.ErrInvalidRefreshToken) {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+    var req ForgotPasswordRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.authService.RequestPasswordReset(req.Email); err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send reset email"})
+        return
+    }
+
+    // Same answer whether or not the account exists
+    c.JSON(http.StatusOK, gin.H{"message": "If an account exists for that email, a reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+    var req ResetPasswordRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.authService.ResetPassword(req.Token, req.Password); err != nil {
+        if errors.Is(err, services.ErrInvalidResetToken) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+    var req VerifyEmailRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.authService.ConfirmEmail(req.Token); err != nil {
+        if errors.Is(err, services.ErrInvalidVerificationToken) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        if errors.Is(err, services.ErrEmailTaken) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Email address verified"})
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
         c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "user_id": userID,
-        "message": "Profile data (will implement full profile later)",
-    })
+    if err := h.authService.SendVerificationEmail(userID.(uint)); err != nil {
+        if errors.Is(err, services.ErrEmailAlreadyVerified) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+    // Let verifiers cache the set, but not for so long that rotations are missed
+    c.Header("Cache-Control", "public, max-age=300")
+    c.JSON(http.StatusOK, gin.H{"keys": h.authService.PublicKeys()})
+}
+
+// Helper methods
+func (h *AuthHandler) toAuthResponse(user *models.User, tokens *services.TokenPair) AuthResponse {
+    return AuthResponse{
+        User:         h.toUserResponse(user),
+        Token:        tokens.AccessToken,
+        RefreshToken: tokens.RefreshToken,
+        ExpiresIn:    tokens.ExpiresIn,
+    }
+}
+
+func (h *AuthHandler) toUserResponse(user *models.User) UserResponse {
+    return UserResponse{
+        ID:            user.ID,
+        Name:          user.Name,
+        Email:         user.Email,
+        EmailVerified: user.EmailVerifiedAt != nil,
+    }
+}
+
+func (h *AuthHandler) respondLockedOut(c *gin.Context, err error) {
+    var locked *services.LockedOutError
+    if errors.As(err, &locked) {
+        // Round up so clients never retry a moment too early
+        seconds := int(math.Ceil(locked.RetryAfter.Seconds()))
+        c.Header("Retry-After", strconv.Itoa(seconds))
+    }
+    c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+}
+
+func clientInfo(c *gin.Context) services.ClientInfo {
+    return services.ClientInfo{
+        UserAgent: c.Request.UserAgent(),
+        IPAddress: c.ClientIP(),
+    }
 }
\ No newline at end of file
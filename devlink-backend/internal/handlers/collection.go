@@ -0,0 +1,243 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type CollectionHandler struct {
+    collectionService *services.CollectionService
+}
+
+type CollectionResponse struct {
+    ID            uint   `json:"id"`
+    ParentID      *uint  `json:"parent_id"`
+    Name          string `json:"name"`
+    Description   string `json:"description"`
+    Icon          string `json:"icon"`
+    Position      int    `json:"position"`
+    ResourceCount *int64 `json:"resource_count,omitempty"`
+    Role          string `json:"role,omitempty"` // the caller's role: viewer, editor or owner
+    CreatedAt     string `json:"created_at"`
+    UpdatedAt     string `json:"updated_at"`
+}
+
+func NewCollectionHandler(collectionService *services.CollectionService) *CollectionHandler {
+    return &CollectionHandler{
+        collectionService: collectionService,
+    }
+}
+
+func (h *CollectionHandler) GetCollections(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collections, err := h.collectionService.GetUserCollections(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := make([]CollectionResponse, 0, len(collections))
+    for _, collection := range collections {
+        item := h.toCollectionResponse(collection.Collection)
+        item.ResourceCount = &collection.ResourceCount
+        item.Role = collection.Role
+        response = append(response, item)
+    }
+
+    c.JSON(http.StatusOK, gin.H{"collections": response})
+}
+
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    collection, role, err := h.collectionService.GetCollection(collectionID, userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    response := h.toCollectionResponse(*collection)
+    response.Role = role
+    c.JSON(http.StatusOK, response)
+}
+
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.CreateCollectionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    collection, err := h.collectionService.CreateCollection(userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, h.toCollectionResponse(*collection))
+}
+
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    var req services.UpdateCollectionRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    collection, err := h.collectionService.UpdateCollection(collectionID, userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toCollectionResponse(*collection))
+}
+
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    if err := h.collectionService.DeleteCollection(collectionID, userID.(uint)); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+func (h *CollectionHandler) AddResources(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    var req services.CollectionResourcesRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.collectionService.AddResources(collectionID, userID.(uint), req.ResourceIDs); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Resources added to collection"})
+}
+
+func (h *CollectionHandler) RemoveResource(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+    resourceID, ok := h.parseID(c, "resourceId")
+    if !ok {
+        return
+    }
+
+    if err := h.collectionService.RemoveResource(collectionID, userID.(uint), resourceID); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Resource removed from collection"})
+}
+
+// Helper methods
+func (h *CollectionHandler) parseID(c *gin.Context, param string) (uint, bool) {
+    id, err := strconv.ParseUint(c.Param(param), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return 0, false
+    }
+    return uint(id), true
+}
+
+func (h *CollectionHandler) handleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, services.ErrInvalidParent), errors.Is(err, services.ErrCollectionTooDeep),
+        errors.Is(err, services.ErrInvalidCollectionRole), errors.Is(err, services.ErrInvalidCollectionInvite):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrCollectionRole), errors.Is(err, services.ErrNotCollectionOwner):
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrAlreadyMember):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrTooManyInvites):
+        c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrCollectionNotFound), errors.Is(err, services.ErrResourceNotFound),
+        errors.Is(err, services.ErrNotInCollection), errors.Is(err, services.ErrMemberNotFound),
+        errors.Is(err, services.ErrCollectionInviteNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
+func (h *CollectionHandler) toCollectionResponse(collection models.Collection) CollectionResponse {
+    return CollectionResponse{
+        ID:          collection.ID,
+        ParentID:    collection.ParentID,
+        Name:        collection.Name,
+        Description: collection.Description,
+        Icon:        collection.Icon,
+        Position:    collection.Position,
+        CreatedAt:   collection.CreatedAt.Format("2006-01-02T15:04:05Z"),
+        UpdatedAt:   collection.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+    "net/http"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type CollectionInviteResponse struct {
+    ID        uint   `json:"id"`
+    Email     string `json:"email"`
+    Role      string `json:"role"`
+    ExpiresAt string `json:"expires_at"`
+    CreatedAt string `json:"created_at"`
+}
+
+func (h *CollectionHandler) GetMembers(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    members, err := h.collectionService.GetMembers(collectionID, userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func (h *CollectionHandler) UpdateMember(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+    memberID, ok := h.parseID(c, "userId")
+    if !ok {
+        return
+    }
+
+    var req services.UpdateMemberRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.collectionService.UpdateMemberRole(collectionID, userID.(uint), memberID, req.Role); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Member role updated"})
+}
+
+func (h *CollectionHandler) RemoveMember(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+    memberID, ok := h.parseID(c, "userId")
+    if !ok {
+        return
+    }
+
+    if err := h.collectionService.RemoveMember(collectionID, userID.(uint), memberID); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+func (h *CollectionHandler) GetInvites(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    invites, err := h.collectionService.GetInvites(collectionID, userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    response := make([]CollectionInviteResponse, 0, len(invites))
+    for _, invite := range invites {
+        response = append(response, h.toCollectionInviteResponse(invite))
+    }
+
+    c.JSON(http.StatusOK, gin.H{"invites": response})
+}
+
+func (h *CollectionHandler) InviteMember(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    var req services.InviteMemberRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    invite, err := h.collectionService.InviteMember(collectionID, userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, h.toCollectionInviteResponse(*invite))
+}
+
+func (h *CollectionHandler) RevokeInvite(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    collectionID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+    inviteID, ok := h.parseID(c, "inviteId")
+    if !ok {
+        return
+    }
+
+    if err := h.collectionService.RevokeInvite(collectionID, userID.(uint), inviteID); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+func (h *CollectionHandler) AcceptInvite(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.AcceptCollectionInviteRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    collection, err := h.collectionService.AcceptInvite(userID.(uint), req.Token)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toCollectionResponse(*collection))
+}
+
+// Helper methods
+func (h *CollectionHandler) toCollectionInviteResponse(invite models.CollectionInvite) CollectionInviteResponse {
+    return CollectionInviteResponse{
+        ID:        invite.ID,
+        Email:     invite.Email,
+        Role:      invite.Role,
+        ExpiresAt: invite.ExpiresAt.Format("2006-01-02T15:04:05Z"),
+        CreatedAt: invite.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+    authService *services.AuthService
+}
+
+type EnableTOTPRequest struct {
+    Code string `json:"code" binding:"required"`
+}
+
+// ReauthRequest is required for changes that weaken or reset 2FA.
+type ReauthRequest struct {
+    Password string `json:"password" binding:"required"`
+    Code     string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type TOTPSetupResponse struct {
+    Secret     string `json:"secret"`
+    OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+    RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func NewMFAHandler(authService *services.AuthService) *MFAHandler {
+    return &MFAHandler{
+        authService: authService,
+    }
+}
+
+func (h *MFAHandler) Setup(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    enrollment, err := h.authService.SetupTOTP(userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, TOTPSetupResponse{
+        Secret:     enrollment.Secret,
+        OTPAuthURI: enrollment.URI,
+    })
+}
+
+func (h *MFAHandler) Enable(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req EnableTOTPRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    codes, err := h.authService.EnableTOTP(userID.(uint), req.Code)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req ReauthRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.authService.DisableTOTP(userID.(uint), req.Password, req.Code); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req ReauthRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    codes, err := h.authService.RegenerateRecoveryCodes(userID.(uint), req.Password, req.Code)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Helper methods
+func (h *MFAHandler) handleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, services.ErrInvalidMFACode), errors.Is(err, services.ErrInvalidPassword):
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrMFAAlreadyEnabled), errors.Is(err, services.ErrMFANotEnabled), errors.Is(err, services.ErrMFANotSetUp):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+    "crypto/subtle"
+    "errors"
+    "net/http"
+    "net/url"
+    "strconv"
+
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+const oauthStateCookie = "devlink_oauth_state"
+
+type OAuthHandler struct {
+    oauthService *services.OAuthService
+    appURL       string
+}
+
+func NewOAuthHandler(oauthService *services.OAuthService, appURL string) *OAuthHandler {
+    return &OAuthHandler{
+        oauthService: oauthService,
+        appURL:       appURL,
+    }
+}
+
+func (h *OAuthHandler) GetProviders(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{"providers": h.oauthService.Providers()})
+}
+
+func (h *OAuthHandler) Start(c *gin.Context) {
+    authURL, state, err := h.oauthService.StartLogin(c.Request.Context(), c.Param("provider"))
+    if err != nil {
+        if errors.Is(err, services.ErrUnknownProvider) {
+            c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start login: " + err.Error()})
+        return
+    }
+
+    // Bind the state to this browser so a callback can't be replayed from elsewhere
+    c.SetSameSite(http.SameSiteLaxMode)
+    c.SetCookie(oauthStateCookie, state, 600, "/api/v1/auth/oauth", "", isSecureRequest(c), true)
+
+    c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *OAuthHandler) Callback(c *gin.Context) {
+    // Clear the state cookie whatever the outcome
+    cookieState, _ := c.Cookie(oauthStateCookie)
+    c.SetSameSite(http.SameSiteLaxMode)
+    c.SetCookie(oauthStateCookie, "", -1, "/api/v1/auth/oauth", "", isSecureRequest(c), true)
+
+    if providerError := c.Query("error"); providerError != "" {
+        description := c.Query("error_description")
+        if description == "" {
+            description = providerError
+        }
+        h.redirectWithError(c, "Login was not completed: "+description)
+        return
+    }
+
+    state := c.Query("state")
+    code := c.Query("code")
+    if state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+        h.redirectWithError(c, services.ErrInvalidOAuthState.Error())
+        return
+    }
+
+    result, err := h.oauthService.CompleteLogin(c.Request.Context(), c.Param("provider"), state, code, clientInfo(c))
+    if err != nil {
+        h.redirectWithError(c, err.Error())
+        return
+    }
+
+    // Tokens go in the fragment so they never reach server or proxy logs
+    var fragment url.Values
+    if result.MFAToken != "" {
+        fragment = url.Values{"mfa_token": {result.MFAToken}}
+    } else {
+        fragment = url.Values{
+            "token":         {result.Tokens.AccessToken},
+            "refresh_token": {result.Tokens.RefreshToken},
+            "expires_in":    {strconv.FormatInt(result.Tokens.ExpiresIn, 10)},
+        }
+    }
+    c.Redirect(http.StatusFound, h.appURL+"/oauth/callback#"+fragment.Encode())
+}
+
+// Helper methods
+func (h *OAuthHandler) redirectWithError(c *gin.Context, message string) {
+    fragment := url.Values{"error": {message}}
+    c.Redirect(http.StatusFound, h.appURL+"/oauth/callback#"+fragment.Encode())
+}
+
+func isSecureRequest(c *gin.Context) bool {
+    return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
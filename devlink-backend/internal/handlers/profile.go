@@ -0,0 +1,165 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+    authService *services.AuthService
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"current_password" binding:"required"`
+    NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+type ChangeEmailRequest struct {
+    Password string `json:"password" binding:"required"`
+    NewEmail string `json:"new_email" binding:"required,email"`
+}
+
+type ProfileResponse struct {
+    ID            uint   `json:"id"`
+    Name          string `json:"name"`
+    Email         string `json:"email"`
+    EmailVerified bool   `json:"email_verified"`
+    Role          string `json:"role"`
+    Bio           string `json:"bio"`
+    AvatarURL     string `json:"avatar_url"`
+    Website       string `json:"website"`
+    Timezone      string `json:"timezone"`
+    DefaultPublic bool   `json:"default_public"`
+    TOTPEnabled   bool   `json:"totp_enabled"`
+    DeleteAfter   string `json:"delete_after,omitempty"` // pending account deletion
+    CreatedAt     string `json:"created_at"`
+}
+
+func NewProfileHandler(authService *services.AuthService) *ProfileHandler {
+    return &ProfileHandler{
+        authService: authService,
+    }
+}
+
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    user, err := h.authService.GetProfile(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toProfileResponse(user))
+}
+
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.UpdateProfileRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    user, err := h.authService.UpdateProfile(userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toProfileResponse(user))
+}
+
+func (h *ProfileHandler) ChangePassword(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req ChangePasswordRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    err := h.authService.ChangePassword(userID.(uint), req.CurrentPassword, req.NewPassword, c.GetString("session_token_id"))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Password changed, other sessions have been signed out"})
+}
+
+func (h *ProfileHandler) ChangeEmail(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req ChangeEmailRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.authService.RequestEmailChange(userID.(uint), req.Password, req.NewEmail); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation link sent to the new address"})
+}
+
+// Helper methods
+func (h *ProfileHandler) handleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, services.ErrInvalidPassword):
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrInvalidTimezone), errors.Is(err, services.ErrSameEmail),
+        errors.Is(err, services.ErrInvalidLinkURL), errors.Is(err, services.ErrReservedEmail):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrEmailTaken):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
+func (h *ProfileHandler) toProfileResponse(user *models.User) ProfileResponse {
+    response := ProfileResponse{
+        ID:            user.ID,
+        Name:          user.Name,
+        Email:         user.Email,
+        EmailVerified: user.EmailVerifiedAt != nil,
+        Role:          user.Role,
+        Bio:           user.Bio,
+        AvatarURL:     user.AvatarURL,
+        Website:       user.Website,
+        Timezone:      user.Timezone,
+        DefaultPublic: user.DefaultPublic,
+        TOTPEnabled:   user.TOTPEnabledAt != nil,
+        CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+
+    if user.DeleteAfter != nil {
+        response.DeleteAfter = user.DeleteAfter.Format("2006-01-02T15:04:05Z")
+    }
+
+    return response
+}
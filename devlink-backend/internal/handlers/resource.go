@@ -1,11 +1,15 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
     "strconv"
 
     "devlink-backend/internal/services"
     "devlink-backend/internal/models"
+    "devlink-backend/internal/searchindex"
+    "devlink-backend/internal/searchquery"
+    "devlink-backend/internal/webpage"
     "github.com/gin-gonic/gin"
 )
 
@@ -14,17 +18,35 @@ type ResourceHandler struct {
 }
 
 type ResourceResponse struct {
-    ID          uint   `json:"id"`
-    Title       string `json:"title"`
-    URL         string `json:"url"`
-    Description string `json:"description"`
-    Category    string `json:"category"`
-    Tags        string `json:"tags"`
-    IsPublic    bool   `json:"is_public"`
-    ClickCount  int    `json:"click_count"`
-    UserID      uint   `json:"user_id"`
-    CreatedAt   string `json:"created_at"`
-    UpdatedAt   string `json:"updated_at"`
+    ID          uint     `json:"id"`
+    Title       string   `json:"title"`
+    URL         string   `json:"url"`
+    Description string   `json:"description"`
+    Category    string   `json:"category"`
+    FaviconURL  string   `json:"favicon_url"`
+    Tags        []string `json:"tags"`
+    IsPublic    bool     `json:"is_public"`
+    Archive     bool     `json:"archive"` // snapshots of the page are kept
+    ClickCount  int      `json:"click_count"`
+    UserID      uint     `json:"user_id"`
+    WorkspaceID *uint    `json:"workspace_id"`
+    CreatedAt   string   `json:"created_at"`
+    UpdatedAt   string   `json:"updated_at"`
+
+    // Link health
+    LinkStatus string             `json:"link_status"` // ok, redirected, broken or unchecked
+    LinkCheck  *LinkCheckResponse `json:"link_check"`  // nil until the link has been checked
+
+    // Where the search matched, only when listing with a search
+    Highlight *services.SearchHighlight `json:"highlight,omitempty"`
+}
+
+// LinkCheckResponse is the link checker's last look at a resource's URL.
+type LinkCheckResponse struct {
+    StatusCode int    `json:"status_code"`
+    FinalURL   string `json:"final_url"`
+    CheckedAt  string `json:"checked_at"`
+    Failures   int    `json:"failures"` // consecutive failed checks
 }
 
 type PaginatedResponse struct {
@@ -33,6 +55,9 @@ type PaginatedResponse struct {
     Page      int                `json:"page"`
     Limit     int                `json:"limit"`
     Pages     int                `json:"pages"`
+
+    // Categories and tags of all results, only when searching with the search index
+    Facets *searchindex.Facets `json:"facets,omitempty"`
 }
 
 func NewResourceHandler(resourceService *services.ResourceService) *ResourceHandler {
@@ -54,8 +79,20 @@ func (h *ResourceHandler) CreateResource(c *gin.Context) {
         return
     }
 
-    resource, err := h.resourceService.CreateResource(userID.(uint), req)
+    resource, err := h.resourceService.CreateResource(c.Request.Context(), c.GetUint("workspace_id"), userID.(uint), req)
     if err != nil {
+        if errors.Is(err, services.ErrEmailNotVerified) {
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        }
+        if errors.Is(err, services.ErrTooManyTags) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        if errors.Is(err, services.ErrWorkspaceNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
@@ -64,6 +101,33 @@ func (h *ResourceHandler) CreateResource(c *gin.Context) {
     c.JSON(http.StatusCreated, response)
 }
 
+// PreviewResource returns what the page at a URL says about itself, for
+// prefilling the new resource form.
+func (h *ResourceHandler) PreviewResource(c *gin.Context) {
+    var req services.PreviewRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    meta, err := h.resourceService.PreviewURL(c.Request.Context(), req.URL)
+    if err != nil {
+        switch {
+        case errors.Is(err, webpage.ErrUnsupportedURL), errors.Is(err, webpage.ErrBlockedAddress):
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        case errors.Is(err, webpage.ErrFetchFailed):
+            c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+        case errors.Is(err, services.ErrPreviewUnavailable):
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+        default:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        }
+        return
+    }
+
+    c.JSON(http.StatusOK, meta)
+}
+
 func (h *ResourceHandler) GetResource(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
@@ -77,7 +141,7 @@ func (h *ResourceHandler) GetResource(c *gin.Context) {
         return
     }
 
-    resource, err := h.resourceService.GetResourceByID(uint(resourceID), userID.(uint))
+    resource, err := h.resourceService.GetResourceByID(c.GetUint("workspace_id"), uint(resourceID), userID.(uint))
     if err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
         return
@@ -100,13 +164,21 @@ func (h *ResourceHandler) GetUserResources(c *gin.Context) {
         return
     }
 
-    resources, total, err := h.resourceService.GetUserResources(userID.(uint), filters)
+    page, err := h.resourceService.GetUserResources(c.GetUint("workspace_id"), userID.(uint), filters)
     if err != nil {
+        if errors.Is(err, services.ErrCollectionNotFound) || errors.Is(err, services.ErrWorkspaceNotFound) {
+            c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+            return
+        }
+        if errors.Is(err, services.ErrInvalidQuery) {
+            h.queryError(c, err)
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
-    response := h.toPaginatedResponse(resources, total, filters.Page, filters.Limit)
+    response := h.toPaginatedResponse(page, filters.Page, filters.Limit)
     c.JSON(http.StatusOK, response)
 }
 
@@ -129,8 +201,16 @@ func (h *ResourceHandler) UpdateResource(c *gin.Context) {
         return
     }
 
-    resource, err := h.resourceService.UpdateResource(uint(resourceID), userID.(uint), req)
+    resource, err := h.resourceService.UpdateResource(c.GetUint("workspace_id"), uint(resourceID), userID.(uint), req)
     if err != nil {
+        if errors.Is(err, services.ErrEmailNotVerified) || errors.Is(err, services.ErrNotResourceOwner) {
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        }
+        if errors.Is(err, services.ErrTooManyTags) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
         c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
         return
     }
@@ -139,6 +219,33 @@ func (h *ResourceHandler) UpdateResource(c *gin.Context) {
     c.JSON(http.StatusOK, response)
 }
 
+// FixRedirect points a redirected resource at the URL it now ends up at.
+func (h *ResourceHandler) FixRedirect(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    resourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+        return
+    }
+
+    resource, err := h.resourceService.FixRedirect(c.GetUint("workspace_id"), uint(resourceID), userID.(uint))
+    if err != nil {
+        if errors.Is(err, services.ErrNoRedirect) {
+            c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toResourceResponse(*resource))
+}
+
 func (h *ResourceHandler) DeleteResource(c *gin.Context) {
     userID, exists := c.Get("user_id")
     if !exists {
@@ -152,7 +259,7 @@ func (h *ResourceHandler) DeleteResource(c *gin.Context) {
         return
     }
 
-    if err := h.resourceService.DeleteResource(uint(resourceID), userID.(uint)); err != nil {
+    if err := h.resourceService.DeleteResource(c.GetUint("workspace_id"), uint(resourceID), userID.(uint)); err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
         return
     }
@@ -167,7 +274,7 @@ func (h *ResourceHandler) ClickResource(c *gin.Context) {
         return
     }
 
-    if err := h.resourceService.IncrementClickCount(uint(resourceID)); err != nil {
+    if err := h.resourceService.IncrementClickCount(uint(resourceID), c.Request.Referer()); err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to track click"})
         return
     }
@@ -182,46 +289,86 @@ func (h *ResourceHandler) GetPublicResources(c *gin.Context) {
         return
     }
 
-    resources, total, err := h.resourceService.GetPublicResources(filters)
+    page, err := h.resourceService.GetPublicResources(filters)
     if err != nil {
+        if errors.Is(err, services.ErrInvalidQuery) {
+            h.queryError(c, err)
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
         return
     }
 
-    response := h.toPaginatedResponse(resources, total, filters.Page, filters.Limit)
+    response := h.toPaginatedResponse(page, filters.Page, filters.Limit)
     c.JSON(http.StatusOK, response)
 }
 
 // Helper methods
+
+// queryError answers 400 for a search that didn't parse, pointing at the
+// offending part so the search box can highlight it.
+func (h *ResourceHandler) queryError(c *gin.Context, err error) {
+    response := gin.H{"error": err.Error()}
+    var queryErr *searchquery.Error
+    if errors.As(err, &queryErr) {
+        response["token"] = queryErr.Token.Text
+        response["position"] = queryErr.Token.Pos + 1
+    }
+    c.JSON(http.StatusBadRequest, response)
+}
+
 func (h *ResourceHandler) toResourceResponse(resource models.Resource) ResourceResponse {
-    return ResourceResponse{
+    response := ResourceResponse{
         ID:          resource.ID,
         Title:       resource.Title,
         URL:         resource.URL,
         Description: resource.Description,
         Category:    resource.Category,
-        Tags:        resource.Tags,
+        FaviconURL:  resource.FaviconURL,
+        Tags:        services.TagNames(resource.Tags),
         IsPublic:    resource.IsPublic,
+        Archive:     resource.Archive,
         ClickCount:  resource.ClickCount,
         UserID:      resource.UserID,
+        WorkspaceID: resource.WorkspaceID,
+        LinkStatus:  resource.LinkStatus,
         CreatedAt:   resource.CreatedAt.Format("2006-01-02T15:04:05Z"),
         UpdatedAt:   resource.UpdatedAt.Format("2006-01-02T15:04:05Z"),
     }
+
+    if resource.LinkCheckedAt != nil {
+        response.LinkCheck = &LinkCheckResponse{
+            StatusCode: resource.LinkStatusCode,
+            FinalURL:   resource.LinkFinalURL,
+            CheckedAt:  resource.LinkCheckedAt.Format("2006-01-02T15:04:05Z"),
+            Failures:   resource.LinkFailures,
+        }
+    }
+    if response.LinkStatus == models.LinkUnchecked {
+        response.LinkStatus = "unchecked"
+    }
+
+    return response
 }
 
-func (h *ResourceHandler) toPaginatedResponse(resources []models.Resource, total int64, page, limit int) PaginatedResponse {
+func (h *ResourceHandler) toPaginatedResponse(result *services.ResourcePage, page, limit int) PaginatedResponse {
     var resourceResponses []ResourceResponse
-    for _, resource := range resources {
-        resourceResponses = append(resourceResponses, h.toResourceResponse(resource))
+    for _, resource := range result.Resources {
+        response := h.toResourceResponse(resource)
+        if highlight, ok := result.Highlights[resource.ID]; ok {
+            response.Highlight = &highlight
+        }
+        resourceResponses = append(resourceResponses, response)
     }
 
-    pages := int((total + int64(limit) - 1) / int64(limit)) // Ceiling division
+    pages := int((result.Total + int64(limit) - 1) / int64(limit)) // Ceiling division
 
     return PaginatedResponse{
         Resources: resourceResponses,
-        Total:     total,
+        Total:     result.Total,
         Page:      page,
         Limit:     limit,
         Pages:     pages,
+        Facets:    result.Facets,
     }
 }
\ No newline at end of file
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+    authService *services.AuthService
+}
+
+type SessionResponse struct {
+    ID         uint   `json:"id"`
+    UserAgent  string `json:"user_agent"`
+    IPAddress  string `json:"ip_address"`
+    Current    bool   `json:"current"`
+    CreatedAt  string `json:"created_at"`
+    LastSeenAt string `json:"last_seen_at"`
+}
+
+func NewSessionHandler(authService *services.AuthService) *SessionHandler {
+    return &SessionHandler{
+        authService: authService,
+    }
+}
+
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    sessions, err := h.authService.GetUserSessions(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    currentTokenID := c.GetString("session_token_id")
+    response := make([]SessionResponse, 0, len(sessions))
+    for _, session := range sessions {
+        response = append(response, h.toSessionResponse(session, currentTokenID))
+    }
+
+    c.JSON(http.StatusOK, gin.H{"sessions": response})
+}
+
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+        return
+    }
+
+    if err := h.authService.RevokeUserSession(uint(sessionID), userID.(uint)); err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    revoked, err := h.authService.RevokeOtherSessions(userID.(uint), c.GetString("session_token_id"))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Signed out of all other sessions",
+        "revoked": revoked,
+    })
+}
+
+// Helper methods
+func (h *SessionHandler) toSessionResponse(session models.Session, currentTokenID string) SessionResponse {
+    return SessionResponse{
+        ID:         session.ID,
+        UserAgent:  session.UserAgent,
+        IPAddress:  session.IPAddress,
+        Current:    session.TokenID == currentTokenID,
+        CreatedAt:  session.CreatedAt.Format("2006-01-02T15:04:05Z"),
+        LastSeenAt: session.LastSeenAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
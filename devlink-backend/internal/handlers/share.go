@@ -0,0 +1,221 @@
+package handlers
+
+import (
+    "errors"
+    "math"
+    "net/http"
+    "strconv"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+// SharePasswordHeader carries the password for protected share links, so
+// it never ends up in URLs or access logs.
+const SharePasswordHeader = "X-Share-Password"
+
+type ShareHandler struct {
+    shareService *services.ShareService
+    appURL       string
+}
+
+type ShareLinkResponse struct {
+    ID           uint    `json:"id"`
+    ResourceID   *uint   `json:"resource_id"`
+    CollectionID *uint   `json:"collection_id"`
+    Prefix       string  `json:"prefix"`
+    HasPassword  bool    `json:"has_password"`
+    ViewCount    int     `json:"view_count"`
+    LastViewedAt *string `json:"last_viewed_at"`
+    ExpiresAt    *string `json:"expires_at"`
+    Revoked      bool    `json:"revoked"`
+    CreatedAt    string  `json:"created_at"`
+}
+
+type CreateShareLinkResponse struct {
+    ShareLinkResponse
+    URL string `json:"url"` // only returned once, at creation
+}
+
+type SharedResourceResponse struct {
+    Title       string   `json:"title"`
+    URL         string   `json:"url"`
+    Description string   `json:"description"`
+    Category    string   `json:"category"`
+    Tags        []string `json:"tags"`
+    CreatedAt   string   `json:"created_at"`
+}
+
+type SharedCollectionResponse struct {
+    Name        string                   `json:"name"`
+    Description string                   `json:"description"`
+    Icon        string                   `json:"icon"`
+    Resources   []SharedResourceResponse `json:"resources"`
+}
+
+func NewShareHandler(shareService *services.ShareService, appURL string) *ShareHandler {
+    return &ShareHandler{
+        shareService: shareService,
+        appURL:       appURL,
+    }
+}
+
+func (h *ShareHandler) GetShareLinks(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    links, err := h.shareService.GetShareLinks(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := make([]ShareLinkResponse, 0, len(links))
+    for _, link := range links {
+        response = append(response, h.toShareLinkResponse(link))
+    }
+
+    c.JSON(http.StatusOK, gin.H{"share_links": response})
+}
+
+func (h *ShareHandler) CreateShareLink(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.CreateShareLinkRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    link, token, err := h.shareService.CreateShareLink(userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, CreateShareLinkResponse{
+        ShareLinkResponse: h.toShareLinkResponse(*link),
+        URL:               h.appURL + "/s/" + token,
+    })
+}
+
+func (h *ShareHandler) RevokeShareLink(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    linkID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share link ID"})
+        return
+    }
+
+    if err := h.shareService.RevokeShareLink(userID.(uint), uint(linkID)); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Share link revoked"})
+}
+
+// OpenShareLink is the public side of a link and needs no login.
+func (h *ShareHandler) OpenShareLink(c *gin.Context) {
+    content, err := h.shareService.OpenShareLink(c.Request.Context(), c.Param("token"), c.GetHeader(SharePasswordHeader), c.ClientIP())
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    // Unlisted content must not end up in search engines or shared caches
+    c.Header("Cache-Control", "private, no-store")
+    c.Header("X-Robots-Tag", "noindex")
+
+    if content.Resource != nil {
+        c.JSON(http.StatusOK, gin.H{
+            "type":     "resource",
+            "resource": h.toSharedResourceResponse(*content.Resource),
+        })
+        return
+    }
+
+    collection := SharedCollectionResponse{
+        Name:        content.Collection.Name,
+        Description: content.Collection.Description,
+        Icon:        content.Collection.Icon,
+        Resources:   make([]SharedResourceResponse, 0, len(content.Resources)),
+    }
+    for _, resource := range content.Resources {
+        collection.Resources = append(collection.Resources, h.toSharedResourceResponse(resource))
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "type":       "collection",
+        "collection": collection,
+    })
+}
+
+// Helper methods
+func (h *ShareHandler) handleError(c *gin.Context, err error) {
+    var locked *services.LockedOutError
+    switch {
+    case errors.As(err, &locked):
+        c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+        c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrShareTarget), errors.Is(err, services.ErrShareWorkspace):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrSharePasswordNeeded), errors.Is(err, services.ErrWrongSharePassword):
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "password_required": true})
+    case errors.Is(err, services.ErrCollectionRole):
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrShareLinkNotFound), errors.Is(err, services.ErrResourceNotFound),
+        errors.Is(err, services.ErrCollectionNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
+func (h *ShareHandler) toShareLinkResponse(link models.ShareLink) ShareLinkResponse {
+    response := ShareLinkResponse{
+        ID:           link.ID,
+        ResourceID:   link.ResourceID,
+        CollectionID: link.CollectionID,
+        Prefix:       link.Prefix,
+        HasPassword:  link.PasswordHash != "",
+        ViewCount:    link.ViewCount,
+        Revoked:      link.RevokedAt != nil,
+        CreatedAt:    link.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+
+    if link.LastViewedAt != nil {
+        lastViewedAt := link.LastViewedAt.Format("2006-01-02T15:04:05Z")
+        response.LastViewedAt = &lastViewedAt
+    }
+    if link.ExpiresAt != nil {
+        expiresAt := link.ExpiresAt.Format("2006-01-02T15:04:05Z")
+        response.ExpiresAt = &expiresAt
+    }
+
+    return response
+}
+
+func (h *ShareHandler) toSharedResourceResponse(resource models.Resource) SharedResourceResponse {
+    return SharedResourceResponse{
+        Title:       resource.Title,
+        URL:         resource.URL,
+        Description: resource.Description,
+        Category:    resource.Category,
+        Tags:        services.TagNames(resource.Tags),
+        CreatedAt:   resource.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
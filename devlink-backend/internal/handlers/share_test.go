@@ -0,0 +1,132 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+    "github.com/glebarez/sqlite"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+)
+
+// newShareTestDB opens an in-memory SQLite database with the tables share
+// links touch. SQLite has no GIN indexes, so the search index is created as
+// a plain one.
+func newShareTestDB(t *testing.T) *gorm.DB {
+    t.Helper()
+
+    db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+    if err != nil {
+        t.Fatalf("open test database: %v", err)
+    }
+    sqlDB, err := db.DB()
+    if err != nil {
+        t.Fatalf("open test database: %v", err)
+    }
+    t.Cleanup(func() { sqlDB.Close() })
+
+    err = db.Callback().Raw().Before("gorm:raw").Register("test:postgres_only", func(db *gorm.DB) {
+        if sql := db.Statement.SQL.String(); strings.Contains(sql, " USING gin") {
+            db.Statement.SQL.Reset()
+            db.Statement.SQL.WriteString(strings.Replace(sql, " USING gin", "", 1))
+        }
+    })
+    if err != nil {
+        t.Fatalf("register test callback: %v", err)
+    }
+
+    if err := db.AutoMigrate(
+        &models.User{},
+        &models.Resource{},
+        &models.Tag{},
+        &models.Collection{},
+        &models.CollectionMember{},
+        &models.Workspace{},
+        &models.WorkspaceMember{},
+        &models.ShareLink{},
+    ); err != nil {
+        t.Fatalf("migrate test database: %v", err)
+    }
+    return db
+}
+
+func openShareLink(router *gin.Engine, token string) *httptest.ResponseRecorder {
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/s/"+token, nil))
+    return w
+}
+
+// Links made before a resource moved into a workspace, or collections that
+// picked up workspace resources, must not show them to anonymous visitors.
+func TestOpenShareLinkHidesWorkspaceResources(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    db := newShareTestDB(t)
+
+    owner := models.User{Name: "owner", Email: "owner@example.com"}
+    if err := db.Create(&owner).Error; err != nil {
+        t.Fatalf("create user: %v", err)
+    }
+    workspace := models.Workspace{Name: "Platform Team", Slug: "platform-team", CreatedByID: owner.ID}
+    if err := db.Create(&workspace).Error; err != nil {
+        t.Fatalf("create workspace: %v", err)
+    }
+    personal := models.Resource{UserID: owner.ID, Title: "Public notes", URL: "https://example.com/notes"}
+    moved := models.Resource{UserID: owner.ID, Title: "Runbook draft", URL: "https://example.com/draft"}
+    internal := models.Resource{UserID: owner.ID, Title: "Kubernetes secrets", URL: "https://example.com/secrets", WorkspaceID: &workspace.ID}
+    for _, resource := range []*models.Resource{&personal, &moved, &internal} {
+        if err := db.Create(resource).Error; err != nil {
+            t.Fatalf("create resource: %v", err)
+        }
+    }
+    collection := models.Collection{UserID: owner.ID, Name: "Reading list", Resources: []models.Resource{personal, internal}}
+    if err := db.Create(&collection).Error; err != nil {
+        t.Fatalf("create collection: %v", err)
+    }
+
+    shares := services.NewShareService(db, nil)
+    _, resourceToken, err := shares.CreateShareLink(owner.ID, services.CreateShareLinkRequest{ResourceID: &moved.ID})
+    if err != nil {
+        t.Fatalf("CreateShareLink(resource): %v", err)
+    }
+    _, collectionToken, err := shares.CreateShareLink(owner.ID, services.CreateShareLinkRequest{CollectionID: &collection.ID})
+    if err != nil {
+        t.Fatalf("CreateShareLink(collection): %v", err)
+    }
+    if _, _, err := shares.CreateShareLink(owner.ID, services.CreateShareLinkRequest{ResourceID: &internal.ID}); !errors.Is(err, services.ErrShareWorkspace) {
+        t.Errorf("CreateShareLink(workspace resource): err = %v, want ErrShareWorkspace", err)
+    }
+
+    router := gin.New()
+    router.GET("/api/v1/s/:token", NewShareHandler(shares, "http://app.test").OpenShareLink)
+
+    if w := openShareLink(router, resourceToken); w.Code != http.StatusOK {
+        t.Fatalf("personal resource link: status %d, want 200", w.Code)
+    }
+    if err := db.Model(&moved).Update("workspace_id", workspace.ID).Error; err != nil {
+        t.Fatalf("move resource: %v", err)
+    }
+    if w := openShareLink(router, resourceToken); w.Code != http.StatusNotFound {
+        t.Errorf("link to a resource moved into a workspace: status %d, want 404", w.Code)
+    }
+
+    w := openShareLink(router, collectionToken)
+    if w.Code != http.StatusOK {
+        t.Fatalf("collection link: status %d, want 200", w.Code)
+    }
+    var body struct {
+        Collection SharedCollectionResponse `json:"collection"`
+    }
+    if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+        t.Fatalf("decode response: %v", err)
+    }
+    if got := body.Collection.Resources; len(got) != 1 || got[0].Title != "Public notes" {
+        t.Errorf("collection link shows %+v, want only the personal resource", got)
+    }
+}
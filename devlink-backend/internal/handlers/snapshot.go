@@ -0,0 +1,183 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "devlink-backend/internal/webpage"
+    "github.com/gin-gonic/gin"
+)
+
+// snapshotCSP lets a stored page show its text and images but nothing else:
+// no scripts, no forms, no requests back to this origin.
+const snapshotCSP = "sandbox; default-src 'none'; img-src http: https: data:; style-src 'unsafe-inline'"
+
+type SnapshotHandler struct {
+    snapshotService *services.SnapshotService
+}
+
+type SnapshotResponse struct {
+    ID          uint   `json:"id"`
+    ResourceID  uint   `json:"resource_id"`
+    URL         string `json:"url"`
+    Title       string `json:"title"`
+    ContentHash string `json:"content_hash"`
+    Size        int    `json:"size"`
+    CreatedAt   string `json:"created_at"`
+}
+
+func NewSnapshotHandler(snapshotService *services.SnapshotService) *SnapshotHandler {
+    return &SnapshotHandler{
+        snapshotService: snapshotService,
+    }
+}
+
+func (h *SnapshotHandler) GetSnapshots(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    resourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+        return
+    }
+
+    snapshots, err := h.snapshotService.GetSnapshots(c.GetUint("workspace_id"), uint(resourceID), userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    response := make([]SnapshotResponse, 0, len(snapshots))
+    for _, snapshot := range snapshots {
+        response = append(response, h.toSnapshotResponse(snapshot))
+    }
+    c.JSON(http.StatusOK, gin.H{"snapshots": response})
+}
+
+// TakeSnapshot captures the resource's page right away. It answers 200 with
+// the latest snapshot when the page hasn't changed since.
+func (h *SnapshotHandler) TakeSnapshot(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    resourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+        return
+    }
+
+    snapshot, created, err := h.snapshotService.TakeSnapshot(c.Request.Context(), c.GetUint("workspace_id"), uint(resourceID), userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    status := http.StatusOK
+    if created {
+        status = http.StatusCreated
+    }
+    c.JSON(status, h.toSnapshotResponse(*snapshot))
+}
+
+// ViewSnapshot serves the stored page, or its plain text with ?format=text.
+func (h *SnapshotHandler) ViewSnapshot(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    resourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+        return
+    }
+    snapshotID, err := strconv.ParseUint(c.Param("snapshotId"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot ID"})
+        return
+    }
+
+    text := c.Query("format") == "text"
+    _, content, err := h.snapshotService.GetSnapshotContent(c.Request.Context(), c.GetUint("workspace_id"),
+        uint(resourceID), uint(snapshotID), userID.(uint), text)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.Header("X-Content-Type-Options", "nosniff")
+    if text {
+        c.Data(http.StatusOK, "text/plain; charset=utf-8", content)
+        return
+    }
+    c.Header("Content-Security-Policy", snapshotCSP)
+    c.Header("Referrer-Policy", "no-referrer")
+    c.Data(http.StatusOK, "text/html; charset=utf-8", content)
+}
+
+func (h *SnapshotHandler) DeleteSnapshot(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    resourceID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid resource ID"})
+        return
+    }
+    snapshotID, err := strconv.ParseUint(c.Param("snapshotId"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid snapshot ID"})
+        return
+    }
+
+    if err := h.snapshotService.DeleteSnapshot(c.Request.Context(), c.GetUint("workspace_id"),
+        uint(resourceID), uint(snapshotID), userID.(uint)); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Snapshot deleted successfully"})
+}
+
+// Helper methods
+func (h *SnapshotHandler) handleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, webpage.ErrUnsupportedURL), errors.Is(err, webpage.ErrBlockedAddress):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, webpage.ErrNotHTML), errors.Is(err, webpage.ErrNoContent):
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+    case errors.Is(err, webpage.ErrFetchFailed):
+        c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrResourceNotFound), errors.Is(err, services.ErrSnapshotNotFound),
+        errors.Is(err, services.ErrWorkspaceNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
+func (h *SnapshotHandler) toSnapshotResponse(snapshot models.Snapshot) SnapshotResponse {
+    return SnapshotResponse{
+        ID:          snapshot.ID,
+        ResourceID:  snapshot.ResourceID,
+        URL:         snapshot.URL,
+        Title:       snapshot.Title,
+        ContentHash: snapshot.ContentHash,
+        Size:        snapshot.Size,
+        CreatedAt:   snapshot.CreatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+    tagService *services.TagService
+}
+
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+    return &TagHandler{
+        tagService: tagService,
+    }
+}
+
+func (h *TagHandler) GetTags(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var filters services.TagFilters
+    if err := c.ShouldBindQuery(&filters); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    tags, err := h.tagService.GetUserTags(c.GetUint("workspace_id"), userID.(uint), filters)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (h *TagHandler) GetPopularTags(c *gin.Context) {
+    var filters services.TagFilters
+    if err := c.ShouldBindQuery(&filters); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    tags, err := h.tagService.GetPopularTags(filters)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (h *TagHandler) RenameTag(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.RenameTagRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    tag, err := h.tagService.RenameTag(c.GetUint("workspace_id"), userID.(uint), c.Param("name"), req.Name)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) MergeTags(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.MergeTagsRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    tag, err := h.tagService.MergeTags(c.GetUint("workspace_id"), userID.(uint), req.Sources, req.Target)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, tag)
+}
+
+// Helper methods
+func (h *TagHandler) handleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, services.ErrTagNotFound), errors.Is(err, services.ErrWorkspaceNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrTagExists):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrEmptyTag), errors.Is(err, services.ErrNoMergeTags):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/services"
+    "github.com/gin-gonic/gin"
+)
+
+type WorkspaceHandler struct {
+    workspaceService *services.WorkspaceService
+}
+
+type WorkspaceResponse struct {
+    ID        uint   `json:"id"`
+    Name      string `json:"name"`
+    Slug      string `json:"slug"`
+    Role      string `json:"role,omitempty"` // the caller's role: member, admin or owner
+    CreatedAt string `json:"created_at"`
+    UpdatedAt string `json:"updated_at"`
+}
+
+func NewWorkspaceHandler(workspaceService *services.WorkspaceService) *WorkspaceHandler {
+    return &WorkspaceHandler{
+        workspaceService: workspaceService,
+    }
+}
+
+func (h *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaces, err := h.workspaceService.GetUserWorkspaces(userID.(uint))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := make([]WorkspaceResponse, 0, len(workspaces))
+    for _, workspace := range workspaces {
+        item := h.toWorkspaceResponse(workspace.Workspace)
+        item.Role = workspace.Role
+        response = append(response, item)
+    }
+
+    c.JSON(http.StatusOK, gin.H{"workspaces": response})
+}
+
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaceID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    workspace, role, err := h.workspaceService.GetWorkspace(workspaceID, userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    response := h.toWorkspaceResponse(*workspace)
+    response.Role = role
+    c.JSON(http.StatusOK, response)
+}
+
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    var req services.CreateWorkspaceRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    workspace, err := h.workspaceService.CreateWorkspace(userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    response := h.toWorkspaceResponse(*workspace)
+    response.Role = models.WorkspaceRoleOwner
+    c.JSON(http.StatusCreated, response)
+}
+
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaceID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    var req services.UpdateWorkspaceRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    workspace, err := h.workspaceService.UpdateWorkspace(workspaceID, userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, h.toWorkspaceResponse(*workspace))
+}
+
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaceID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    if err := h.workspaceService.DeleteWorkspace(workspaceID, userID.(uint)); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Workspace deleted successfully"})
+}
+
+func (h *WorkspaceHandler) GetMembers(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaceID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    members, err := h.workspaceService.GetMembers(workspaceID, userID.(uint))
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaceID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+
+    var req services.AddWorkspaceMemberRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    member, err := h.workspaceService.AddMember(workspaceID, userID.(uint), req)
+    if err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusCreated, member)
+}
+
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaceID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+    memberID, ok := h.parseID(c, "userId")
+    if !ok {
+        return
+    }
+
+    var req services.UpdateWorkspaceMemberRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.workspaceService.UpdateMemberRole(workspaceID, userID.(uint), memberID, req.Role); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Member role updated"})
+}
+
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+    userID, exists := c.Get("user_id")
+    if !exists {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+        return
+    }
+
+    workspaceID, ok := h.parseID(c, "id")
+    if !ok {
+        return
+    }
+    memberID, ok := h.parseID(c, "userId")
+    if !ok {
+        return
+    }
+
+    if err := h.workspaceService.RemoveMember(workspaceID, userID.(uint), memberID); err != nil {
+        h.handleError(c, err)
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// Helper methods
+func (h *WorkspaceHandler) parseID(c *gin.Context, param string) (uint, bool) {
+    id, err := strconv.ParseUint(c.Param(param), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+        return 0, false
+    }
+    return uint(id), true
+}
+
+func (h *WorkspaceHandler) handleError(c *gin.Context, err error) {
+    switch {
+    case errors.Is(err, services.ErrInvalidWorkspaceRole), errors.Is(err, services.ErrInvalidWorkspaceSlug):
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrWorkspaceRole):
+        c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrWorkspaceSlugTaken), errors.Is(err, services.ErrAlreadyWorkspaceMember),
+        errors.Is(err, services.ErrLastWorkspaceOwner), errors.Is(err, services.ErrMemberEmailUnverified):
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, services.ErrWorkspaceNotFound), errors.Is(err, services.ErrMemberNotFound),
+        errors.Is(err, services.ErrUserNotFound):
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+    }
+}
+
+func (h *WorkspaceHandler) toWorkspaceResponse(workspace models.Workspace) WorkspaceResponse {
+    return WorkspaceResponse{
+        ID:        workspace.ID,
+        Name:      workspace.Name,
+        Slug:      workspace.Slug,
+        CreatedAt: workspace.CreatedAt.Format("2006-01-02T15:04:05Z"),
+        UpdatedAt: workspace.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+    }
+}
@@ -0,0 +1,160 @@
+// Package jwtkeys holds the keys DevLink signs and verifies access tokens
+// with, and publishes the public half as a JSON Web Key Set.
+package jwtkeys
+
+import (
+    "crypto"
+    "crypto/ed25519"
+    "crypto/rsa"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "math/big"
+    "sort"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// Key is one signing or verification key. Private is nil for retired keys
+// that are only kept to verify tokens issued before a rotation.
+type Key struct {
+    ID      string
+    Method  jwt.SigningMethod
+    Private crypto.PrivateKey
+    Public  crypto.PublicKey
+}
+
+// KeySet signs with its active key and verifies with any key it holds.
+type KeySet struct {
+    active *Key
+    keys   map[string]*Key
+}
+
+// NewHMACKeySet wraps a shared secret in a KeySet. This is the legacy HS256
+// mode; nothing is published in the JWKS since the secret is symmetric.
+func NewHMACKeySet(secret string) *KeySet {
+    key := &Key{
+        Method:  jwt.SigningMethodHS256,
+        Private: []byte(secret),
+        Public:  []byte(secret),
+    }
+    return &KeySet{
+        active: key,
+        keys:   map[string]*Key{"": key},
+    }
+}
+
+// NewKeySet builds a KeySet from keys, signing with the one named activeID.
+func NewKeySet(keys []*Key, activeID string) (*KeySet, error) {
+    set := &KeySet{keys: make(map[string]*Key, len(keys))}
+    for _, key := range keys {
+        if _, exists := set.keys[key.ID]; exists {
+            return nil, fmt.Errorf("duplicate key id %q", key.ID)
+        }
+        set.keys[key.ID] = key
+    }
+
+    active, ok := set.keys[activeID]
+    if !ok {
+        return nil, fmt.Errorf("active key %q not found", activeID)
+    }
+    if active.Private == nil {
+        return nil, fmt.Errorf("active key %q has no private key", activeID)
+    }
+    set.active = active
+
+    return set, nil
+}
+
+// Sign signs claims with the active key and stamps its kid in the header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+    token := jwt.NewWithClaims(s.active.Method, claims)
+    if s.active.ID != "" {
+        token.Header["kid"] = s.active.ID
+    }
+    return token.SignedString(s.active.Private)
+}
+
+// Keyfunc resolves the verification key for a token by its kid, refusing
+// tokens whose alg doesn't match the key so algorithms can't be swapped.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+    kid, _ := token.Header["kid"].(string)
+
+    key, ok := s.keys[kid]
+    if !ok {
+        return nil, fmt.Errorf("unknown signing key %q", kid)
+    }
+    if token.Method.Alg() != key.Method.Alg() {
+        return nil, errors.New("token algorithm does not match signing key")
+    }
+
+    return key.Public, nil
+}
+
+// ValidMethods lists the algorithms of every key in the set.
+func (s *KeySet) ValidMethods() []string {
+    seen := make(map[string]bool)
+    var methods []string
+    for _, key := range s.keys {
+        alg := key.Method.Alg()
+        if !seen[alg] {
+            seen[alg] = true
+            methods = append(methods, alg)
+        }
+    }
+    return methods
+}
+
+// JWK is the public JSON Web Key representation of a key.
+type JWK struct {
+    Kty string `json:"kty"`
+    Kid string `json:"kid"`
+    Use string `json:"use"`
+    Alg string `json:"alg"`
+    N   string `json:"n,omitempty"`
+    E   string `json:"e,omitempty"`
+    Crv string `json:"crv,omitempty"`
+    X   string `json:"x,omitempty"`
+}
+
+// JWKS returns the public keys for the /.well-known/jwks.json endpoint.
+// Symmetric keys are never included.
+func (s *KeySet) JWKS() []JWK {
+    ids := make([]string, 0, len(s.keys))
+    for id := range s.keys {
+        ids = append(ids, id)
+    }
+    sort.Strings(ids)
+
+    keys := make([]JWK, 0, len(s.keys))
+    for _, id := range ids {
+        key := s.keys[id]
+        switch public := key.Public.(type) {
+        case *rsa.PublicKey:
+            keys = append(keys, JWK{
+                Kty: "RSA",
+                Kid: key.ID,
+                Use: "sig",
+                Alg: key.Method.Alg(),
+                N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+                E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+            })
+        case ed25519.PublicKey:
+            keys = append(keys, JWK{
+                Kty: "OKP",
+                Kid: key.ID,
+                Use: "sig",
+                Alg: key.Method.Alg(),
+                Crv: "Ed25519",
+                X:   base64.RawURLEncoding.EncodeToString(public),
+            })
+        }
+    }
+    return keys
+}
+
+// IsSymmetric reports whether tokens are signed with a shared secret.
+func (s *KeySet) IsSymmetric() bool {
+    _, ok := s.active.Private.([]byte)
+    return ok
+}
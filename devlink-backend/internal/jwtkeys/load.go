@@ -0,0 +1,123 @@
+package jwtkeys
+
+import (
+    "crypto/ed25519"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/pem"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// LoadDir reads every key in dir. The file name minus its extension is the
+// kid: "2025-06.pem" holds a private key (PKCS#8 RSA or Ed25519, or PKCS#1
+// RSA), "2025-01.pub.pem" a public key kept only for verification.
+func LoadDir(dir, activeID string) (*KeySet, error) {
+    paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+    if err != nil {
+        return nil, err
+    }
+    if len(paths) == 0 {
+        return nil, fmt.Errorf("no .pem keys found in %s", dir)
+    }
+    sort.Strings(paths)
+
+    var keys []*Key
+    for _, path := range paths {
+        key, err := loadKeyFile(path)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+        }
+        keys = append(keys, key)
+    }
+
+    return NewKeySet(keys, activeID)
+}
+
+func loadKeyFile(path string) (*Key, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, errors.New("no PEM block found")
+    }
+
+    name := filepath.Base(path)
+    if strings.HasSuffix(name, ".pub.pem") {
+        key, err := parsePublicKey(block)
+        if err != nil {
+            return nil, err
+        }
+        key.ID = strings.TrimSuffix(name, ".pub.pem")
+        return key, nil
+    }
+
+    key, err := parsePrivateKey(block)
+    if err != nil {
+        return nil, err
+    }
+    key.ID = strings.TrimSuffix(name, ".pem")
+    return key, nil
+}
+
+func parsePrivateKey(block *pem.Block) (*Key, error) {
+    var parsed interface{}
+    var err error
+    switch block.Type {
+    case "RSA PRIVATE KEY":
+        parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+    case "PRIVATE KEY":
+        parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+    default:
+        return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    switch private := parsed.(type) {
+    case *rsa.PrivateKey:
+        if private.N.BitLen() < 2048 {
+            return nil, errors.New("RSA keys must be at least 2048 bits")
+        }
+        return &Key{Method: jwt.SigningMethodRS256, Private: private, Public: &private.PublicKey}, nil
+    case ed25519.PrivateKey:
+        return &Key{Method: jwt.SigningMethodEdDSA, Private: private, Public: private.Public()}, nil
+    default:
+        return nil, fmt.Errorf("unsupported private key type %T (use RSA or Ed25519)", parsed)
+    }
+}
+
+func parsePublicKey(block *pem.Block) (*Key, error) {
+    var parsed interface{}
+    var err error
+    switch block.Type {
+    case "RSA PUBLIC KEY":
+        parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+    case "PUBLIC KEY":
+        parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+    default:
+        return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    switch public := parsed.(type) {
+    case *rsa.PublicKey:
+        return &Key{Method: jwt.SigningMethodRS256, Public: public}, nil
+    case ed25519.PublicKey:
+        return &Key{Method: jwt.SigningMethodEdDSA, Public: public}, nil
+    default:
+        return nil, fmt.Errorf("unsupported public key type %T (use RSA or Ed25519)", parsed)
+    }
+}
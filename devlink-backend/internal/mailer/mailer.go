@@ -0,0 +1,34 @@
+package mailer
+
+import (
+    "bytes"
+    "fmt"
+    "time"
+)
+
+// Message is a plain-text email.
+type Message struct {
+    To      string
+    Subject string
+    Body    string
+}
+
+// Mailer delivers outgoing email. Services depend on this interface so the
+// SMTP sender can be swapped for the local outbox in development and tests.
+type Mailer interface {
+    Send(msg Message) error
+}
+
+// render formats a message as RFC 5322 text, ready for SMTP or an .eml file.
+func render(from string, msg Message) []byte {
+    var buf bytes.Buffer
+    fmt.Fprintf(&buf, "From: %s\r\n", from)
+    fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+    fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+    fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+    buf.WriteString("MIME-Version: 1.0\r\n")
+    buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+    buf.WriteString("\r\n")
+    buf.WriteString(msg.Body)
+    return buf.Bytes()
+}
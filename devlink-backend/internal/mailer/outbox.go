@@ -0,0 +1,43 @@
+package mailer
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+    "time"
+)
+
+// OutboxMailer writes every message to a directory as an .eml file instead
+// of sending it, so flows like password reset can be exercised without a
+// mail server.
+type OutboxMailer struct {
+    dir  string
+    from string
+    mu   sync.Mutex
+    seq  int
+}
+
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+    return &OutboxMailer{
+        dir:  dir,
+        from: from,
+    }
+}
+
+func (m *OutboxMailer) Send(msg Message) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if err := os.MkdirAll(m.dir, 0o755); err != nil {
+        return err
+    }
+
+    // Timestamp plus a counter keeps files unique and sorted by send order
+    m.seq++
+    recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+    name := fmt.Sprintf("%s-%04d-%s.eml", time.Now().UTC().Format("20060102T150405"), m.seq, recipient)
+
+    return os.WriteFile(filepath.Join(m.dir, name), render(m.from, msg), 0o644)
+}
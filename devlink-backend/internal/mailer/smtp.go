@@ -0,0 +1,35 @@
+package mailer
+
+import (
+    "net"
+    "net/smtp"
+)
+
+type SMTPMailer struct {
+    host     string
+    port     string
+    username string
+    password string
+    from     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+    return &SMTPMailer{
+        host:     host,
+        port:     port,
+        username: username,
+        password: password,
+        from:     from,
+    }
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+    // Only authenticate when credentials are configured (e.g. local relays don't need them)
+    var auth smtp.Auth
+    if m.username != "" {
+        auth = smtp.PlainAuth("", m.username, m.password, m.host)
+    }
+
+    addr := net.JoinHostPort(m.host, m.port)
+    return smtp.SendMail(addr, auth, m.from, []string{msg.To}, render(m.from, msg))
+}
@@ -4,41 +4,169 @@ import (
     "net/http"
     "strings"
 
+    "devlink-backend/internal/models"
     "devlink-backend/internal/services"
     "github.com/gin-gonic/gin"
 )
 
 func JWTAuth(authService *services.AuthService) gin.HandlerFunc {
     return func(c *gin.Context) {
-        authHeader := c.GetHeader("Authorization")
-        if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+        token, ok := bearerToken(c)
+        if !ok {
+            return
+        }
+
+        if !authenticateJWT(c, authService, token) {
+            return
+        }
+
+        c.Next()
+    }
+}
+
+// APIAuth is JWTAuth for routes that scripts may call too: it additionally
+// accepts personal access tokens. Pair it with RequireScope on each route.
+func APIAuth(authService *services.AuthService, accessTokenService *services.AccessTokenService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        token, ok := bearerToken(c)
+        if !ok {
+            return
+        }
+
+        if !strings.HasPrefix(token, services.PATPrefix) {
+            if !authenticateJWT(c, authService, token) {
+                return
+            }
+            c.Next()
+            return
+        }
+
+        accessToken, err := accessTokenService.Authenticate(token)
+        if err != nil {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired access token"})
+            c.Abort()
+            return
+        }
+
+        // Add user info to context
+        c.Set("user_id", accessToken.UserID)
+        c.Set("token_scopes", services.TokenScopes(accessToken))
+
+        c.Next()
+    }
+}
+
+// RequireScope rejects personal access tokens that lack the given scope.
+// Session JWTs carry the user's full access and always pass.
+func RequireScope(scope string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        scopes, isToken := c.Get("token_scopes")
+        if isToken && !services.HasScope(scopes.([]string), scope) {
+            c.JSON(http.StatusForbidden, gin.H{"error": "Access token is missing the " + scope + " scope"})
             c.Abort()
             return
         }
 
-        // Check if header starts with "Bearer "
-        if !strings.HasPrefix(authHeader, "Bearer ") {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+        c.Next()
+    }
+}
+
+// RequireRole only lets through users holding at least the given role,
+// where admin outranks moderator and moderator outranks user.
+func RequireRole(role string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if roleRank(c.GetString("user_role")) < roleRank(role) {
+            c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
             c.Abort()
             return
         }
 
-        // Extract token
-        token := strings.TrimPrefix(authHeader, "Bearer ")
-        
-        // Validate token
-        claims, err := authService.ValidateToken(token)
+        c.Next()
+    }
+}
+
+func roleRank(role string) int {
+    switch role {
+    case models.RoleAdmin:
+        return 3
+    case models.RoleModerator:
+        return 2
+    case models.RoleUser:
+        return 1
+    default:
+        return 0
+    }
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+    authHeader := c.GetHeader("Authorization")
+    if authHeader == "" {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+        c.Abort()
+        return "", false
+    }
+
+    // Check if header starts with "Bearer "
+    if !strings.HasPrefix(authHeader, "Bearer ") {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+        c.Abort()
+        return "", false
+    }
+
+    // Extract token
+    return strings.TrimPrefix(authHeader, "Bearer "), true
+}
+
+func authenticateJWT(c *gin.Context, authService *services.AuthService, token string) bool {
+    // Validate token
+    claims, err := authService.ValidateToken(token)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+        c.Abort()
+        return false
+    }
+
+    // Reject tokens whose session has been logged out or revoked
+    if err := authService.ValidateSession(claims.ID); err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+        c.Abort()
+        return false
+    }
+
+    // Add user info to context
+    c.Set("user_id", claims.UserID)
+    c.Set("user_email", claims.Email)
+    c.Set("user_role", claims.Role)
+    c.Set("session_token_id", claims.ID)
+
+    return true
+}
+
+// WorkspaceHeader selects the workspace a request works in, by ID or slug.
+// Without it requests use the caller's personal space.
+const WorkspaceHeader = "X-Workspace"
+
+// Workspace resolves the X-Workspace header for authenticated routes and
+// stores the workspace in the context. Non-members get a 404, so the header
+// can't be used to discover which workspaces exist.
+func Workspace(workspaceService *services.WorkspaceService) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        ref := strings.TrimSpace(c.GetHeader(WorkspaceHeader))
+        if ref == "" {
+            c.Next()
+            return
+        }
+
+        workspace, role, err := workspaceService.Resolve(ref, c.GetUint("user_id"))
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+            c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
             c.Abort()
             return
         }
 
-        // Add user info to context
-        c.Set("user_id", claims.UserID)
-        c.Set("user_email", claims.Email)
-        
+        c.Set("workspace_id", workspace.ID)
+        c.Set("workspace_role", role)
+
         c.Next()
     }
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package models
+
+import (
+    "time"
+)
+
+// PersonalAccessToken is a long-lived, scoped credential for scripts and
+// integrations. Only its hash is stored; the raw token is shown once.
+type PersonalAccessToken struct {
+    ID         uint       `json:"id" gorm:"primaryKey"`
+    UserID     uint       `json:"user_id" gorm:"index;not null"`
+    Name       string     `json:"name" gorm:"not null"`
+    TokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+    Prefix     string     `json:"prefix"` // first characters of the token, to tell them apart in the UI
+    Scopes     string     `json:"scopes"` // comma separated, e.g. "resources:read,resources:write"
+    ExpiresAt  *time.Time `json:"expires_at"`
+    LastUsedAt *time.Time `json:"last_used_at"`
+    CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+    "time"
+    "gorm.io/gorm"
+)
+
+// Collection is a user's folder of resources. Collections can be nested
+// and a resource can sit in any number of them.
+type Collection struct {
+    ID          uint           `json:"id" gorm:"primaryKey"`
+    UserID      uint           `json:"user_id" gorm:"index;not null"`
+    ParentID    *uint          `json:"parent_id" gorm:"index"`
+    Name        string         `json:"name" gorm:"not null"`
+    Description string         `json:"description"`
+    Icon        string         `json:"icon"`                      // emoji or icon name chosen by the frontend
+    Position    int            `json:"position" gorm:"default:0"` // order among its siblings
+    CreatedAt   time.Time      `json:"created_at"`
+    UpdatedAt   time.Time      `json:"updated_at"`
+    DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+    // Relationships
+    Resources []Resource `json:"resources,omitempty" gorm:"many2many:collection_resources"`
+}
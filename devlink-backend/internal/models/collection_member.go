@@ -0,0 +1,41 @@
+package models
+
+import (
+    "time"
+)
+
+const (
+    CollectionViewer = "viewer"
+    CollectionEditor = "editor"
+    CollectionOwner  = "owner"
+)
+
+// CollectionMember gives another user access to a shared collection. The
+// collection's creator is always an owner and has no member row.
+type CollectionMember struct {
+    ID           uint      `json:"id" gorm:"primaryKey"`
+    CollectionID uint      `json:"collection_id" gorm:"uniqueIndex:idx_collection_member;not null"`
+    UserID       uint      `json:"user_id" gorm:"uniqueIndex:idx_collection_member;index;not null"`
+    Role         string    `json:"role" gorm:"not null"`
+    InvitedByID  uint      `json:"invited_by_id"`
+    CreatedAt    time.Time `json:"created_at"`
+    UpdatedAt    time.Time `json:"updated_at"`
+
+    // Relationships
+    User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// CollectionInvite is an emailed invitation to join a collection. Only the
+// token's hash is stored.
+type CollectionInvite struct {
+    ID           uint       `json:"id" gorm:"primaryKey"`
+    CollectionID uint       `json:"collection_id" gorm:"index;not null"`
+    Email        string     `json:"email" gorm:"not null"`
+    Role         string     `json:"role" gorm:"not null"`
+    TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+    InvitedByID  uint       `json:"invited_by_id" gorm:"index"`
+    ExpiresAt    time.Time  `json:"expires_at" gorm:"not null"`
+    AcceptedAt   *time.Time `json:"accepted_at"`
+    RevokedAt    *time.Time `json:"revoked_at"`
+    CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+    "time"
+)
+
+type EmailVerificationToken struct {
+    ID        uint       `json:"id" gorm:"primaryKey"`
+    UserID    uint       `json:"user_id" gorm:"index;not null"`
+    Email     string     `json:"email" gorm:"not null"` // address the link was sent to
+    OldEmail  string     `json:"old_email"`             // set when the link confirms an email change
+    TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+    ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+    "time"
+)
+
+// InviteCode lets someone register while registration is invite-only.
+// Only its hash is stored; the raw code is shown once to the admin.
+type InviteCode struct {
+    ID          uint       `json:"id" gorm:"primaryKey"`
+    CodeHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+    Prefix      string     `json:"prefix"` // first characters of the code, to tell them apart in the UI
+    Note        string     `json:"note"`
+    MaxUses     int        `json:"max_uses" gorm:"not null;default:1"`
+    Uses        int        `json:"uses" gorm:"not null;default:0"`
+    ExpiresAt   *time.Time `json:"expires_at"`
+    RevokedAt   *time.Time `json:"revoked_at"`
+    CreatedByID uint       `json:"created_by_id" gorm:"index"`
+    CreatedAt   time.Time  `json:"created_at"`
+}
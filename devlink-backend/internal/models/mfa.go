@@ -0,0 +1,26 @@
+package models
+
+import (
+    "time"
+)
+
+// MFAChallenge is handed out by the first login step when the account has
+// two-factor authentication enabled, and exchanged for tokens in the second.
+type MFAChallenge struct {
+    ID        uint       `json:"id" gorm:"primaryKey"`
+    UserID    uint       `json:"user_id" gorm:"index;not null"`
+    TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+    Attempts  int        `json:"attempts" gorm:"default:0"`
+    ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// RecoveryCode is a one-time fallback for a lost authenticator.
+type RecoveryCode struct {
+    ID        uint       `json:"id" gorm:"primaryKey"`
+    UserID    uint       `json:"user_id" gorm:"index;not null"`
+    CodeHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
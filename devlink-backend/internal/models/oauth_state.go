@@ -0,0 +1,17 @@
+package models
+
+import (
+    "time"
+)
+
+// OAuthState remembers an in-flight authorization request between the
+// redirect to the provider and its callback.
+type OAuthState struct {
+    ID           uint      `json:"id" gorm:"primaryKey"`
+    StateHash    string    `json:"-" gorm:"uniqueIndex;not null"`
+    Provider     string    `json:"provider" gorm:"not null"`
+    CodeVerifier string    `json:"-" gorm:"not null"`
+    Nonce        string    `json:"-" gorm:"not null"`
+    ExpiresAt    time.Time `json:"expires_at" gorm:"index;not null"`
+    CreatedAt    time.Time `json:"created_at"`
+}
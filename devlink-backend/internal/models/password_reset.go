@@ -0,0 +1,14 @@
+package models
+
+import (
+    "time"
+)
+
+type PasswordResetToken struct {
+    ID        uint       `json:"id" gorm:"primaryKey"`
+    UserID    uint       `json:"user_id" gorm:"index;not null"`
+    TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+    ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+    "time"
+)
+
+type RefreshToken struct {
+    ID        uint       `json:"id" gorm:"primaryKey"`
+    UserID    uint       `json:"user_id" gorm:"index;not null"`
+    FamilyID  string     `json:"family_id" gorm:"index;not null"` // TokenID of the session the token belongs to
+    TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`   // SHA-256 of the raw token, the raw value is never stored
+    ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+    UsedAt    *time.Time `json:"used_at"`    // set once the token has been exchanged for a new one
+    RevokedAt *time.Time `json:"revoked_at"` // set on logout or when reuse is detected
+    CreatedAt time.Time  `json:"created_at"`
+}
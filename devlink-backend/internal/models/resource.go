@@ -5,20 +5,46 @@ import (
     "gorm.io/gorm"
 )
 
+// Link health as recorded by the background link checker
+const (
+    LinkUnchecked  = ""
+    LinkOK         = "ok"
+    LinkRedirected = "redirected" // works, but ends up at a different URL
+    LinkBroken     = "broken"
+)
+
 type Resource struct {
     ID          uint           `json:"id" gorm:"primaryKey"`
     Title       string         `json:"title" gorm:"not null"`
     URL         string         `json:"url" gorm:"not null"`
     Description string         `json:"description"`
     Category    string         `json:"category"`
-    Tags        string         `json:"tags"` // JSON string for now, can be normalized later
+    FaviconURL  string         `json:"favicon_url"`
     IsPublic    bool           `json:"is_public" gorm:"default:false"`
+    Archive     bool           `json:"archive" gorm:"default:false"` // opted in to periodic page snapshots
     ClickCount  int            `json:"click_count" gorm:"default:0"`
     UserID      uint           `json:"user_id" gorm:"index;not null"`
+    WorkspaceID *uint          `json:"workspace_id" gorm:"index"` // nil for the owner's personal space
+    HiddenAt    *time.Time     `json:"hidden_at"` // set by moderators to pull a public resource from listings
     CreatedAt   time.Time      `json:"created_at"`
     UpdatedAt   time.Time      `json:"updated_at"`
     DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
     
+    // Link health, maintained by the link checker
+    LinkStatus     string     `json:"link_status" gorm:"index"`
+    LinkStatusCode int        `json:"link_status_code"`            // HTTP status of the last check, 0 when it didn't connect
+    LinkFinalURL   string     `json:"link_final_url"`              // where the URL ended up after redirects
+    LinkCheckedAt  *time.Time `json:"link_checked_at" gorm:"index"`
+    LinkFailures   int        `json:"link_failures" gorm:"default:0"` // consecutive failed checks
+    
+    // When the archiver last tried to snapshot the page, whatever came of it
+    ArchiveAttemptedAt *time.Time `json:"archive_attempted_at" gorm:"index"`
+    
+    // Full-text search over title, tags, description and the latest snapshot;
+    // written with SQL only, see services.refreshSearchVectors
+    SearchVector string `json:"-" gorm:"type:tsvector;->:false;index:idx_resources_search,type:gin"`
+    
     // Relationships
-    User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+    User User  `json:"user,omitempty" gorm:"foreignKey:UserID"`
+    Tags []Tag `json:"tags,omitempty" gorm:"many2many:resource_tags"`
 }
\ No newline at end of file
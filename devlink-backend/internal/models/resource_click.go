@@ -0,0 +1,13 @@
+package models
+
+import (
+    "time"
+)
+
+// ResourceClick records one tracked visit to a resource.
+type ResourceClick struct {
+    ID         uint      `json:"id" gorm:"primaryKey"`
+    ResourceID uint      `json:"resource_id" gorm:"index;not null"`
+    Referrer   string    `json:"referrer"`
+    CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+    "time"
+)
+
+// Session is one login on one device. Access tokens carry the session's
+// TokenID as their jti and its refresh tokens share it as their FamilyID.
+type Session struct {
+    ID         uint       `json:"id" gorm:"primaryKey"`
+    UserID     uint       `json:"user_id" gorm:"index;not null"`
+    TokenID    string     `json:"-" gorm:"uniqueIndex;not null"`
+    UserAgent  string     `json:"user_agent"`
+    IPAddress  string     `json:"ip_address"`
+    LastSeenAt time.Time  `json:"last_seen_at"`
+    RevokedAt  *time.Time `json:"revoked_at"`
+    CreatedAt  time.Time  `json:"created_at"`
+}
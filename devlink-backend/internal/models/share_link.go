@@ -0,0 +1,23 @@
+package models
+
+import (
+    "time"
+)
+
+// ShareLink is an unlisted link to a resource or a collection that works
+// without logging in. Exactly one of ResourceID and CollectionID is set and
+// only the token's hash is stored.
+type ShareLink struct {
+    ID           uint       `json:"id" gorm:"primaryKey"`
+    UserID       uint       `json:"user_id" gorm:"index;not null"`
+    ResourceID   *uint      `json:"resource_id" gorm:"index"`
+    CollectionID *uint      `json:"collection_id" gorm:"index"`
+    TokenHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+    Prefix       string     `json:"prefix"`         // first characters of the token, to tell links apart in the UI
+    PasswordHash string     `json:"-"`              // bcrypt hash, empty when no password is required
+    ViewCount    int        `json:"view_count" gorm:"not null;default:0"`
+    LastViewedAt *time.Time `json:"last_viewed_at"`
+    ExpiresAt    *time.Time `json:"expires_at"`
+    RevokedAt    *time.Time `json:"revoked_at"`
+    CreatedAt    time.Time  `json:"created_at"`
+}
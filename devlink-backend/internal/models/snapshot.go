@@ -0,0 +1,20 @@
+package models
+
+import (
+    "time"
+)
+
+// Snapshot is a saved copy of the readable content of a resource's page.
+// The content itself lives in blob storage under HTMLKey and TextKey.
+type Snapshot struct {
+    ID          uint      `json:"id" gorm:"primaryKey"`
+    ResourceID  uint      `json:"resource_id" gorm:"index;not null"`
+    URL         string    `json:"url" gorm:"not null"` // page the content was taken from, after redirects
+    Title       string    `json:"title"`
+    ContentHash string    `json:"content_hash" gorm:"index;not null"` // hex SHA-256 of the plain text
+    HTMLKey     string    `json:"-" gorm:"not null"`
+    TextKey     string    `json:"-" gorm:"not null"`
+    Size        int       `json:"size"` // bytes of stored HTML and text
+    SearchText  string    `json:"-"`    // start of the plain text, for full-text search
+    CreatedAt   time.Time `json:"created_at"`
+}
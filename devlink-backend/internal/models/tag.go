@@ -0,0 +1,13 @@
+package models
+
+import (
+    "time"
+)
+
+// Tag is a normalized, lowercase label. Tags are shared across users and
+// attached to resources through the resource_tags join table.
+type Tag struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    Name      string    `json:"name" gorm:"uniqueIndex;not null"`
+    CreatedAt time.Time `json:"created_at"`
+}
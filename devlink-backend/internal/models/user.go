@@ -5,14 +5,33 @@ import (
     "gorm.io/gorm"
 )
 
+const (
+    RoleUser      = "user"
+    RoleModerator = "moderator"
+    RoleAdmin     = "admin"
+)
+
 type User struct {
-    ID        uint           `json:"id" gorm:"primaryKey"`
-    Name      string         `json:"name" gorm:"not null"`
-    Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-    Password  string         `json:"-" gorm:"not null"` // "-" excludes from JSON
-    CreatedAt time.Time      `json:"created_at"`
-    UpdatedAt time.Time      `json:"updated_at"`
-    DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+    ID              uint           `json:"id" gorm:"primaryKey"`
+    Name            string         `json:"name" gorm:"not null"`
+    Email           string         `json:"email" gorm:"uniqueIndex;not null"`
+    Password        string         `json:"-" gorm:"not null"` // "-" excludes from JSON
+    Role            string         `json:"role" gorm:"default:user;not null"`
+    Bio             string         `json:"bio"`
+    AvatarURL       string         `json:"avatar_url"`
+    Website         string         `json:"website"`
+    Timezone        string         `json:"timezone" gorm:"default:UTC;not null"`
+    DefaultPublic   bool           `json:"default_public" gorm:"default:false"` // visibility for new resources
+    DisabledAt      *time.Time     `json:"disabled_at"`
+    DeleteAfter     *time.Time     `json:"delete_after"` // set when the user asks to delete the account
+    EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+    TOTPSecret      string         `json:"-"` // set during enrollment, before it is enabled
+    TOTPEnabledAt   *time.Time     `json:"totp_enabled_at"`
+    TOTPLastStep    int64          `json:"-"` // last accepted time step, blocks code replay
+    Placeholder     bool           `json:"-" gorm:"default:false;not null"` // owner of anonymized resources, never a real account
+    CreatedAt       time.Time      `json:"created_at"`
+    UpdatedAt       time.Time      `json:"updated_at"`
+    DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
     
     // Relationships
     Resources []Resource `json:"resources,omitempty"`
@@ -0,0 +1,16 @@
+package models
+
+import (
+    "time"
+)
+
+// UserIdentity links a User to an account at an external OAuth/OIDC provider.
+type UserIdentity struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    UserID    uint      `json:"user_id" gorm:"index;not null"`
+    Provider  string    `json:"provider" gorm:"uniqueIndex:idx_identity_provider_subject;not null"`
+    Subject   string    `json:"-" gorm:"uniqueIndex:idx_identity_provider_subject;not null"`
+    Email     string    `json:"email"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
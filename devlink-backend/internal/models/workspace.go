@@ -0,0 +1,36 @@
+package models
+
+import (
+    "time"
+    "gorm.io/gorm"
+)
+
+const (
+    WorkspaceRoleMember = "member"
+    WorkspaceRoleAdmin  = "admin"
+    WorkspaceRoleOwner  = "owner"
+)
+
+// Workspace is a team's separate DevLink space. Resources saved in it are
+// only ever visible to its members.
+type Workspace struct {
+    ID          uint           `json:"id" gorm:"primaryKey"`
+    Name        string         `json:"name" gorm:"not null"`
+    Slug        string         `json:"slug" gorm:"uniqueIndex;not null"`
+    CreatedByID uint           `json:"created_by_id"`
+    CreatedAt   time.Time      `json:"created_at"`
+    UpdatedAt   time.Time      `json:"updated_at"`
+    DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type WorkspaceMember struct {
+    ID          uint      `json:"id" gorm:"primaryKey"`
+    WorkspaceID uint      `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_member;not null"`
+    UserID      uint      `json:"user_id" gorm:"uniqueIndex:idx_workspace_member;index;not null"`
+    Role        string    `json:"role" gorm:"not null"`
+    CreatedAt   time.Time `json:"created_at"`
+    UpdatedAt   time.Time `json:"updated_at"`
+
+    // Relationships
+    User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
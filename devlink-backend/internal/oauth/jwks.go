@@ -0,0 +1,115 @@
+package oauth
+
+import (
+    "context"
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/ed25519"
+    "crypto/elliptic"
+    "crypto/rsa"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "math/big"
+    "net/http"
+)
+
+type jsonWebKey struct {
+    Kty string `json:"kty"`
+    Kid string `json:"kid"`
+    Use string `json:"use"`
+    N   string `json:"n"`
+    E   string `json:"e"`
+    Crv string `json:"crv"`
+    X   string `json:"x"`
+    Y   string `json:"y"`
+}
+
+// fetchJWKS downloads a JSON Web Key Set and returns its signing keys by kid.
+// Keys of unsupported types are skipped rather than failing the whole set.
+func fetchJWKS(ctx context.Context, client *http.Client, jwksURL string) (map[string]crypto.PublicKey, error) {
+    var set struct {
+        Keys []jsonWebKey `json:"keys"`
+    }
+    if err := getJSON(ctx, client, jwksURL, "", &set); err != nil {
+        return nil, err
+    }
+
+    keys := make(map[string]crypto.PublicKey)
+    for _, jwk := range set.Keys {
+        if jwk.Use != "" && jwk.Use != "sig" {
+            continue
+        }
+        key, err := jwk.publicKey()
+        if err != nil {
+            continue
+        }
+        keys[jwk.Kid] = key
+    }
+
+    if len(keys) == 0 {
+        return nil, errors.New("JWKS contains no usable signing keys")
+    }
+    return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+    switch k.Kty {
+    case "RSA":
+        n, err := decodeBigInt(k.N)
+        if err != nil {
+            return nil, err
+        }
+        e, err := decodeBigInt(k.E)
+        if err != nil {
+            return nil, err
+        }
+        return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+    case "EC":
+        var curve elliptic.Curve
+        switch k.Crv {
+        case "P-256":
+            curve = elliptic.P256()
+        case "P-384":
+            curve = elliptic.P384()
+        case "P-521":
+            curve = elliptic.P521()
+        default:
+            return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+        }
+        x, err := decodeBigInt(k.X)
+        if err != nil {
+            return nil, err
+        }
+        y, err := decodeBigInt(k.Y)
+        if err != nil {
+            return nil, err
+        }
+        return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+    case "OKP":
+        if k.Crv != "Ed25519" {
+            return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+        }
+        x, err := base64.RawURLEncoding.DecodeString(k.X)
+        if err != nil || len(x) != ed25519.PublicKeySize {
+            return nil, errors.New("invalid Ed25519 key")
+        }
+        return ed25519.PublicKey(x), nil
+
+    default:
+        return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+    }
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+    raw, err := base64.RawURLEncoding.DecodeString(value)
+    if err != nil {
+        return nil, err
+    }
+    if len(raw) == 0 {
+        return nil, errors.New("empty key component")
+    }
+    return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,235 @@
+package oauth
+
+import (
+    "context"
+    "crypto"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+type discoveryDocument struct {
+    Issuer                string `json:"issuer"`
+    AuthorizationEndpoint string `json:"authorization_endpoint"`
+    TokenEndpoint         string `json:"token_endpoint"`
+    UserinfoEndpoint      string `json:"userinfo_endpoint"`
+    JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider is a generic OpenID Connect provider configured entirely
+// from the issuer's discovery document.
+type OIDCProvider struct {
+    name   string
+    issuer string
+    cfg    Config
+    client *http.Client
+
+    mu        sync.Mutex
+    discovery *discoveryDocument
+    keys      map[string]crypto.PublicKey
+}
+
+func NewOIDCProvider(name, issuer string, cfg Config, client *http.Client) *OIDCProvider {
+    if len(cfg.Scopes) == 0 {
+        cfg.Scopes = []string{"openid", "email", "profile"}
+    }
+
+    return &OIDCProvider{
+        name:   name,
+        issuer: strings.TrimRight(issuer, "/"),
+        cfg:    cfg,
+        client: defaultClient(client),
+    }
+}
+
+func NewGoogleProvider(cfg Config, client *http.Client) *OIDCProvider {
+    return NewOIDCProvider("google", "https://accounts.google.com", cfg, client)
+}
+
+func (p *OIDCProvider) Name() string {
+    return p.name
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error) {
+    doc, err := p.discover(ctx)
+    if err != nil {
+        return "", err
+    }
+
+    params := url.Values{
+        "response_type":         {"code"},
+        "client_id":             {p.cfg.ClientID},
+        "redirect_uri":          {p.cfg.RedirectURL},
+        "scope":                 {strings.Join(p.cfg.Scopes, " ")},
+        "state":                 {state},
+        "nonce":                 {nonce},
+        "code_challenge":        {codeChallenge},
+        "code_challenge_method": {"S256"},
+    }
+
+    separator := "?"
+    if strings.Contains(doc.AuthorizationEndpoint, "?") {
+        separator = "&"
+    }
+    return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+type idTokenClaims struct {
+    Email         string   `json:"email"`
+    EmailVerified flexBool `json:"email_verified"`
+    Name          string   `json:"name"`
+    Nonce         string   `json:"nonce"`
+    jwt.RegisteredClaims
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*UserInfo, error) {
+    doc, err := p.discover(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    token, err := exchangeCode(ctx, p.client, doc.TokenEndpoint, p.cfg, code, codeVerifier)
+    if err != nil {
+        return nil, err
+    }
+    if token.IDToken == "" {
+        return nil, errors.New("provider did not return an id_token")
+    }
+
+    claims := &idTokenClaims{}
+    _, err = jwt.ParseWithClaims(token.IDToken, claims, func(t *jwt.Token) (interface{}, error) {
+        kid, _ := t.Header["kid"].(string)
+        return p.signingKey(ctx, doc, kid)
+    },
+        jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+        jwt.WithIssuer(doc.Issuer),
+        jwt.WithAudience(p.cfg.ClientID),
+        jwt.WithExpirationRequired(),
+    )
+    if err != nil {
+        return nil, fmt.Errorf("invalid id_token: %w", err)
+    }
+
+    if claims.Nonce != nonce {
+        return nil, errors.New("invalid id_token: nonce mismatch")
+    }
+    if claims.Subject == "" {
+        return nil, errors.New("invalid id_token: missing subject")
+    }
+
+    info := &UserInfo{
+        Subject:       claims.Subject,
+        Email:         claims.Email,
+        EmailVerified: bool(claims.EmailVerified),
+        Name:          claims.Name,
+    }
+
+    // Some providers keep profile claims out of the ID token
+    if info.Email == "" && doc.UserinfoEndpoint != "" {
+        var userinfo struct {
+            Subject       string   `json:"sub"`
+            Email         string   `json:"email"`
+            EmailVerified flexBool `json:"email_verified"`
+            Name          string   `json:"name"`
+        }
+        if err := getJSON(ctx, p.client, doc.UserinfoEndpoint, token.AccessToken, &userinfo); err != nil {
+            return nil, err
+        }
+        if userinfo.Subject != info.Subject {
+            return nil, errors.New("userinfo subject does not match id_token")
+        }
+        info.Email = userinfo.Email
+        info.EmailVerified = bool(userinfo.EmailVerified)
+        if info.Name == "" {
+            info.Name = userinfo.Name
+        }
+    }
+
+    return info, nil
+}
+
+// discover fetches and caches the issuer's discovery document.
+func (p *OIDCProvider) discover(ctx context.Context) (*discoveryDocument, error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if p.discovery != nil {
+        return p.discovery, nil
+    }
+
+    var doc discoveryDocument
+    if err := getJSON(ctx, p.client, p.issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+        return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+    }
+    if strings.TrimRight(doc.Issuer, "/") != p.issuer {
+        return nil, fmt.Errorf("OIDC discovery issuer %q does not match %q", doc.Issuer, p.issuer)
+    }
+    if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+        return nil, errors.New("OIDC discovery document is missing required endpoints")
+    }
+
+    p.discovery = &doc
+    return p.discovery, nil
+}
+
+// signingKey looks up a key by kid, refetching the JWKS once when the kid is
+// unknown so provider key rotation is picked up automatically.
+func (p *OIDCProvider) signingKey(ctx context.Context, doc *discoveryDocument, kid string) (crypto.PublicKey, error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if key, ok := p.lookupKey(kid); ok {
+        return key, nil
+    }
+
+    keys, err := fetchJWKS(ctx, p.client, doc.JWKSURI)
+    if err != nil {
+        return nil, err
+    }
+    p.keys = keys
+
+    if key, ok := p.lookupKey(kid); ok {
+        return key, nil
+    }
+    return nil, fmt.Errorf("no signing key found for kid %q", kid)
+}
+
+func (p *OIDCProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+    if key, ok := p.keys[kid]; ok {
+        return key, true
+    }
+    // Tokens without a kid are fine as long as the set has exactly one key
+    if kid == "" && len(p.keys) == 1 {
+        for _, key := range p.keys {
+            return key, true
+        }
+    }
+    return nil, false
+}
+
+// flexBool accepts both true and "true", since some providers send
+// email_verified as a string.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+    var value interface{}
+    if err := json.Unmarshal(data, &value); err != nil {
+        return err
+    }
+
+    switch v := value.(type) {
+    case bool:
+        *b = flexBool(v)
+    case string:
+        *b = flexBool(v == "true")
+    default:
+        *b = false
+    }
+    return nil
+}
@@ -0,0 +1,329 @@
+package oauth
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/rsa"
+    "encoding/base64"
+    "encoding/json"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+    testClientID = "devlink"
+    testKeyID    = "key-1"
+)
+
+// mockIdP is an OpenID Connect provider on an httptest server. Codes are
+// granted up front with the PKCE challenge they were issued for and the ID
+// token they redeem to.
+type mockIdP struct {
+    t      *testing.T
+    server *httptest.Server
+    key    *rsa.PrivateKey
+    issuer string // announced in discovery, the server URL unless overridden
+
+    mu       sync.Mutex
+    grants   map[string]mockGrant
+    userinfo map[string]interface{}
+}
+
+type mockGrant struct {
+    challenge string
+    idToken   string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+    t.Helper()
+
+    key, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatal(err)
+    }
+    idp := &mockIdP{t: t, key: key, grants: make(map[string]mockGrant)}
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]string{
+            "issuer":                 idp.issuer,
+            "authorization_endpoint": idp.server.URL + "/authorize",
+            "token_endpoint":         idp.server.URL + "/token",
+            "userinfo_endpoint":      idp.server.URL + "/userinfo",
+            "jwks_uri":               idp.server.URL + "/jwks",
+        })
+    })
+    mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+        writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []map[string]string{{
+            "kty": "RSA",
+            "kid": testKeyID,
+            "use": "sig",
+            "n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+            "e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+        }}})
+    })
+    mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+        if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+            writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+            return
+        }
+        idp.mu.Lock()
+        grant, ok := idp.grants[r.PostForm.Get("code")]
+        delete(idp.grants, r.PostForm.Get("code"))
+        idp.mu.Unlock()
+
+        switch {
+        case !ok || r.PostForm.Get("client_id") != testClientID:
+            writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+        case CodeChallengeS256(r.PostForm.Get("code_verifier")) != grant.challenge:
+            writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+        default:
+            writeJSON(w, http.StatusOK, map[string]string{
+                "access_token": "access-" + r.PostForm.Get("code"),
+                "token_type":   "Bearer",
+                "id_token":     grant.idToken,
+            })
+        }
+    })
+    mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+        idp.mu.Lock()
+        defer idp.mu.Unlock()
+        if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer access-") || idp.userinfo == nil {
+            writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+            return
+        }
+        writeJSON(w, http.StatusOK, idp.userinfo)
+    })
+
+    idp.server = httptest.NewServer(mux)
+    idp.issuer = idp.server.URL
+    t.Cleanup(idp.server.Close)
+    return idp
+}
+
+func (idp *mockIdP) provider() *OIDCProvider {
+    return NewOIDCProvider("mock", idp.server.URL, Config{
+        ClientID:     testClientID,
+        ClientSecret: "secret",
+        RedirectURL:  "http://app.test/callback",
+    }, idp.server.Client())
+}
+
+// claims are valid ID token claims for the given nonce.
+func (idp *mockIdP) claims(nonce string) jwt.MapClaims {
+    return jwt.MapClaims{
+        "iss":            idp.server.URL,
+        "aud":            testClientID,
+        "sub":            "user-1",
+        "email":          "user@example.com",
+        "email_verified": "true", // some providers send a string
+        "name":           "Test User",
+        "nonce":          nonce,
+        "iat":            time.Now().Unix(),
+        "exp":            time.Now().Add(5 * time.Minute).Unix(),
+    }
+}
+
+func (idp *mockIdP) sign(claims jwt.MapClaims) string {
+    token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+    token.Header["kid"] = testKeyID
+    signed, err := token.SignedString(idp.key)
+    if err != nil {
+        idp.t.Fatal(err)
+    }
+    return signed
+}
+
+// grant issues code for a login that started with verifier and redeems to idToken.
+func (idp *mockIdP) grant(code, verifier, idToken string) {
+    idp.mu.Lock()
+    defer idp.mu.Unlock()
+    idp.grants[code] = mockGrant{challenge: CodeChallengeS256(verifier), idToken: idToken}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(body)
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+    idp := newMockIdP(t)
+
+    verifier, _ := NewCodeVerifier()
+    authURL, err := idp.provider().AuthCodeURL(context.Background(), "the-state", CodeChallengeS256(verifier), "the-nonce")
+    if err != nil {
+        t.Fatalf("AuthCodeURL: %v", err)
+    }
+    parsed, err := url.Parse(authURL)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != idp.server.URL+"/authorize" {
+        t.Errorf("endpoint = %s, want the discovered authorization endpoint", got)
+    }
+
+    want := map[string]string{
+        "response_type":         "code",
+        "client_id":             testClientID,
+        "redirect_uri":          "http://app.test/callback",
+        "state":                 "the-state",
+        "nonce":                 "the-nonce",
+        "code_challenge":        CodeChallengeS256(verifier),
+        "code_challenge_method": "S256",
+    }
+    for name, value := range want {
+        if got := parsed.Query().Get(name); got != value {
+            t.Errorf("%s = %q, want %q", name, got, value)
+        }
+    }
+}
+
+func TestOIDCExchange(t *testing.T) {
+    idp := newMockIdP(t)
+    verifier, _ := NewCodeVerifier()
+    idp.grant("code-1", verifier, idp.sign(idp.claims("nonce-1")))
+
+    info, err := idp.provider().Exchange(context.Background(), "code-1", verifier, "nonce-1")
+    if err != nil {
+        t.Fatalf("Exchange: %v", err)
+    }
+    if info.Subject != "user-1" || info.Email != "user@example.com" || !info.EmailVerified || info.Name != "Test User" {
+        t.Errorf("info = %+v", info)
+    }
+}
+
+func TestOIDCExchangeRequiresPKCEVerifier(t *testing.T) {
+    idp := newMockIdP(t)
+    verifier, _ := NewCodeVerifier()
+    other, _ := NewCodeVerifier()
+    idp.grant("code-1", verifier, idp.sign(idp.claims("nonce-1")))
+
+    _, err := idp.provider().Exchange(context.Background(), "code-1", other, "nonce-1")
+    if err == nil || !strings.Contains(err.Error(), "PKCE") {
+        t.Fatalf("err = %v, want the token endpoint's PKCE rejection", err)
+    }
+}
+
+func TestOIDCExchangeRejectsNonceMismatch(t *testing.T) {
+    idp := newMockIdP(t)
+    verifier, _ := NewCodeVerifier()
+    idp.grant("code-1", verifier, idp.sign(idp.claims("nonce-of-another-login")))
+
+    _, err := idp.provider().Exchange(context.Background(), "code-1", verifier, "nonce-1")
+    if err == nil || !strings.Contains(err.Error(), "nonce") {
+        t.Fatalf("err = %v, want a nonce mismatch", err)
+    }
+}
+
+func TestOIDCExchangeValidatesIDToken(t *testing.T) {
+    idp := newMockIdP(t)
+    otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name  string
+        token func(claims jwt.MapClaims) string
+    }{
+        {"wrong issuer", func(c jwt.MapClaims) string {
+            c["iss"] = "https://evil.example.com"
+            return idp.sign(c)
+        }},
+        {"wrong audience", func(c jwt.MapClaims) string {
+            c["aud"] = "another-client"
+            return idp.sign(c)
+        }},
+        {"expired", func(c jwt.MapClaims) string {
+            c["exp"] = time.Now().Add(-time.Minute).Unix()
+            return idp.sign(c)
+        }},
+        {"no expiry", func(c jwt.MapClaims) string {
+            delete(c, "exp")
+            return idp.sign(c)
+        }},
+        {"no subject", func(c jwt.MapClaims) string {
+            delete(c, "sub")
+            return idp.sign(c)
+        }},
+        {"signed by another key", func(c jwt.MapClaims) string {
+            token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+            token.Header["kid"] = testKeyID
+            signed, _ := token.SignedString(otherKey)
+            return signed
+        }},
+        {"unknown key ID", func(c jwt.MapClaims) string {
+            token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+            token.Header["kid"] = "key-2"
+            signed, _ := token.SignedString(idp.key)
+            return signed
+        }},
+        {"HMAC with the client secret", func(c jwt.MapClaims) string {
+            signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte("secret"))
+            return signed
+        }},
+        {"unsigned", func(c jwt.MapClaims) string {
+            signed, _ := jwt.NewWithClaims(jwt.SigningMethodNone, c).SignedString(jwt.UnsafeAllowNoneSignatureType)
+            return signed
+        }},
+        {"garbage", func(c jwt.MapClaims) string {
+            return "not.a.jwt"
+        }},
+    }
+
+    provider := idp.provider()
+    for i, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            verifier, _ := NewCodeVerifier()
+            code := "code-" + string(rune('a'+i))
+            idp.grant(code, verifier, tt.token(idp.claims("nonce-1")))
+
+            if _, err := provider.Exchange(context.Background(), code, verifier, "nonce-1"); err == nil {
+                t.Fatal("expected the ID token to be rejected")
+            }
+        })
+    }
+}
+
+func TestOIDCExchangeFetchesUserinfo(t *testing.T) {
+    idp := newMockIdP(t)
+    verifier, _ := NewCodeVerifier()
+    claims := idp.claims("nonce-1")
+    delete(claims, "email")
+    delete(claims, "email_verified")
+    idp.grant("code-1", verifier, idp.sign(claims))
+    idp.userinfo = map[string]interface{}{"sub": "user-1", "email": "user@example.com", "email_verified": true}
+
+    info, err := idp.provider().Exchange(context.Background(), "code-1", verifier, "nonce-1")
+    if err != nil {
+        t.Fatalf("Exchange: %v", err)
+    }
+    if info.Email != "user@example.com" || !info.EmailVerified {
+        t.Errorf("info = %+v, want the userinfo email", info)
+    }
+
+    // A userinfo response about someone else must not be trusted
+    idp.grant("code-2", verifier, idp.sign(claims))
+    idp.userinfo = map[string]interface{}{"sub": "user-2", "email": "other@example.com", "email_verified": true}
+    if _, err := idp.provider().Exchange(context.Background(), "code-2", verifier, "nonce-1"); err == nil {
+        t.Fatal("expected a userinfo subject mismatch to be rejected")
+    }
+}
+
+func TestOIDCDiscoveryRejectsIssuerMismatch(t *testing.T) {
+    idp := newMockIdP(t)
+    idp.issuer = "https://evil.example.com"
+
+    if _, err := idp.provider().AuthCodeURL(context.Background(), "state", "challenge", "nonce"); err == nil {
+        t.Fatal("expected discovery with a different issuer to fail")
+    }
+}
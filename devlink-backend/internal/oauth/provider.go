@@ -0,0 +1,127 @@
+package oauth
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// UserInfo is the identity a provider vouches for after a successful login.
+type UserInfo struct {
+    Subject       string // stable provider-side user ID
+    Email         string
+    EmailVerified bool
+    Name          string
+}
+
+// Provider is one external identity provider. Implementations handle the
+// authorization code flow with PKCE; state handling is left to the caller.
+type Provider interface {
+    Name() string
+    AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error)
+    Exchange(ctx context.Context, code, codeVerifier, nonce string) (*UserInfo, error)
+}
+
+// Config holds the client registration shared by every provider.
+type Config struct {
+    ClientID     string
+    ClientSecret string
+    RedirectURL  string
+    Scopes       []string
+}
+
+func defaultClient(client *http.Client) *http.Client {
+    if client != nil {
+        return client
+    }
+    return &http.Client{Timeout: 10 * time.Second}
+}
+
+type tokenResponse struct {
+    AccessToken      string `json:"access_token"`
+    TokenType        string `json:"token_type"`
+    IDToken          string `json:"id_token"`
+    Error            string `json:"error"`
+    ErrorDescription string `json:"error_description"`
+}
+
+// exchangeCode redeems an authorization code at the provider's token endpoint.
+func exchangeCode(ctx context.Context, client *http.Client, tokenURL string, cfg Config, code, codeVerifier string) (*tokenResponse, error) {
+    form := url.Values{
+        "grant_type":    {"authorization_code"},
+        "code":          {code},
+        "redirect_uri":  {cfg.RedirectURL},
+        "client_id":     {cfg.ClientID},
+        "client_secret": {cfg.ClientSecret},
+        "code_verifier": {codeVerifier},
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    req.Header.Set("Accept", "application/json")
+
+    var token tokenResponse
+    status, err := doJSON(client, req, &token)
+    if err != nil {
+        return nil, fmt.Errorf("token exchange failed: %w", err)
+    }
+    if token.Error != "" {
+        return nil, fmt.Errorf("token exchange failed: %s %s", token.Error, token.ErrorDescription)
+    }
+    if status != http.StatusOK || token.AccessToken == "" {
+        return nil, fmt.Errorf("token exchange failed with status %d", status)
+    }
+
+    return &token, nil
+}
+
+// getJSON fetches url with an optional bearer token and decodes the body into out.
+func getJSON(ctx context.Context, client *http.Client, rawURL, accessToken string, out interface{}) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Accept", "application/json")
+    if accessToken != "" {
+        req.Header.Set("Authorization", "Bearer "+accessToken)
+    }
+
+    status, err := doJSON(client, req, out)
+    if err != nil {
+        return err
+    }
+    if status != http.StatusOK {
+        return fmt.Errorf("GET %s returned status %d", rawURL, status)
+    }
+    return nil
+}
+
+func doJSON(client *http.Client, req *http.Request, out interface{}) (int, error) {
+    resp, err := client.Do(req)
+    if err != nil {
+        return 0, err
+    }
+    defer resp.Body.Close()
+
+    // Provider responses are small, anything bigger is not what we asked for
+    body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+    if err != nil {
+        return resp.StatusCode, err
+    }
+    if len(body) == 0 {
+        return resp.StatusCode, errors.New("empty response body")
+    }
+    if err := json.Unmarshal(body, out); err != nil {
+        return resp.StatusCode, err
+    }
+    return resp.StatusCode, nil
+}
@@ -0,0 +1,583 @@
+// Package searchindex is a small embedded full-text index for resources,
+// for deployments that don't want to rely on the database for search. It
+// keeps an inverted index in memory, matches words exactly, by prefix and
+// with typos, and saves its documents to a single file.
+package searchindex
+
+import (
+    "bufio"
+    "context"
+    "encoding/gob"
+    "errors"
+    "html"
+    "log"
+    "math"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Fields of a document
+const (
+    fieldTitle = iota
+    fieldTags
+    fieldCategory
+    fieldDescription
+    fieldURL
+    fieldContent
+    numFields
+)
+
+// How much a match in each field counts
+var fieldWeights = [numFields]float64{3, 2.5, 1.5, 1.2, 1, 0.5}
+
+const (
+    // MaxContentBytes of page text are indexed per document
+    MaxContentBytes = 20000
+
+    maxExpansions = 50  // indexed words one query word may stand for
+    snippetBefore = 10  // words of context before the first match
+    snippetAfter  = 20  // and after it
+    formatVersion = 1   // of the saved file; other versions are ignored
+)
+
+// Document is what gets indexed for one resource.
+type Document struct {
+    ID          uint
+    Title       string
+    Description string
+    URL         string
+    Category    string
+    Tags        []string
+    Content     string // text of the page snapshot, if any
+}
+
+// Hit is a matching document and how well it matched.
+type Hit struct {
+    ID    uint
+    Score float64
+}
+
+// FacetCount is how many results share a value.
+type FacetCount struct {
+    Value string `json:"value"`
+    Count int    `json:"count"`
+}
+
+// Facets break results down by category and tag, most common first.
+type Facets struct {
+    Categories []FacetCount `json:"categories"`
+    Tags       []FacetCount `json:"tags"`
+}
+
+type posting struct {
+    field uint8
+    pos   uint32
+}
+
+type entry struct {
+    doc   Document
+    terms []string // distinct indexed words, to find the postings on removal
+}
+
+// expansion is an indexed word that a query word stands for.
+type expansion struct {
+    term  string
+    boost float64 // 1 for the word itself, less for prefixes and typos
+}
+
+// Index is safe for concurrent use.
+type Index struct {
+    path string // "" keeps the index in memory only
+
+    mu    sync.RWMutex
+    docs  map[uint]*entry
+    terms map[string]map[uint][]posting
+    dirty bool // changed since the last save
+}
+
+type savedIndex struct {
+    Version int
+    Docs    []Document
+}
+
+// New returns an empty index kept in memory only.
+func New() *Index {
+    return &Index{
+        docs:  make(map[uint]*entry),
+        terms: make(map[string]map[uint][]posting),
+    }
+}
+
+// Open loads the index saved at path. A missing, unreadable or outdated
+// file gives an empty index, which the caller is expected to rebuild.
+func Open(path string) (*Index, error) {
+    if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+        return nil, err
+    }
+
+    ix := New()
+    ix.path = path
+
+    file, err := os.Open(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return ix, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    var saved savedIndex
+    if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&saved); err != nil || saved.Version != formatVersion {
+        log.Printf("Ignoring unreadable search index %s", path)
+        return ix, nil
+    }
+    for _, doc := range saved.Docs {
+        ix.add(doc)
+    }
+    return ix, nil
+}
+
+// Len is the number of indexed documents.
+func (ix *Index) Len() int {
+    ix.mu.RLock()
+    defer ix.mu.RUnlock()
+    return len(ix.docs)
+}
+
+// Put adds a document or replaces the one with the same ID.
+func (ix *Index) Put(doc Document) {
+    ix.mu.Lock()
+    defer ix.mu.Unlock()
+    ix.add(doc)
+    ix.dirty = true
+}
+
+// Delete removes a document; unknown IDs are ignored.
+func (ix *Index) Delete(id uint) {
+    ix.mu.Lock()
+    defer ix.mu.Unlock()
+    if _, ok := ix.docs[id]; ok {
+        ix.remove(id)
+        ix.dirty = true
+    }
+}
+
+// Replace swaps the whole contents of the index for docs.
+func (ix *Index) Replace(docs []Document) {
+    fresh := New()
+    for _, doc := range docs {
+        fresh.add(doc)
+    }
+
+    ix.mu.Lock()
+    defer ix.mu.Unlock()
+    ix.docs, ix.terms = fresh.docs, fresh.terms
+    ix.dirty = true
+}
+
+// Search finds documents matching a web-search style query, best first,
+// at most limit of them. Words may match with a typo or as a prefix.
+func (ix *Index) Search(text string, limit int) []Hit {
+    q := parseQuery(text)
+    if len(q.clauses) == 0 {
+        return nil
+    }
+
+    ix.mu.RLock()
+    defer ix.mu.RUnlock()
+
+    cache := make(map[string][]expansion)
+    fuzzy := func(word string) []expansion {
+        if _, ok := cache[word]; !ok {
+            cache[word] = ix.expand(word)
+        }
+        return cache[word]
+    }
+
+    var scores map[uint]float64
+    for i, c := range q.clauses {
+        matched := make(map[uint]float64)
+        for _, p := range c {
+            for id, score := range ix.matchPhrase(p, fuzzy) {
+                matched[id] = math.Max(matched[id], score)
+            }
+        }
+
+        if i == 0 {
+            scores = matched
+            continue
+        }
+        for id := range scores {
+            if score, ok := matched[id]; ok {
+                scores[id] += score
+            } else {
+                delete(scores, id)
+            }
+        }
+    }
+
+    exact := func(word string) []expansion { return []expansion{{term: word, boost: 1}} }
+    for _, p := range q.excluded {
+        for id := range ix.matchPhrase(p, exact) {
+            delete(scores, id)
+        }
+    }
+
+    hits := make([]Hit, 0, len(scores))
+    for id, score := range scores {
+        hits = append(hits, Hit{ID: id, Score: score})
+    }
+    // Newer resources have higher IDs and win ties
+    sort.Slice(hits, func(i, j int) bool {
+        if hits[i].Score != hits[j].Score {
+            return hits[i].Score > hits[j].Score
+        }
+        return hits[i].ID > hits[j].ID
+    })
+    if limit > 0 && len(hits) > limit {
+        hits = hits[:limit]
+    }
+    return hits
+}
+
+// Facets counts the categories and tags of the given documents, keeping
+// the size most common of each.
+func (ix *Index) Facets(ids []uint, size int) Facets {
+    categories := make(map[string]int)
+    tags := make(map[string]int)
+
+    ix.mu.RLock()
+    for _, id := range ids {
+        entry, ok := ix.docs[id]
+        if !ok {
+            continue
+        }
+        if entry.doc.Category != "" {
+            categories[entry.doc.Category]++
+        }
+        for _, tag := range entry.doc.Tags {
+            tags[tag]++
+        }
+    }
+    ix.mu.RUnlock()
+
+    return Facets{
+        Categories: topCounts(categories, size),
+        Tags:       topCounts(tags, size),
+    }
+}
+
+// Highlight returns the document's title and a snippet of its description
+// or page text, HTML-escaped, with words matching the query wrapped in
+// <mark>. The snippet is empty when neither matched.
+func (ix *Index) Highlight(id uint, text string) (title, snippet string) {
+    words := parseQuery(text).words()
+
+    ix.mu.RLock()
+    entry, ok := ix.docs[id]
+    ix.mu.RUnlock()
+    if !ok {
+        return "", ""
+    }
+
+    matches := func(term string) bool {
+        for _, word := range words {
+            if term == word || len(word) >= 3 && strings.HasPrefix(term, word) {
+                return true
+            }
+            if _, ok := withinDistance(term, word, fuzziness(word)); ok {
+                return true
+            }
+        }
+        return false
+    }
+
+    doc := entry.doc
+    title = mark(doc.Title, tokenize(doc.Title), matches)
+    for _, source := range []string{doc.Description, doc.Content} {
+        spans := tokenize(source)
+        for i, s := range spans {
+            if !matches(s.term) {
+                continue
+            }
+            from, to := max(0, i-snippetBefore), min(len(spans), i+snippetAfter)
+            window := source[spans[from].start:spans[to-1].end]
+            snippet = mark(window, shift(spans[from:to], spans[from].start), matches)
+            if from > 0 {
+                snippet = "… " + snippet
+            }
+            if spans[to-1].end < len(source) {
+                snippet += " …"
+            }
+            return title, snippet
+        }
+    }
+    return title, ""
+}
+
+// Flush saves the index if it changed since the last save.
+func (ix *Index) Flush() error {
+    ix.mu.Lock()
+    if ix.path == "" || !ix.dirty {
+        ix.mu.Unlock()
+        return nil
+    }
+    saved := savedIndex{Version: formatVersion, Docs: make([]Document, 0, len(ix.docs))}
+    for _, entry := range ix.docs {
+        saved.Docs = append(saved.Docs, entry.doc)
+    }
+    ix.dirty = false
+    ix.mu.Unlock()
+
+    if err := ix.write(saved); err != nil {
+        ix.mu.Lock()
+        ix.dirty = true
+        ix.mu.Unlock()
+        return err
+    }
+    return nil
+}
+
+// Run saves changes every interval until ctx is cancelled, then once more.
+func (ix *Index) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            if err := ix.Flush(); err != nil {
+                log.Printf("Saving search index failed: %v", err)
+            }
+            return
+        case <-ticker.C:
+            if err := ix.Flush(); err != nil {
+                log.Printf("Saving search index failed: %v", err)
+            }
+        }
+    }
+}
+
+// write saves to a temporary file first so a crash never leaves half an index.
+func (ix *Index) write(saved savedIndex) error {
+    tmp, err := os.CreateTemp(filepath.Dir(ix.path), ".search-index-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+
+    buf := bufio.NewWriter(tmp)
+    if err := gob.NewEncoder(buf).Encode(saved); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := buf.Flush(); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), ix.path)
+}
+
+// add indexes a document. The caller holds the write lock.
+func (ix *Index) add(doc Document) {
+    ix.remove(doc.ID)
+
+    if len(doc.Content) > MaxContentBytes {
+        doc.Content = strings.ToValidUTF8(doc.Content[:MaxContentBytes], "")
+    }
+    entry := &entry{doc: doc}
+    fields := [numFields]string{doc.Title, strings.Join(doc.Tags, " "), doc.Category, doc.Description, doc.URL, doc.Content}
+    for field, text := range fields {
+        for pos, s := range tokenize(text) {
+            docs, ok := ix.terms[s.term]
+            if !ok {
+                docs = make(map[uint][]posting)
+                ix.terms[s.term] = docs
+            }
+            if _, ok := docs[doc.ID]; !ok {
+                entry.terms = append(entry.terms, s.term)
+            }
+            docs[doc.ID] = append(docs[doc.ID], posting{field: uint8(field), pos: uint32(pos)})
+        }
+    }
+    ix.docs[doc.ID] = entry
+}
+
+// remove drops a document. The caller holds the write lock.
+func (ix *Index) remove(id uint) {
+    entry, ok := ix.docs[id]
+    if !ok {
+        return
+    }
+    for _, term := range entry.terms {
+        delete(ix.terms[term], id)
+        if len(ix.terms[term]) == 0 {
+            delete(ix.terms, term)
+        }
+    }
+    delete(ix.docs, id)
+}
+
+// expand lists the indexed words a query word stands for: itself, longer
+// words it starts, and words within a few typos of it.
+func (ix *Index) expand(word string) []expansion {
+    var expansions []expansion
+    if _, ok := ix.terms[word]; ok {
+        expansions = append(expansions, expansion{term: word, boost: 1})
+    }
+
+    fuzz := fuzziness(word)
+    for term := range ix.terms {
+        if term == word {
+            continue
+        }
+        boost := 0.0
+        if len(word) >= 3 && strings.HasPrefix(term, word) {
+            boost = 0.6
+        }
+        if d, ok := withinDistance(term, word, fuzz); ok {
+            boost = math.Max(boost, 0.8-0.2*float64(d))
+        }
+        if boost > 0 {
+            expansions = append(expansions, expansion{term: term, boost: boost})
+        }
+    }
+
+    sort.Slice(expansions, func(i, j int) bool {
+        if expansions[i].boost != expansions[j].boost {
+            return expansions[i].boost > expansions[j].boost
+        }
+        return expansions[i].term < expansions[j].term
+    })
+    if len(expansions) > maxExpansions {
+        expansions = expansions[:maxExpansions]
+    }
+    return expansions
+}
+
+// matchPhrase scores the documents where the words of p appear next to each
+// other in one field. The caller holds the read lock.
+func (ix *Index) matchPhrase(p phrase, expand func(string) []expansion) map[uint]float64 {
+    total := float64(len(ix.docs))
+
+    // Best score of each word in each document that has it
+    perWord := make([]map[uint]float64, len(p))
+    for i, word := range p {
+        perWord[i] = make(map[uint]float64)
+        for _, e := range expand(word) {
+            docs := ix.terms[e.term]
+            idf := math.Log(1 + total/float64(len(docs)))
+            for id, postings := range docs {
+                var counts [numFields]int
+                for _, posting := range postings {
+                    counts[posting.field]++
+                }
+                score := 0.0
+                for field, count := range counts {
+                    if count > 0 {
+                        score += fieldWeights[field] * (1 + math.Log(float64(count)))
+                    }
+                }
+                perWord[i][id] = math.Max(perWord[i][id], score*idf*e.boost)
+            }
+        }
+    }
+
+    scores := make(map[uint]float64)
+    for id, score := range perWord[0] {
+        for _, other := range perWord[1:] {
+            s, ok := other[id]
+            if !ok {
+                score = -1
+                break
+            }
+            score += s
+        }
+        if score < 0 || len(p) > 1 && !ix.adjacent(id, p, expand) {
+            continue
+        }
+        scores[id] = score
+    }
+    return scores
+}
+
+// adjacent reports whether the words of p follow each other in some field
+// of the document. The caller holds the read lock.
+func (ix *Index) adjacent(id uint, p phrase, expand func(string) []expansion) bool {
+    type slot struct {
+        field uint8
+        pos   uint32
+    }
+    at := make([]map[slot]bool, len(p))
+    for i, word := range p {
+        at[i] = make(map[slot]bool)
+        for _, e := range expand(word) {
+            for _, posting := range ix.terms[e.term][id] {
+                at[i][slot{posting.field, posting.pos}] = true
+            }
+        }
+    }
+
+    for start := range at[0] {
+        found := true
+        for i := 1; i < len(p) && found; i++ {
+            found = at[i][slot{start.field, start.pos + uint32(i)}]
+        }
+        if found {
+            return true
+        }
+    }
+    return false
+}
+
+// mark escapes text and wraps the spans whose word matches in <mark>.
+func mark(text string, spans []span, matches func(string) bool) string {
+    var b strings.Builder
+    last := 0
+    for _, s := range spans {
+        if !matches(s.term) {
+            continue
+        }
+        b.WriteString(html.EscapeString(text[last:s.start]))
+        b.WriteString("<mark>")
+        b.WriteString(html.EscapeString(text[s.start:s.end]))
+        b.WriteString("</mark>")
+        last = s.end
+    }
+    b.WriteString(html.EscapeString(text[last:]))
+    return b.String()
+}
+
+// shift moves spans to offsets relative to a substring starting at offset.
+func shift(spans []span, offset int) []span {
+    moved := make([]span, len(spans))
+    for i, s := range spans {
+        moved[i] = span{term: s.term, start: s.start - offset, end: s.end - offset}
+    }
+    return moved
+}
+
+func topCounts(counts map[string]int, size int) []FacetCount {
+    facets := make([]FacetCount, 0, len(counts))
+    for value, count := range counts {
+        facets = append(facets, FacetCount{Value: value, Count: count})
+    }
+    sort.Slice(facets, func(i, j int) bool {
+        if facets[i].Count != facets[j].Count {
+            return facets[i].Count > facets[j].Count
+        }
+        return facets[i].Value < facets[j].Value
+    })
+    if size > 0 && len(facets) > size {
+        facets = facets[:size]
+    }
+    return facets
+}
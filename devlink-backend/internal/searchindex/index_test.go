@@ -0,0 +1,207 @@
+package searchindex
+
+import (
+    "encoding/gob"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+)
+
+var testDocs = []Document{
+    {ID: 1, Title: "Kubernetes networking guide", Tags: []string{"k8s"}, Description: "Services, ingress and DNS"},
+    {ID: 2, Title: "Go concurrency patterns", Tags: []string{"go"}, Description: "Goroutines and channels in practice",
+        Content: "The context package cancels work that is no longer needed."},
+    {ID: 3, Title: "Rust ownership", Category: "tutorial", Description: "The borrow checker explained"},
+    {ID: 4, Title: "Context switching costs", Description: "Why we cancel meetings"},
+}
+
+func newTestIndex() *Index {
+    ix := New()
+    for _, doc := range testDocs {
+        ix.Put(doc)
+    }
+    return ix
+}
+
+func hitIDs(hits []Hit) []uint {
+    ids := make([]uint, len(hits))
+    for i, hit := range hits {
+        ids[i] = hit.ID
+    }
+    return ids
+}
+
+func TestSearch(t *testing.T) {
+    ix := newTestIndex()
+
+    tests := []struct {
+        name    string
+        query   string
+        want    []uint
+        ordered bool // whether the order of want matters
+    }{
+        {"exact word", "kubernetes", []uint{1}, false},
+        {"case is ignored", "KUBERNETES", []uint{1}, false},
+        {"all words are required", "go channels", []uint{2}, false},
+        {"one typo", "concurency", []uint{2}, false},
+        {"two typos in a long word", "kubernets", []uint{1}, false},
+        {"short words need to match exactly", "ruts", nil, false},
+        {"prefix", "gorout", []uint{2}, false},
+        {"prefixes need three letters", "ne", nil, false},
+        {"phrase", `"context package"`, []uint{2}, false},
+        {"phrase words must be adjacent", `"context cancel"`, nil, false},
+        {"phrase words must share a field", `"costs why"`, nil, false},
+        {"words anywhere", "context cancel", []uint{4, 2}, true},
+        {"title matches rank first", "context", []uint{4, 2}, true},
+        {"exclusion", "context -meetings", []uint{2}, false},
+        {"excluded phrase", `context -"context package"`, []uint{4}, false},
+        {"exclusion matches exactly", "context -meeting", []uint{2, 4}, false},
+        {"or", "rust OR kubernetes", []uint{1, 3}, false},
+        {"words must be in the same document", "tutorial k8s", nil, false},
+        {"tag", "k8s", []uint{1}, false},
+        {"category", "tutorial", []uint{3}, false},
+        {"only exclusions match nothing", "-rust", nil, false},
+        {"empty query", "  ", nil, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := hitIDs(ix.Search(tt.query, 10))
+            if !tt.ordered {
+                sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+            }
+            if len(got) == 0 && len(tt.want) == 0 {
+                return
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestSearchLimitAndUpdates(t *testing.T) {
+    ix := newTestIndex()
+
+    if hits := ix.Search("context", 1); len(hits) != 1 || hits[0].ID != 4 {
+        t.Errorf("Search with limit 1 = %v, want only the best hit", hitIDs(hits))
+    }
+
+    ix.Put(Document{ID: 4, Title: "Meeting notes"})
+    if hits := ix.Search("switching", 10); len(hits) != 0 {
+        t.Errorf("replaced document still matches its old title: %v", hitIDs(hits))
+    }
+    ix.Delete(2)
+    if hits := ix.Search("goroutines", 10); len(hits) != 0 {
+        t.Errorf("deleted document still matches: %v", hitIDs(hits))
+    }
+    if ix.Len() != 3 {
+        t.Errorf("Len = %d, want 3", ix.Len())
+    }
+}
+
+func TestHighlight(t *testing.T) {
+    ix := newTestIndex()
+    long := strings.Repeat("filler ", 30) + "the needle is here " + strings.Repeat("more ", 30)
+    ix.Put(Document{ID: 5, Title: "<b>Tips</b> & tricks", Content: long})
+
+    tests := []struct {
+        name    string
+        id      uint
+        query   string
+        title   string
+        snippet string
+    }{
+        {"title match, no snippet", 1, "kubernetes", "<mark>Kubernetes</mark> networking guide", ""},
+        {"description snippet", 2, "goroutine", "Go concurrency patterns", "<mark>Goroutines</mark> and channels in practice"},
+        {"typos are marked too", 3, "ownrship borow", "Rust <mark>ownership</mark>", "The <mark>borrow</mark> checker explained"},
+        {"escaped and trimmed", 5, "tips needle", "&lt;b&gt;<mark>Tips</mark>&lt;/b&gt; &amp; tricks",
+            "… " + strings.Repeat("filler ", 9) + "the <mark>needle</mark> is here " + strings.Repeat("more ", 16) + "more …"},
+        {"unknown document", 9, "anything", "", ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            title, snippet := ix.Highlight(tt.id, tt.query)
+            if title != tt.title {
+                t.Errorf("title = %q, want %q", title, tt.title)
+            }
+            if snippet != tt.snippet {
+                t.Errorf("snippet = %q, want %q", snippet, tt.snippet)
+            }
+        })
+    }
+}
+
+func TestOpenFlushRoundTrip(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "index", "search.gob")
+
+    ix, err := Open(path)
+    if err != nil {
+        t.Fatalf("Open: %v", err)
+    }
+    if ix.Len() != 0 {
+        t.Fatalf("new index has %d documents", ix.Len())
+    }
+    for _, doc := range testDocs {
+        ix.Put(doc)
+    }
+    if err := ix.Flush(); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+
+    reopened, err := Open(path)
+    if err != nil {
+        t.Fatalf("Open after Flush: %v", err)
+    }
+    if reopened.Len() != len(testDocs) {
+        t.Errorf("reopened index has %d documents, want %d", reopened.Len(), len(testDocs))
+    }
+    for _, query := range []string{"kubernets", `"context package"`, "context -meetings", "tutorial"} {
+        if got, want := hitIDs(reopened.Search(query, 10)), hitIDs(ix.Search(query, 10)); !reflect.DeepEqual(got, want) {
+            t.Errorf("Search(%q) after reopening = %v, want %v", query, got, want)
+        }
+    }
+
+    // Unchanged indexes are not written again
+    if err := os.Remove(path); err != nil {
+        t.Fatal(err)
+    }
+    if err := reopened.Flush(); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+    if _, err := os.Stat(path); !os.IsNotExist(err) {
+        t.Error("Flush rewrote an unchanged index")
+    }
+}
+
+func TestOpenIgnoresOtherVersions(t *testing.T) {
+    dir := t.TempDir()
+
+    outdated := filepath.Join(dir, "outdated.gob")
+    file, err := os.Create(outdated)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := gob.NewEncoder(file).Encode(savedIndex{Version: formatVersion + 1, Docs: testDocs}); err != nil {
+        t.Fatal(err)
+    }
+    file.Close()
+
+    garbage := filepath.Join(dir, "garbage.gob")
+    if err := os.WriteFile(garbage, []byte("not a gob"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    for _, path := range []string{outdated, garbage} {
+        ix, err := Open(path)
+        if err != nil {
+            t.Errorf("Open(%s): %v", filepath.Base(path), err)
+            continue
+        }
+        if ix.Len() != 0 {
+            t.Errorf("Open(%s) loaded %d documents, want an empty index to rebuild", filepath.Base(path), ix.Len())
+        }
+    }
+}
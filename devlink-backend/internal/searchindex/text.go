@@ -0,0 +1,173 @@
+package searchindex
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// span is a word of a text and where it sits in the original string.
+type span struct {
+    term       string // lowercased
+    start, end int    // byte offsets
+}
+
+// tokenize splits text into lowercased words of letters and digits.
+func tokenize(text string) []span {
+    var spans []span
+    start := -1
+    for i, r := range text {
+        word := unicode.IsLetter(r) || unicode.IsDigit(r)
+        switch {
+        case word && start < 0:
+            start = i
+        case !word && start >= 0:
+            spans = append(spans, span{term: strings.ToLower(text[start:i]), start: start, end: i})
+            start = -1
+        }
+    }
+    if start >= 0 {
+        spans = append(spans, span{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+    }
+    return spans
+}
+
+// phrase is one or more words that have to appear next to each other.
+type phrase []string
+
+// clause is satisfied when any of its phrases matches.
+type clause []phrase
+
+// parsedQuery is a web-search style query: words and "quoted phrases" are
+// all required, OR between them offers alternatives, and a leading minus
+// excludes a word or phrase.
+type parsedQuery struct {
+    clauses  []clause
+    excluded []phrase
+}
+
+func parseQuery(text string) parsedQuery {
+    var q parsedQuery
+    orNext := false
+
+    for _, raw := range splitQuoted(text) {
+        negate := strings.HasPrefix(raw, "-") && len(raw) > 1
+        if negate {
+            raw = raw[1:]
+        }
+        if raw == "OR" && !negate {
+            orNext = len(q.clauses) > 0
+            continue
+        }
+
+        var words phrase
+        for _, s := range tokenize(strings.Trim(raw, `"`)) {
+            words = append(words, s.term)
+        }
+        if len(words) == 0 {
+            continue
+        }
+
+        switch {
+        case negate:
+            q.excluded = append(q.excluded, words)
+        case orNext:
+            last := len(q.clauses) - 1
+            q.clauses[last] = append(q.clauses[last], words)
+        default:
+            q.clauses = append(q.clauses, clause{words})
+        }
+        orNext = false
+    }
+    return q
+}
+
+// words lists the distinct required and optional words of the query.
+func (q parsedQuery) words() []string {
+    seen := make(map[string]bool)
+    var words []string
+    for _, c := range q.clauses {
+        for _, p := range c {
+            for _, w := range p {
+                if !seen[w] {
+                    seen[w] = true
+                    words = append(words, w)
+                }
+            }
+        }
+    }
+    return words
+}
+
+// splitQuoted splits at whitespace outside double quotes, keeping the quotes.
+func splitQuoted(text string) []string {
+    var parts []string
+    var current strings.Builder
+    quoted := false
+    for _, r := range text {
+        switch {
+        case r == '"':
+            quoted = !quoted
+            current.WriteRune(r)
+        case unicode.IsSpace(r) && !quoted:
+            if current.Len() > 0 {
+                parts = append(parts, current.String())
+                current.Reset()
+            }
+        default:
+            current.WriteRune(r)
+        }
+    }
+    if current.Len() > 0 {
+        parts = append(parts, current.String())
+    }
+    return parts
+}
+
+// fuzziness is how many edits a word of this length may be off by: none
+// for short words, where one edit is a different word, then one, then two.
+func fuzziness(word string) int {
+    switch n := utf8.RuneCountInString(word); {
+    case n < 4:
+        return 0
+    case n < 8:
+        return 1
+    default:
+        return 2
+    }
+}
+
+// withinDistance reports whether the Levenshtein distance between a and b
+// is at most limit, and what it is.
+func withinDistance(a, b string, limit int) (int, bool) {
+    ra, rb := []rune(a), []rune(b)
+    if d := len(ra) - len(rb); d > limit || -d > limit {
+        return 0, false
+    }
+
+    prev := make([]int, len(rb)+1)
+    curr := make([]int, len(rb)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(ra); i++ {
+        curr[0] = i
+        best := curr[0]
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+            if curr[j] < best {
+                best = curr[j]
+            }
+        }
+        // Every later row is at least as far off as the best of this one
+        if best > limit {
+            return 0, false
+        }
+        prev, curr = curr, prev
+    }
+    return prev[len(rb)], prev[len(rb)] <= limit
+}
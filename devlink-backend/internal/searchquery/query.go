@@ -0,0 +1,350 @@
+// Package searchquery parses the search box syntax for resources:
+//
+//    tag:go category:tool is:public clicks:>10 created:>2025-01-01 "context cancel"
+//
+// Qualifiers narrow the results, everything else is full-text search. A
+// leading minus negates a word, phrase or qualifier, and commas inside a
+// qualifier mean "any of": tag:go,rust.
+package searchquery
+
+import (
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+)
+
+// Qualifiers
+const (
+    FieldTag      = "tag"
+    FieldCategory = "category"
+    FieldIs       = "is"
+    FieldClicks   = "clicks"
+    FieldCreated  = "created"
+    FieldUpdated  = "updated"
+    FieldSite     = "site"
+)
+
+// Values accepted by is:
+const (
+    IsPublic     = "public"
+    IsPrivate    = "private"
+    IsArchived   = "archived"
+    IsOK         = "ok"
+    IsRedirected = "redirected"
+    IsBroken     = "broken"
+    IsUnchecked  = "unchecked"
+)
+
+const (
+    MaxTerms  = 32 // words, phrases and qualifiers in one query
+    MaxValues = 20 // comma-separated values in one qualifier
+
+    dateLayout = "2006-01-02"
+)
+
+var fields = []string{FieldTag, FieldCategory, FieldIs, FieldClicks, FieldCreated, FieldUpdated, FieldSite}
+
+var isValues = []string{IsPublic, IsPrivate, IsArchived, IsOK, IsRedirected, IsBroken, IsUnchecked}
+
+// Query is a parsed search.
+type Query struct {
+    Text    string // words and phrases in websearch_to_tsquery syntax, empty when there are none
+    Filters []Filter
+}
+
+// Filter is one qualifier. Which of the value fields is set depends on Field.
+type Filter struct {
+    Field  string
+    Negate bool
+    Token  Token
+
+    Values []string  // tag, category, is and site: matches any of them
+    Min    *int64    // clicks: inclusive bounds, nil when open
+    Max    *int64
+    From   time.Time // created and updated: from From up to but excluding Until,
+    Until  time.Time // zero when open
+}
+
+// Token is a piece of the query as the user typed it.
+type Token struct {
+    Text string
+    Pos  int // rune offset in the query
+}
+
+// Error points at the part of a query that couldn't be understood.
+type Error struct {
+    Token   Token
+    Message string
+}
+
+func (e *Error) Error() string {
+    return fmt.Sprintf("%s (at %q, position %d)", e.Message, e.Token.Text, e.Token.Pos+1)
+}
+
+// Parse reads a query. An empty query matches everything.
+func Parse(input string) (*Query, error) {
+    tokens, err := split(input)
+    if err != nil {
+        return nil, err
+    }
+    if len(tokens) > MaxTerms {
+        return nil, &Error{Token: tokens[MaxTerms], Message: fmt.Sprintf("a query can have at most %d terms", MaxTerms)}
+    }
+
+    query := &Query{}
+    var text []string
+    for _, token := range tokens {
+        raw := token.Text
+        negate := strings.HasPrefix(raw, "-") && len(raw) > 1
+        if negate {
+            raw = raw[1:]
+        }
+
+        key, value, qualified := cutQualifier(raw)
+        if !qualified {
+            if negate {
+                raw = "-" + raw
+            }
+            text = append(text, raw)
+            continue
+        }
+
+        filter, err := parseFilter(token, key, value)
+        if err != nil {
+            return nil, err
+        }
+        filter.Negate = negate
+        query.Filters = append(query.Filters, *filter)
+    }
+
+    query.Text = strings.Join(text, " ")
+    return query, nil
+}
+
+// split breaks the input at whitespace outside double quotes.
+func split(input string) ([]Token, error) {
+    var tokens []Token
+    var current strings.Builder
+    start, quoteAt := -1, -1
+
+    runes := []rune(input)
+    for i, r := range runes {
+        switch {
+        case r == '"':
+            if start < 0 {
+                start = i
+            }
+            if quoteAt < 0 {
+                quoteAt = i
+            } else {
+                quoteAt = -1
+            }
+            current.WriteRune(r)
+        case unicode.IsSpace(r) && quoteAt < 0:
+            if start >= 0 {
+                tokens = append(tokens, Token{Text: current.String(), Pos: start})
+                current.Reset()
+                start = -1
+            }
+        default:
+            if start < 0 {
+                start = i
+            }
+            current.WriteRune(r)
+        }
+    }
+
+    if quoteAt >= 0 {
+        return nil, &Error{Token: Token{Text: string(runes[quoteAt:]), Pos: quoteAt}, Message: "missing closing quote"}
+    }
+    if start >= 0 {
+        tokens = append(tokens, Token{Text: current.String(), Pos: start})
+    }
+    return tokens, nil
+}
+
+// cutQualifier splits key:value. Quoted text, URLs, words ending in a colon
+// and words whose part before the colon isn't a plain word are searched for
+// as they are.
+func cutQualifier(raw string) (key, value string, ok bool) {
+    key, value, ok = strings.Cut(raw, ":")
+    if !ok || key == "" || value == "" || strings.HasPrefix(value, "//") {
+        return "", "", false
+    }
+    for _, r := range key {
+        if !unicode.IsLetter(r) {
+            return "", "", false
+        }
+    }
+    return strings.ToLower(key), value, true
+}
+
+func parseFilter(token Token, key, value string) (*Filter, error) {
+    filter := &Filter{Field: key, Token: token}
+    if !contains(fields, key) {
+        return nil, &Error{Token: token, Message: fmt.Sprintf("unknown qualifier %q, use %s, or put the word in quotes", key, strings.Join(fields, ", "))}
+    }
+    value = strings.ReplaceAll(value, `"`, "")
+    if strings.TrimSpace(value) == "" {
+        return nil, &Error{Token: token, Message: key + ": needs a value"}
+    }
+
+    switch key {
+    case FieldTag, FieldCategory, FieldIs, FieldSite:
+        values, err := parseList(token, key, value)
+        if err != nil {
+            return nil, err
+        }
+        filter.Values = values
+
+    case FieldClicks:
+        lo, hi, err := parseRange(value, func(s string) (int64, error) {
+            return strconv.ParseInt(s, 10, 64)
+        })
+        if err != nil {
+            return nil, &Error{Token: token, Message: "clicks: expects a number such as 10, >10, <=5 or 5..20"}
+        }
+        // The bounds are inclusive, so strict comparisons move them by one,
+        // which must not wrap around at the ends of int64
+        if (lo != nil && lo.exclusive && lo.value == math.MaxInt64) || (hi != nil && hi.exclusive && hi.value == math.MinInt64) {
+            return nil, &Error{Token: token, Message: "clicks: the number is out of range"}
+        }
+        if lo != nil && lo.exclusive {
+            lo.value++
+        }
+        if hi != nil && hi.exclusive {
+            hi.value--
+        }
+        if lo != nil {
+            filter.Min = &lo.value
+        }
+        if hi != nil {
+            filter.Max = &hi.value
+        }
+
+    case FieldCreated, FieldUpdated:
+        lo, hi, err := parseRange(value, func(s string) (time.Time, error) {
+            return time.Parse(dateLayout, s)
+        })
+        if err != nil {
+            return nil, &Error{Token: token, Message: key + ": expects a date such as 2025-01-31, >2025-01-31 or 2025-01-01..2025-01-31"}
+        }
+        // Whole days: "after" starts the next day, "up to" ends the day after
+        if lo != nil {
+            filter.From = lo.value
+            if lo.exclusive {
+                filter.From = filter.From.AddDate(0, 0, 1)
+            }
+        }
+        if hi != nil {
+            filter.Until = hi.value
+            if !hi.exclusive {
+                filter.Until = filter.Until.AddDate(0, 0, 1)
+            }
+        }
+
+    }
+
+    return filter, nil
+}
+
+func parseList(token Token, key, value string) ([]string, error) {
+    var values []string
+    for _, item := range strings.Split(value, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        switch key {
+        case FieldIs:
+            item = strings.ToLower(item)
+            if !contains(isValues, item) {
+                return nil, &Error{Token: token, Message: fmt.Sprintf("is: expects one of %s", strings.Join(isValues, ", "))}
+            }
+        case FieldSite:
+            item = strings.TrimPrefix(strings.ToLower(item), "www.")
+            if !isHostname(item) {
+                return nil, &Error{Token: token, Message: "site: expects a domain such as github.com"}
+            }
+        }
+        values = append(values, item)
+    }
+
+    if len(values) == 0 {
+        return nil, &Error{Token: token, Message: key + ": needs a value"}
+    }
+    if len(values) > MaxValues {
+        return nil, &Error{Token: token, Message: fmt.Sprintf("%s: takes at most %d values", key, MaxValues)}
+    }
+    return values, nil
+}
+
+type bound[T any] struct {
+    value     T
+    exclusive bool
+}
+
+// parseRange reads N, >N, >=N, <N, <=N and N..M, where either side of a
+// range may be * for open. A nil bound is open.
+func parseRange[T any](value string, parse func(string) (T, error)) (lo, hi *bound[T], err error) {
+    one := func(s string, exclusive bool) (*bound[T], error) {
+        if s == "*" {
+            return nil, nil
+        }
+        v, err := parse(s)
+        if err != nil {
+            return nil, err
+        }
+        return &bound[T]{value: v, exclusive: exclusive}, nil
+    }
+
+    switch {
+    case strings.HasPrefix(value, ">="):
+        lo, err = one(value[2:], false)
+    case strings.HasPrefix(value, ">"):
+        lo, err = one(value[1:], true)
+    case strings.HasPrefix(value, "<="):
+        hi, err = one(value[2:], false)
+    case strings.HasPrefix(value, "<"):
+        hi, err = one(value[1:], true)
+    case strings.Contains(value, ".."):
+        from, to, _ := strings.Cut(value, "..")
+        if lo, err = one(from, false); err == nil {
+            hi, err = one(to, false)
+        }
+    default:
+        if lo, err = one(value, false); err == nil && lo == nil {
+            err = fmt.Errorf("* on its own is not a range")
+        }
+        hi = lo
+    }
+
+    if err == nil && lo == nil && hi == nil {
+        err = fmt.Errorf("open range")
+    }
+    return lo, hi, err
+}
+
+func isHostname(host string) bool {
+    if host == "" || len(host) > 253 || strings.HasPrefix(host, ".") || strings.HasSuffix(host, ".") {
+        return false
+    }
+    for _, r := range host {
+        if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '-') {
+            return false
+        }
+    }
+    return true
+}
+
+func contains(list []string, value string) bool {
+    for _, item := range list {
+        if item == value {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,215 @@
+package searchquery
+
+import (
+    "errors"
+    "math"
+    "reflect"
+    "strconv"
+    "testing"
+    "time"
+)
+
+func TestSplit(t *testing.T) {
+    tests := []struct {
+        input string
+        want  []Token
+    }{
+        {"", nil},
+        {"   ", nil},
+        {"go  rust", []Token{{"go", 0}, {"rust", 4}}},
+        {`"context cancel" go`, []Token{{`"context cancel"`, 0}, {"go", 17}}},
+        {`-"foo bar"`, []Token{{`-"foo bar"`, 0}}},
+        {`tag:"web dev" x`, []Token{{`tag:"web dev"`, 0}, {"x", 14}}},
+        {"\tgo\nrust ", []Token{{"go", 1}, {"rust", 4}}},
+        {"héllo wörld", []Token{{"héllo", 0}, {"wörld", 6}}}, // positions count runes, not bytes
+    }
+    for _, tt := range tests {
+        got, err := split(tt.input)
+        if err != nil {
+            t.Errorf("split(%q): %v", tt.input, err)
+            continue
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("split(%q) = %v, want %v", tt.input, got, tt.want)
+        }
+    }
+}
+
+func TestSplitUnclosedQuote(t *testing.T) {
+    _, err := split(`go "context cancel`)
+    var qerr *Error
+    if !errors.As(err, &qerr) {
+        t.Fatalf("err = %v, want an *Error", err)
+    }
+    if qerr.Token.Pos != 3 || qerr.Token.Text != `"context cancel` {
+        t.Errorf("error points at %+v, want the opening quote at 3", qerr.Token)
+    }
+}
+
+func TestCutQualifier(t *testing.T) {
+    tests := []struct {
+        raw       string
+        key       string
+        value     string
+        qualified bool
+    }{
+        {"tag:go", "tag", "go", true},
+        {"TAG:Go", "tag", "Go", true},
+        {"unknown:value", "unknown", "value", true}, // rejected later, by parseFilter
+        {"https://go.dev/doc", "", "", false},
+        {"http://example.com", "", "", false},
+        {"note:", "", "", false},
+        {":value", "", "", false},
+        {"word", "", "", false},
+        {"c++:value", "", "", false},
+        {"v2:beta", "", "", false},
+        {"site:a:b", "site", "a:b", true},
+    }
+    for _, tt := range tests {
+        key, value, ok := cutQualifier(tt.raw)
+        if key != tt.key || value != tt.value || ok != tt.qualified {
+            t.Errorf("cutQualifier(%q) = %q, %q, %v, want %q, %q, %v", tt.raw, key, value, ok, tt.key, tt.value, tt.qualified)
+        }
+    }
+}
+
+func TestParseClicks(t *testing.T) {
+    maxInt, minInt := int64(math.MaxInt64), int64(math.MinInt64)
+    tests := []struct {
+        value string
+        min   *int64
+        max   *int64
+    }{
+        {"10", ptr(10), ptr(10)},
+        {">10", ptr(11), nil},
+        {">=10", ptr(10), nil},
+        {"<10", nil, ptr(9)},
+        {"<=10", nil, ptr(10)},
+        {"5..20", ptr(5), ptr(20)},
+        {"5..*", ptr(5), nil},
+        {"*..20", nil, ptr(20)},
+        {">=" + strconv.FormatInt(maxInt, 10), &maxInt, nil},
+        {"<=" + strconv.FormatInt(minInt, 10), nil, &minInt},
+        {">" + strconv.FormatInt(maxInt-1, 10), &maxInt, nil},
+    }
+    for _, tt := range tests {
+        query, err := Parse("clicks:" + tt.value)
+        if err != nil {
+            t.Errorf("clicks:%s: %v", tt.value, err)
+            continue
+        }
+        filter := query.Filters[0]
+        if !reflect.DeepEqual(filter.Min, tt.min) || !reflect.DeepEqual(filter.Max, tt.max) {
+            t.Errorf("clicks:%s = %v..%v, want %v..%v", tt.value, deref(filter.Min), deref(filter.Max), deref(tt.min), deref(tt.max))
+        }
+    }
+}
+
+func TestParseDates(t *testing.T) {
+    day := func(s string) time.Time {
+        d, _ := time.Parse(dateLayout, s)
+        return d
+    }
+    tests := []struct {
+        value string
+        from  time.Time
+        until time.Time
+    }{
+        {"2025-01-31", day("2025-01-31"), day("2025-02-01")},
+        {">2025-01-31", day("2025-02-01"), time.Time{}},
+        {">=2025-01-31", day("2025-01-31"), time.Time{}},
+        {"<2025-01-31", time.Time{}, day("2025-01-31")},
+        {"<=2025-01-31", time.Time{}, day("2025-02-01")},
+        {"2025-01-01..2025-01-31", day("2025-01-01"), day("2025-02-01")},
+    }
+    for _, tt := range tests {
+        query, err := Parse("created:" + tt.value)
+        if err != nil {
+            t.Errorf("created:%s: %v", tt.value, err)
+            continue
+        }
+        filter := query.Filters[0]
+        if !filter.From.Equal(tt.from) || !filter.Until.Equal(tt.until) {
+            t.Errorf("created:%s = %v..%v, want %v..%v", tt.value, filter.From, filter.Until, tt.from, tt.until)
+        }
+    }
+}
+
+func TestParse(t *testing.T) {
+    query, err := Parse(`-tag:java,Kotlin "context cancel" -deprecated https://go.dev is:PUBLIC`)
+    if err != nil {
+        t.Fatalf("Parse: %v", err)
+    }
+    if want := `"context cancel" -deprecated https://go.dev`; query.Text != want {
+        t.Errorf("text = %q, want %q", query.Text, want)
+    }
+    if len(query.Filters) != 2 {
+        t.Fatalf("got %d filters, want 2", len(query.Filters))
+    }
+    tag, is := query.Filters[0], query.Filters[1]
+    if tag.Field != FieldTag || !tag.Negate || !reflect.DeepEqual(tag.Values, []string{"java", "Kotlin"}) || tag.Token.Pos != 0 {
+        t.Errorf("tag filter = %+v", tag)
+    }
+    if is.Field != FieldIs || is.Negate || !reflect.DeepEqual(is.Values, []string{IsPublic}) || is.Token.Pos != 61 {
+        t.Errorf("is filter = %+v", is)
+    }
+}
+
+func TestParseErrors(t *testing.T) {
+    tests := []struct {
+        input string
+        pos   int // rune offset of the token the error points at
+    }{
+        {"go color:red", 3},
+        {"clicks:lots", 0},
+        {"clicks:*", 0},
+        {"clicks:*..*", 0},
+        {"clicks:>" + strconv.FormatInt(math.MaxInt64, 10), 0},
+        {"clicks:<" + strconv.FormatInt(math.MinInt64, 10), 0},
+        {"clicks:99999999999999999999", 0},
+        {"created:yesterday", 0},
+        {"é is:shiny", 2},
+        {"site:not_a_host", 0},
+        {`tag:""`, 0},
+        {"tag:,", 0},
+    }
+    for _, tt := range tests {
+        _, err := Parse(tt.input)
+        var qerr *Error
+        if !errors.As(err, &qerr) {
+            t.Errorf("Parse(%q): err = %v, want an *Error", tt.input, err)
+            continue
+        }
+        if qerr.Token.Pos != tt.pos {
+            t.Errorf("Parse(%q): error at %d, want %d", tt.input, qerr.Token.Pos, tt.pos)
+        }
+    }
+}
+
+func TestParseLimits(t *testing.T) {
+    many := ""
+    for i := 0; i <= MaxTerms; i++ {
+        many += "w "
+    }
+    var qerr *Error
+    if _, err := Parse(many); !errors.As(err, &qerr) || qerr.Token.Pos != 2*MaxTerms {
+        t.Errorf("Parse(%d terms): err = %v, want an *Error at the first extra term", MaxTerms+1, err)
+    }
+
+    values := "tag:a"
+    for i := 0; i < MaxValues; i++ {
+        values += ",a"
+    }
+    if _, err := Parse(values); !errors.As(err, &qerr) {
+        t.Errorf("Parse(%d values): err = %v, want an *Error", MaxValues+1, err)
+    }
+}
+
+func ptr(v int64) *int64 { return &v }
+
+func deref(v *int64) string {
+    if v == nil {
+        return "*"
+    }
+    return strconv.FormatInt(*v, 10)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+// PATPrefix marks personal access tokens so middleware can tell them from JWTs.
+const PATPrefix = "dlp_"
+
+const (
+    ScopeResourcesRead  = "resources:read"
+    ScopeResourcesWrite = "resources:write"
+)
+
+var validScopes = []string{
+    ScopeResourcesRead,
+    ScopeResourcesWrite,
+}
+
+var ErrInvalidAccessToken = errors.New("invalid or expired access token")
+
+type AccessTokenService struct {
+    db          *gorm.DB
+    authService *AuthService
+}
+
+type CreateAccessTokenRequest struct {
+    Name          string   `json:"name" binding:"required,max=100"`
+    Scopes        []string `json:"scopes" binding:"required,min=1"`
+    ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // 0 means no expiry
+}
+
+func NewAccessTokenService(db *gorm.DB, authService *AuthService) *AccessTokenService {
+    return &AccessTokenService{
+        db:          db,
+        authService: authService,
+    }
+}
+
+// CreateToken issues a new token and returns the raw value alongside the
+// stored record. The raw value cannot be recovered afterwards.
+func (s *AccessTokenService) CreateToken(userID uint, req CreateAccessTokenRequest) (*models.PersonalAccessToken, string, error) {
+    for _, scope := range req.Scopes {
+        if !isValidScope(scope) {
+            return nil, "", fmt.Errorf("unknown scope %q (valid scopes: %s)", scope, strings.Join(validScopes, ", "))
+        }
+    }
+
+    secret, _, err := generateOpaqueToken()
+    if err != nil {
+        return nil, "", err
+    }
+    raw := PATPrefix + secret
+
+    token := models.PersonalAccessToken{
+        UserID:    userID,
+        Name:      req.Name,
+        TokenHash: hashToken(raw),
+        Prefix:    raw[:len(PATPrefix)+6],
+        Scopes:    strings.Join(req.Scopes, ","),
+    }
+    if req.ExpiresInDays > 0 {
+        expiresAt := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+        token.ExpiresAt = &expiresAt
+    }
+
+    if err := s.db.Create(&token).Error; err != nil {
+        return nil, "", err
+    }
+
+    return &token, raw, nil
+}
+
+func (s *AccessTokenService) GetUserTokens(userID uint) ([]models.PersonalAccessToken, error) {
+    var tokens []models.PersonalAccessToken
+    if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+        return nil, err
+    }
+    return tokens, nil
+}
+
+func (s *AccessTokenService) RevokeToken(tokenID, userID uint) error {
+    result := s.db.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&models.PersonalAccessToken{})
+
+    if result.Error != nil {
+        return result.Error
+    }
+
+    if result.RowsAffected == 0 {
+        return errors.New("token not found")
+    }
+
+    return nil
+}
+
+// Authenticate resolves a raw token to its record and records the use.
+func (s *AccessTokenService) Authenticate(raw string) (*models.PersonalAccessToken, error) {
+    var token models.PersonalAccessToken
+    if err := s.db.Where("token_hash = ?", hashToken(raw)).First(&token).Error; err != nil {
+        return nil, ErrInvalidAccessToken
+    }
+
+    if token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt) {
+        return nil, ErrInvalidAccessToken
+    }
+
+    // Tokens stop working as soon as their owner is disabled, or while the
+    // registration policy wants an address the owner hasn't confirmed
+    var user models.User
+    if err := s.db.Select("id", "disabled_at", "email_verified_at").First(&user, token.UserID).Error; err != nil ||
+        user.DisabledAt != nil || s.authService.requiresVerifiedEmail(&user) {
+        return nil, ErrInvalidAccessToken
+    }
+
+    // Same throttling as session last-seen tracking
+    if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > sessionTouchInterval {
+        s.db.Model(&token).Update("last_used_at", time.Now())
+    }
+
+    return &token, nil
+}
+
+// TokenScopes splits a stored scope list.
+func TokenScopes(token *models.PersonalAccessToken) []string {
+    if token.Scopes == "" {
+        return nil
+    }
+    return strings.Split(token.Scopes, ",")
+}
+
+// HasScope reports whether granted covers required. A write scope also
+// grants the matching read scope.
+func HasScope(granted []string, required string) bool {
+    for _, scope := range granted {
+        if scope == required {
+            return true
+        }
+        if strings.HasSuffix(required, ":read") && scope == strings.TrimSuffix(required, ":read")+":write" {
+            return true
+        }
+    }
+    return false
+}
+
+func isValidScope(scope string) bool {
+    for _, valid := range validScopes {
+        if scope == valid {
+            return true
+        }
+    }
+    return false
+}
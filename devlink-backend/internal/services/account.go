@@ -0,0 +1,522 @@
+package services
+
+import (
+    "archive/zip"
+    "bytes"
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/mailer"
+    "devlink-backend/internal/models"
+    "golang.org/x/crypto/bcrypt"
+    "gorm.io/gorm"
+)
+
+// Public resources of deleted accounts are handed to a placeholder user
+// when they are anonymized rather than deleted. Addresses on its domain
+// are reserved, so nobody can register or switch to one.
+const (
+    reservedEmailDomain = "devlink.invalid"
+    deletedUserEmail    = "deleted-user@" + reservedEmailDomain
+)
+
+var (
+    ErrDeletionAlreadyScheduled = errors.New("account deletion is already scheduled")
+    ErrDeletionNotScheduled     = errors.New("account deletion is not scheduled")
+    ErrReservedEmail            = errors.New("this email address is reserved")
+)
+
+type AccountDeletionPolicy struct {
+    GracePeriod           time.Duration // how long a deleted account can still be restored
+    DeletePublicResources bool          // delete public resources instead of anonymizing them
+}
+
+func DefaultAccountDeletionPolicy() AccountDeletionPolicy {
+    return AccountDeletionPolicy{
+        GracePeriod:           30 * 24 * time.Hour,
+        DeletePublicResources: false,
+    }
+}
+
+type AccountService struct {
+    db          *gorm.DB
+    authService *AuthService
+    index       SearchIndex
+    policy      AccountDeletionPolicy
+}
+
+// AccountExport is the JSON document inside a data export archive.
+type AccountExport struct {
+    ExportedAt  time.Time              `json:"exported_at"`
+    Profile     models.User            `json:"profile"`
+    Identities  []models.UserIdentity  `json:"identities"`
+    Resources   []exportResource       `json:"resources"`
+    Collections []exportCollection     `json:"collections"`
+    Clicks      []models.ResourceClick `json:"clicks"`
+    Sessions    []models.Session       `json:"sessions"`
+}
+
+type exportCollection struct {
+    models.Collection
+    ResourceIDs []uint `json:"resource_ids"`
+}
+
+type exportResource struct {
+    ID          uint       `json:"id"`
+    Title       string     `json:"title"`
+    URL         string     `json:"url"`
+    Description string     `json:"description"`
+    Category    string     `json:"category"`
+    Tags        []string   `json:"tags"`
+    IsPublic    bool       `json:"is_public"`
+    ClickCount  int        `json:"click_count"`
+    HiddenAt    *time.Time `json:"hidden_at"`
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func NewAccountService(db *gorm.DB, authService *AuthService, index SearchIndex, policy AccountDeletionPolicy) *AccountService {
+    return &AccountService{
+        db:          db,
+        authService: authService,
+        index:       index,
+        policy:      policy,
+    }
+}
+
+// Export builds a zip archive with everything stored about the user: a full
+// JSON document plus CSV copies of the tabular parts.
+func (s *AccountService) Export(userID uint) ([]byte, error) {
+    export := AccountExport{ExportedAt: time.Now().UTC()}
+
+    if err := s.db.First(&export.Profile, userID).Error; err != nil {
+        return nil, errors.New("user not found")
+    }
+
+    if err := s.db.Where("user_id = ?", userID).Find(&export.Identities).Error; err != nil {
+        return nil, err
+    }
+
+    var resources []models.Resource
+    if err := s.db.Where("user_id = ?", userID).Order("created_at").Preload("Tags").Find(&resources).Error; err != nil {
+        return nil, err
+    }
+    export.Resources = make([]exportResource, 0, len(resources))
+    for _, resource := range resources {
+        export.Resources = append(export.Resources, exportResource{
+            ID:          resource.ID,
+            Title:       resource.Title,
+            URL:         resource.URL,
+            Description: resource.Description,
+            Category:    resource.Category,
+            Tags:        TagNames(resource.Tags),
+            IsPublic:    resource.IsPublic,
+            ClickCount:  resource.ClickCount,
+            HiddenAt:    resource.HiddenAt,
+            CreatedAt:   resource.CreatedAt,
+            UpdatedAt:   resource.UpdatedAt,
+        })
+    }
+
+    var collections []models.Collection
+    if err := s.db.Where("user_id = ?", userID).Order("position, name").Find(&collections).Error; err != nil {
+        return nil, err
+    }
+    export.Collections = make([]exportCollection, 0, len(collections))
+    for _, collection := range collections {
+        item := exportCollection{Collection: collection, ResourceIDs: []uint{}}
+        if err := s.db.Table("collection_resources").
+            Where("collection_id = ?", collection.ID).
+            Pluck("resource_id", &item.ResourceIDs).Error; err != nil {
+            return nil, err
+        }
+        export.Collections = append(export.Collections, item)
+    }
+
+    if err := s.db.Where("resource_id IN (?)", s.db.Model(&models.Resource{}).Select("id").Where("user_id = ?", userID)).
+        Order("created_at").Find(&export.Clicks).Error; err != nil {
+        return nil, err
+    }
+
+    if err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&export.Sessions).Error; err != nil {
+        return nil, err
+    }
+
+    return buildExportArchive(&export)
+}
+
+// ScheduleDeletion marks the account for deletion once the grace period
+// is over, signs it out everywhere and deletes its personal access tokens.
+// Logging back in and cancelling within the grace period keeps the account.
+func (s *AccountService) ScheduleDeletion(userID uint, password, code string) (*models.User, error) {
+    var user models.User
+    if err := s.db.First(&user, userID).Error; err != nil {
+        return nil, errors.New("user not found")
+    }
+
+    if user.DeleteAfter != nil {
+        return nil, ErrDeletionAlreadyScheduled
+    }
+
+    if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+        return nil, ErrInvalidPassword
+    }
+    if user.TOTPEnabledAt != nil {
+        if err := s.authService.checkSecondFactor(&user, code); err != nil {
+            return nil, err
+        }
+    }
+
+    deleteAfter := time.Now().Add(s.policy.GracePeriod)
+    if err := s.db.Model(&user).Update("delete_after", deleteAfter).Error; err != nil {
+        return nil, err
+    }
+    user.DeleteAfter = &deleteAfter
+
+    if err := s.authService.RevokeAllCredentials(user.ID); err != nil {
+        return nil, err
+    }
+
+    if err := s.authService.mailer.Send(mailer.Message{
+        To:      user.Email,
+        Subject: "Your DevLink account is scheduled for deletion",
+        Body: fmt.Sprintf("Hi %s,\n\nYour DevLink account and its data will be permanently deleted on %s.\n"+
+            "Changed your mind? Log in before then and cancel the deletion from your account settings.\n",
+            user.Name, deleteAfter.UTC().Format("January 2, 2006 at 15:04 UTC")),
+    }); err != nil {
+        log.Printf("Failed to send deletion notice to user %d: %v", user.ID, err)
+    }
+
+    return &user, nil
+}
+
+func (s *AccountService) CancelDeletion(userID uint) error {
+    result := s.db.Model(&models.User{}).
+        Where("id = ? AND delete_after IS NOT NULL", userID).
+        Update("delete_after", nil)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrDeletionNotScheduled
+    }
+    return nil
+}
+
+// RunPurger hard-deletes accounts whose grace period has passed, checking
+// every interval until ctx is cancelled.
+func (s *AccountService) RunPurger(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if purged, err := s.PurgeDueAccounts(); err != nil {
+            log.Printf("Account purge failed: %v", err)
+        } else if purged > 0 {
+            log.Printf("Purged %d deleted account(s)", purged)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (s *AccountService) PurgeDueAccounts() (int, error) {
+    var userIDs []uint
+    if err := s.db.Model(&models.User{}).
+        Where("delete_after IS NOT NULL AND delete_after <= ?", time.Now()).
+        Pluck("id", &userIDs).Error; err != nil {
+        return 0, err
+    }
+
+    purged := 0
+    for _, userID := range userIDs {
+        if err := s.purgeAccount(userID); err != nil {
+            return purged, fmt.Errorf("user %d: %w", userID, err)
+        }
+        purged++
+    }
+
+    return purged, nil
+}
+
+// purgeAccount deletes the user and their data, then brings the search
+// index up to date for every resource that was deleted or anonymized.
+func (s *AccountService) purgeAccount(userID uint) error {
+    // Resources the purge may touch: the user's own and those of workspaces
+    // that could be deleted along with their last member
+    var affected []uint
+    if s.index != nil {
+        if err := s.db.Unscoped().Model(&models.Resource{}).
+            Where("user_id = ? OR workspace_id IN (?)", userID,
+                s.db.Model(&models.WorkspaceMember{}).Select("workspace_id").Where("user_id = ?", userID)).
+            Pluck("id", &affected).Error; err != nil {
+            return err
+        }
+    }
+
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if !s.policy.DeletePublicResources {
+            ghost, err := s.deletedUser(tx)
+            if err != nil {
+                return err
+            }
+
+            if err := tx.Model(&models.Resource{}).
+                Where("user_id = ? AND is_public = ?", userID, true).
+                Update("user_id", ghost.ID).Error; err != nil {
+                return err
+            }
+        }
+
+        // Collections go entirely, including anonymized resources' membership in them
+        collections := tx.Unscoped().Model(&models.Collection{}).Select("id").Where("user_id = ?", userID)
+        if err := tx.Where("collection_id IN (?) OR user_id = ?", collections, userID).
+            Delete(&models.CollectionMember{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("collection_id IN (?)", collections).Delete(&models.CollectionInvite{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Exec("DELETE FROM collection_resources WHERE collection_id IN (?)", collections).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Collection{}).Error; err != nil {
+            return err
+        }
+
+        if err := leaveWorkspaces(tx, userID); err != nil {
+            return err
+        }
+
+        // Everything still owned by the user goes, soft-deleted rows included
+        owned := tx.Unscoped().Model(&models.Resource{}).Select("id").Where("user_id = ?", userID)
+        if err := tx.Where("resource_id IN (?)", owned).Delete(&models.ResourceClick{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Exec("DELETE FROM resource_tags WHERE resource_id IN (?)", owned).Error; err != nil {
+            return err
+        }
+        if err := tx.Exec("DELETE FROM collection_resources WHERE resource_id IN (?)", owned).Error; err != nil {
+            return err
+        }
+        if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Resource{}).Error; err != nil {
+            return err
+        }
+
+        for _, model := range []interface{}{
+            &models.RefreshToken{},
+            &models.Session{},
+            &models.PasswordResetToken{},
+            &models.EmailVerificationToken{},
+            &models.UserIdentity{},
+            &models.MFAChallenge{},
+            &models.RecoveryCode{},
+            &models.PersonalAccessToken{},
+            &models.ShareLink{},
+        } {
+            if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+                return err
+            }
+        }
+
+        return tx.Unscoped().Delete(&models.User{}, userID).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    reindexResources(s.db, s.index, affected)
+    return nil
+}
+
+// deletedUser returns the placeholder owner for anonymized resources, as
+// seeded by EnsureDeletedUser. It refuses a placeholder that could log in
+// rather than hand it anybody's resources.
+func (s *AccountService) deletedUser(tx *gorm.DB) (*models.User, error) {
+    var user models.User
+    if err := tx.Where("placeholder = ?", true).First(&user).Error; err != nil {
+        return nil, fmt.Errorf("placeholder for deleted users: %w", err)
+    }
+    if user.DisabledAt == nil || user.Password != "" {
+        return nil, fmt.Errorf("placeholder for deleted users (user %d) is a usable account", user.ID)
+    }
+    return &user, nil
+}
+
+// EnsureDeletedUser creates the disabled, passwordless placeholder that
+// anonymized resources are handed to. Before it was flagged, the placeholder
+// was found by its address; such a row is adopted, but an account someone
+// registered under that address is left alone and a new address used.
+func EnsureDeletedUser(db *gorm.DB) error {
+    var count int64
+    if err := db.Unscoped().Model(&models.User{}).Where("placeholder = ?", true).Count(&count).Error; err != nil {
+        return err
+    }
+    if count > 0 {
+        return nil
+    }
+
+    var legacy models.User
+    err := db.Where("email = ?", deletedUserEmail).First(&legacy).Error
+    if err == nil && legacy.Password == "" && legacy.DisabledAt != nil {
+        return db.Model(&legacy).Update("placeholder", true).Error
+    }
+    if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+        return err
+    }
+
+    email := deletedUserEmail
+    if err == nil {
+        log.Printf("User %d holds the reserved address %s; giving the deleted-user placeholder another one", legacy.ID, deletedUserEmail)
+        email = fmt.Sprintf("deleted-user-%d@%s", time.Now().Unix(), reservedEmailDomain)
+    }
+
+    now := time.Now()
+    return db.Create(&models.User{
+        Name:            "Deleted user",
+        Email:           email,
+        DisabledAt:      &now,
+        EmailVerifiedAt: &now,
+        Placeholder:     true,
+    }).Error
+}
+
+// isReservedEmail reports whether email is on the placeholder's domain.
+func isReservedEmail(email string) bool {
+    return strings.HasSuffix(strings.ToLower(strings.TrimSpace(email)), "@"+reservedEmailDomain)
+}
+
+func buildExportArchive(export *AccountExport) ([]byte, error) {
+    var buf bytes.Buffer
+    archive := zip.NewWriter(&buf)
+
+    document, err := json.MarshalIndent(export, "", "  ")
+    if err != nil {
+        return nil, err
+    }
+    if err := writeArchiveFile(archive, "devlink-export.json", document); err != nil {
+        return nil, err
+    }
+
+    resourceRows := [][]string{{"id", "title", "url", "description", "category", "tags", "is_public", "click_count", "created_at", "updated_at"}}
+    for _, resource := range export.Resources {
+        resourceRows = append(resourceRows, []string{
+            strconv.FormatUint(uint64(resource.ID), 10),
+            resource.Title,
+            resource.URL,
+            resource.Description,
+            resource.Category,
+            strings.Join(resource.Tags, ","),
+            strconv.FormatBool(resource.IsPublic),
+            strconv.Itoa(resource.ClickCount),
+            resource.CreatedAt.UTC().Format(time.RFC3339),
+            resource.UpdatedAt.UTC().Format(time.RFC3339),
+        })
+    }
+
+    clickRows := [][]string{{"id", "resource_id", "referrer", "clicked_at"}}
+    for _, click := range export.Clicks {
+        clickRows = append(clickRows, []string{
+            strconv.FormatUint(uint64(click.ID), 10),
+            strconv.FormatUint(uint64(click.ResourceID), 10),
+            click.Referrer,
+            click.CreatedAt.UTC().Format(time.RFC3339),
+        })
+    }
+
+    sessionRows := [][]string{{"id", "user_agent", "ip_address", "created_at", "last_seen_at", "revoked_at"}}
+    for _, session := range export.Sessions {
+        revokedAt := ""
+        if session.RevokedAt != nil {
+            revokedAt = session.RevokedAt.UTC().Format(time.RFC3339)
+        }
+        sessionRows = append(sessionRows, []string{
+            strconv.FormatUint(uint64(session.ID), 10),
+            session.UserAgent,
+            session.IPAddress,
+            session.CreatedAt.UTC().Format(time.RFC3339),
+            session.LastSeenAt.UTC().Format(time.RFC3339),
+            revokedAt,
+        })
+    }
+
+    for _, table := range []struct {
+        name string
+        rows [][]string
+    }{
+        {"resources.csv", resourceRows},
+        {"clicks.csv", clickRows},
+        {"sessions.csv", sessionRows},
+    } {
+        var csvBuf bytes.Buffer
+        if err := csv.NewWriter(&csvBuf).WriteAll(table.rows); err != nil {
+            return nil, err
+        }
+        if err := writeArchiveFile(archive, table.name, csvBuf.Bytes()); err != nil {
+            return nil, err
+        }
+    }
+
+    if err := archive.Close(); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+func writeArchiveFile(archive *zip.Writer, name string, data []byte) error {
+    file, err := archive.Create(name)
+    if err != nil {
+        return err
+    }
+    _, err = file.Write(data)
+    return err
+}
+
+// leaveWorkspaces removes the user from every workspace. Where they were
+// the only owner the longest-standing remaining member takes over, and
+// workspaces left without members are deleted.
+func leaveWorkspaces(tx *gorm.DB, userID uint) error {
+    var memberships []models.WorkspaceMember
+    if err := tx.Where("user_id = ? AND role = ?", userID, models.WorkspaceRoleOwner).Find(&memberships).Error; err != nil {
+        return err
+    }
+
+    for _, membership := range memberships {
+        if checkOtherOwner(tx, membership.WorkspaceID, userID) == nil {
+            continue
+        }
+
+        var successor models.WorkspaceMember
+        err := tx.Where("workspace_id = ? AND user_id <> ?", membership.WorkspaceID, userID).
+            Order("CASE WHEN role = 'admin' THEN 0 ELSE 1 END, created_at").
+            First(&successor).Error
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            if err := tx.Where("workspace_id = ?", membership.WorkspaceID).Delete(&models.Resource{}).Error; err != nil {
+                return err
+            }
+            if err := tx.Delete(&models.Workspace{}, membership.WorkspaceID).Error; err != nil {
+                return err
+            }
+            continue
+        }
+        if err != nil {
+            return err
+        }
+        if err := tx.Model(&successor).Update("role", models.WorkspaceRoleOwner).Error; err != nil {
+            return err
+        }
+    }
+
+    return tx.Where("user_id = ?", userID).Delete(&models.WorkspaceMember{}).Error
+}
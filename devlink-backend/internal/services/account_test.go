@@ -0,0 +1,135 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/searchindex"
+)
+
+func TestScheduleDeletionRevokesAccessTokens(t *testing.T) {
+    db := newTestDB(t)
+    authService, _ := newTestAuthService(db)
+    accounts := NewAccountService(db, authService, nil, DefaultAccountDeletionPolicy())
+    tokens := NewAccessTokenService(db, authService)
+    user := createTestUser(t, db, "user@example.com", true)
+
+    _, raw, err := tokens.CreateToken(user.ID, CreateAccessTokenRequest{Name: "ci", Scopes: []string{ScopeResourcesRead}})
+    if err != nil {
+        t.Fatalf("CreateToken: %v", err)
+    }
+    if _, err := accounts.ScheduleDeletion(user.ID, "password123", ""); err != nil {
+        t.Fatalf("ScheduleDeletion: %v", err)
+    }
+
+    if _, err := tokens.Authenticate(raw); err != ErrInvalidAccessToken {
+        t.Errorf("Authenticate after scheduling deletion = %v, want ErrInvalidAccessToken", err)
+    }
+    assertNoAccessTokens(t, db, user.ID)
+}
+
+func TestPurgeAccountUpdatesSearchIndex(t *testing.T) {
+    db := newTestDB(t)
+    if err := EnsureDeletedUser(db); err != nil {
+        t.Fatalf("EnsureDeletedUser: %v", err)
+    }
+    index := searchindex.New()
+    authService, _ := newTestAuthService(db)
+    accounts := NewAccountService(db, authService, index, DefaultAccountDeletionPolicy())
+    resources := NewResourceService(db, nil, nil, index)
+    user := createTestUser(t, db, "user@example.com", true)
+
+    workspace, err := NewWorkspaceService(db, index).CreateWorkspace(user.ID, CreateWorkspaceRequest{Name: "Solo"})
+    if err != nil {
+        t.Fatalf("CreateWorkspace: %v", err)
+    }
+    create := func(workspaceID uint, title string, public bool) *models.Resource {
+        resource, err := resources.CreateResource(context.Background(), workspaceID, user.ID, CreateResourceRequest{
+            Title:    title,
+            URL:      "https://example.com/" + title,
+            IsPublic: &public,
+        })
+        if err != nil {
+            t.Fatalf("CreateResource(%q): %v", title, err)
+        }
+        return resource
+    }
+    kept := create(0, "kubernetes-public", true)
+    create(0, "kubernetes-private", false)
+    create(workspace.ID, "kubernetes-workspace", true)
+
+    if err := db.Model(user).Update("delete_after", time.Now().Add(-time.Minute)).Error; err != nil {
+        t.Fatalf("schedule deletion: %v", err)
+    }
+    if purged, err := accounts.PurgeDueAccounts(); err != nil || purged != 1 {
+        t.Fatalf("PurgeDueAccounts = %d, %v; want 1 account purged", purged, err)
+    }
+
+    // Only the anonymized public resource is left to find
+    hits := index.Search("kubernetes", 10)
+    if len(hits) != 1 || hits[0].ID != kept.ID {
+        t.Errorf("index finds %v after the purge, want only resource %d", hits, kept.ID)
+    }
+}
+
+func TestReservedEmailCannotBeClaimed(t *testing.T) {
+    db := newTestDB(t)
+    authService, _ := newTestAuthService(db)
+    user := createTestUser(t, db, "user@example.com", true)
+
+    for _, email := range []string{deletedUserEmail, "Deleted-User@DevLink.invalid", "anyone@devlink.invalid"} {
+        if _, err := authService.Register("Squatter", email, "password123", ""); !errors.Is(err, ErrReservedEmail) {
+            t.Errorf("Register(%s): err = %v, want ErrReservedEmail", email, err)
+        }
+        if err := authService.RequestEmailChange(user.ID, "password123", email); !errors.Is(err, ErrReservedEmail) {
+            t.Errorf("RequestEmailChange(%s): err = %v, want ErrReservedEmail", email, err)
+        }
+    }
+}
+
+func TestPurgeNeverAnonymizesToARealAccount(t *testing.T) {
+    db := newTestDB(t)
+    authService, _ := newTestAuthService(db)
+    accounts := NewAccountService(db, authService, nil, DefaultAccountDeletionPolicy())
+
+    // An account registered under the placeholder address before it was reserved
+    squatter := createTestUser(t, db, deletedUserEmail, true)
+    if err := EnsureDeletedUser(db); err != nil {
+        t.Fatalf("EnsureDeletedUser: %v", err)
+    }
+    if err := EnsureDeletedUser(db); err != nil {
+        t.Fatalf("EnsureDeletedUser run twice: %v", err)
+    }
+
+    user := createTestUser(t, db, "user@example.com", true)
+    public := true
+    resource, err := NewResourceService(db, nil, nil, nil).CreateResource(context.Background(), 0, user.ID, CreateResourceRequest{
+        Title:    "Shared guide",
+        URL:      "https://example.com/guide",
+        IsPublic: &public,
+    })
+    if err != nil {
+        t.Fatalf("CreateResource: %v", err)
+    }
+    db.Model(user).Update("delete_after", time.Now().Add(-time.Minute))
+    if _, err := accounts.PurgeDueAccounts(); err != nil {
+        t.Fatalf("PurgeDueAccounts: %v", err)
+    }
+
+    var owner models.User
+    db.Joins("JOIN resources ON resources.user_id = users.id").Where("resources.id = ?", resource.ID).First(&owner)
+    if owner.ID == squatter.ID || !owner.Placeholder || owner.DisabledAt == nil {
+        t.Errorf("resource handed to user %d (placeholder %v), want the disabled placeholder", owner.ID, owner.Placeholder)
+    }
+
+    // A placeholder that somehow became usable stops the purge
+    db.Model(&owner).Update("disabled_at", nil)
+    other := createTestUser(t, db, "other@example.com", true)
+    db.Model(other).Update("delete_after", time.Now().Add(-time.Minute))
+    if purged, err := accounts.PurgeDueAccounts(); err == nil || purged != 0 {
+        t.Errorf("PurgeDueAccounts = %d, %v; want it to refuse an enabled placeholder", purged, err)
+    }
+}
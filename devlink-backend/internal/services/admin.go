@@ -0,0 +1,292 @@
+package services
+
+import (
+    "errors"
+    "time"
+
+    "devlink-backend/internal/models"
+    "golang.org/x/crypto/bcrypt"
+    "gorm.io/gorm"
+)
+
+var (
+    ErrInvalidRole      = errors.New("role must be one of user, moderator, admin")
+    ErrCannotModifySelf = errors.New("admins cannot change their own role or disable themselves")
+    ErrUserNotFound     = errors.New("user not found")
+    ErrSearchIndexOff   = errors.New("resources are searched in Postgres; set SEARCH_BACKEND=index to use the search index")
+)
+
+type AdminService struct {
+    db          *gorm.DB
+    authService *AuthService
+    index       SearchIndex // nil when searching with Postgres
+}
+
+type AdminUserFilters struct {
+    Search   string `form:"search"`
+    Role     string `form:"role"`
+    Disabled *bool  `form:"disabled"`
+    Page     int    `form:"page,default=1"`
+    Limit    int    `form:"limit,default=20"`
+}
+
+type AdminResourceFilters struct {
+    Search string `form:"search"`
+    Hidden *bool  `form:"hidden"`
+    Page   int    `form:"page,default=1"`
+    Limit  int    `form:"limit,default=20"`
+}
+
+func NewAdminService(db *gorm.DB, authService *AuthService, index SearchIndex) *AdminService {
+    return &AdminService{
+        db:          db,
+        authService: authService,
+        index:       index,
+    }
+}
+
+// EnsureAdmins promotes the given addresses to admin, so a fresh deployment
+// can bootstrap its first administrator from configuration.
+func (s *AdminService) EnsureAdmins(emails []string) error {
+    if len(emails) == 0 {
+        return nil
+    }
+
+    return s.db.Model(&models.User{}).
+        Where("email IN ? AND role <> ?", emails, models.RoleAdmin).
+        Update("role", models.RoleAdmin).Error
+}
+
+func (s *AdminService) GetUsers(filters AdminUserFilters) ([]models.User, int64, error) {
+    var users []models.User
+    var total int64
+
+    query := s.db.Model(&models.User{})
+
+    if filters.Search != "" {
+        searchTerm := "%" + filters.Search + "%"
+        query = query.Where("name ILIKE ? OR email ILIKE ?", searchTerm, searchTerm)
+    }
+
+    if filters.Role != "" {
+        query = query.Where("role = ?", filters.Role)
+    }
+
+    if filters.Disabled != nil {
+        if *filters.Disabled {
+            query = query.Where("disabled_at IS NOT NULL")
+        } else {
+            query = query.Where("disabled_at IS NULL")
+        }
+    }
+
+    // Count total before pagination
+    if err := query.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+
+    // Apply pagination
+    offset := (filters.Page - 1) * filters.Limit
+    if err := query.Order("created_at DESC").
+        Limit(filters.Limit).
+        Offset(offset).
+        Find(&users).Error; err != nil {
+        return nil, 0, err
+    }
+
+    return users, total, nil
+}
+
+func (s *AdminService) SetUserRole(actorID, userID uint, role string) (*models.User, error) {
+    if role != models.RoleUser && role != models.RoleModerator && role != models.RoleAdmin {
+        return nil, ErrInvalidRole
+    }
+    if actorID == userID {
+        return nil, ErrCannotModifySelf
+    }
+
+    user, err := s.findUser(userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.db.Model(user).Update("role", role).Error; err != nil {
+        return nil, err
+    }
+
+    // Roles travel in access tokens, so sign the user out to pick up the change.
+    // Personal access tokens go too, they were issued under the old role.
+    if err := s.authService.RevokeAllCredentials(user.ID); err != nil {
+        return nil, err
+    }
+
+    return user, nil
+}
+
+// DisableUser blocks logins, ends every session and deletes the user's
+// personal access tokens. Access tokens still in flight stop working
+// because their sessions are revoked.
+func (s *AdminService) DisableUser(actorID, userID uint) (*models.User, error) {
+    if actorID == userID {
+        return nil, ErrCannotModifySelf
+    }
+
+    user, err := s.findUser(userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.db.Model(user).Update("disabled_at", time.Now()).Error; err != nil {
+        return nil, err
+    }
+
+    if err := s.authService.RevokeAllCredentials(user.ID); err != nil {
+        return nil, err
+    }
+
+    return user, nil
+}
+
+func (s *AdminService) EnableUser(userID uint) (*models.User, error) {
+    user, err := s.findUser(userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.db.Model(user).Update("disabled_at", nil).Error; err != nil {
+        return nil, err
+    }
+
+    return user, nil
+}
+
+// ForcePasswordReset scrambles the user's password, signs them out,
+// deletes their personal access tokens and emails a reset link, so the
+// only way back in is choosing a new password.
+func (s *AdminService) ForcePasswordReset(userID uint) error {
+    user, err := s.findUser(userID)
+    if err != nil {
+        return err
+    }
+
+    secret, _, err := generateOpaqueToken()
+    if err != nil {
+        return err
+    }
+    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+    if err != nil {
+        return err
+    }
+
+    if err := s.db.Model(user).Update("password", string(hashedPassword)).Error; err != nil {
+        return err
+    }
+
+    if err := s.authService.RevokeAllCredentials(user.ID); err != nil {
+        return err
+    }
+
+    return s.authService.RequestPasswordReset(user.Email)
+}
+
+// GetPublicResources lists every resource of the public feed, hidden ones
+// included, for moderation. Public workspace resources are only visible to
+// the workspace, so they are left out.
+func (s *AdminService) GetPublicResources(filters AdminResourceFilters) ([]models.Resource, int64, error) {
+    var resources []models.Resource
+    var total int64
+
+    query := s.moderated().Preload("User")
+
+    if filters.Search != "" {
+        searchTerm := "%" + filters.Search + "%"
+        query = query.Where("title ILIKE ? OR description ILIKE ? OR url ILIKE ?",
+            searchTerm, searchTerm, searchTerm)
+    }
+
+    if filters.Hidden != nil {
+        if *filters.Hidden {
+            query = query.Where("hidden_at IS NOT NULL")
+        } else {
+            query = query.Where("hidden_at IS NULL")
+        }
+    }
+
+    // Count total
+    if err := query.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+
+    // Apply pagination
+    offset := (filters.Page - 1) * filters.Limit
+    if err := query.Order("created_at DESC").
+        Limit(filters.Limit).
+        Offset(offset).
+        Find(&resources).Error; err != nil {
+        return nil, 0, err
+    }
+
+    return resources, total, nil
+}
+
+// SetResourceHidden hides or restores a resource of the public feed,
+// whoever owns it. Private and workspace resources are not moderated.
+func (s *AdminService) SetResourceHidden(resourceID uint, hidden bool) (*models.Resource, error) {
+    var resource models.Resource
+    if err := s.moderated().First(&resource, resourceID).Error; err != nil {
+        return nil, errors.New("resource not found")
+    }
+
+    var hiddenAt interface{}
+    if hidden {
+        hiddenAt = time.Now()
+    }
+
+    if err := s.db.Model(&resource).Update("hidden_at", hiddenAt).Error; err != nil {
+        return nil, err
+    }
+
+    return &resource, nil
+}
+
+// DeleteResource deletes a resource of the public feed, whoever owns it.
+func (s *AdminService) DeleteResource(resourceID uint) error {
+    result := s.moderated().Delete(&models.Resource{}, resourceID)
+
+    if result.Error != nil {
+        return result.Error
+    }
+
+    if result.RowsAffected == 0 {
+        return errors.New("resource not found")
+    }
+    if s.index != nil {
+        s.index.Delete(resourceID)
+    }
+
+    return nil
+}
+
+// RebuildSearchIndex refills the search index from the database, for when
+// it was lost or has drifted, and returns how many resources it now holds.
+func (s *AdminService) RebuildSearchIndex() (int, error) {
+    if s.index == nil {
+        return 0, ErrSearchIndexOff
+    }
+    return RebuildSearchIndex(s.db, s.index)
+}
+
+// findUser loads a real account; the deleted-user placeholder is off limits.
+func (s *AdminService) findUser(userID uint) (*models.User, error) {
+    var user models.User
+    if err := s.db.Where("placeholder = ?", false).First(&user, userID).Error; err != nil {
+        return nil, ErrUserNotFound
+    }
+    return &user, nil
+}
+
+// moderated starts a query limited to what moderators may see and act on:
+// public resources outside workspaces.
+func (s *AdminService) moderated() *gorm.DB {
+    return s.db.Model(&models.Resource{}).Where("resources.is_public = ? AND resources.workspace_id IS NULL", true)
+}
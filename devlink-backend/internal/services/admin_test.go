@@ -0,0 +1,81 @@
+package services
+
+import (
+    "testing"
+
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+func TestAdminActionsRevokeAccessTokens(t *testing.T) {
+    actions := map[string]func(admin *AdminService, actorID, userID uint) error{
+        "role change": func(admin *AdminService, actorID, userID uint) error {
+            _, err := admin.SetUserRole(actorID, userID, models.RoleModerator)
+            return err
+        },
+        "disable": func(admin *AdminService, actorID, userID uint) error {
+            _, err := admin.DisableUser(actorID, userID)
+            return err
+        },
+        "force password reset": func(admin *AdminService, actorID, userID uint) error {
+            return admin.ForcePasswordReset(userID)
+        },
+    }
+
+    for name, action := range actions {
+        t.Run(name, func(t *testing.T) {
+            db := newTestDB(t)
+            authService, _ := newTestAuthService(db)
+            admin := NewAdminService(db, authService, nil)
+            tokens := NewAccessTokenService(db, authService)
+            actor := createTestUser(t, db, "admin@example.com", true)
+            user := createTestUser(t, db, "user@example.com", true)
+
+            _, raw, err := tokens.CreateToken(user.ID, CreateAccessTokenRequest{Name: "ci", Scopes: []string{ScopeResourcesRead}})
+            if err != nil {
+                t.Fatalf("CreateToken: %v", err)
+            }
+            if err := action(admin, actor.ID, user.ID); err != nil {
+                t.Fatalf("action: %v", err)
+            }
+
+            if _, err := tokens.Authenticate(raw); err != ErrInvalidAccessToken {
+                t.Errorf("Authenticate after %s = %v, want ErrInvalidAccessToken", name, err)
+            }
+            assertNoAccessTokens(t, db, user.ID)
+        })
+    }
+}
+
+func assertNoAccessTokens(t *testing.T, db *gorm.DB, userID uint) {
+    t.Helper()
+
+    var count int64
+    db.Model(&models.PersonalAccessToken{}).Where("user_id = ?", userID).Count(&count)
+    if count != 0 {
+        t.Errorf("%d personal access tokens left, want 0", count)
+    }
+}
+
+func TestModerationLimitedToPublicFeed(t *testing.T) {
+    f := newWorkspaceFixture(t)
+    authService, _ := newTestAuthService(f.db)
+    admin := NewAdminService(f.db, authService, nil)
+    personal := f.createResource(t, 0, f.member.ID, "Private notes", "notes", false)
+
+    for _, resource := range []*models.Resource{f.shared, f.private, personal} {
+        if _, err := admin.SetResourceHidden(resource.ID, true); err == nil {
+            t.Errorf("moderator hid %q", resource.Title)
+        }
+        if err := admin.DeleteResource(resource.ID); err == nil {
+            t.Errorf("moderator deleted %q", resource.Title)
+        }
+    }
+
+    if _, err := admin.SetResourceHidden(f.own.ID, true); err != nil {
+        t.Errorf("hiding a public resource: %v", err)
+    }
+    if err := admin.DeleteResource(f.own.ID); err != nil {
+        t.Errorf("deleting a public resource: %v", err)
+    }
+}
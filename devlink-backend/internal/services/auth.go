@@ -2,37 +2,75 @@ package services
 
 import (
     "errors"
+    "log"
     "time"
 
+    "devlink-backend/internal/jwtkeys"
+    "devlink-backend/internal/mailer"
     "devlink-backend/internal/models"
     "github.com/golang-jwt/jwt/v5"
     "golang.org/x/crypto/bcrypt"
     "gorm.io/gorm"
 )
 
+const (
+    accessTokenTTL  = 15 * time.Minute
+    refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+    ErrInvalidCredentials  = errors.New("invalid credentials")
+    ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+    ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+    ErrAccountDisabled     = errors.New("this account has been disabled")
+    ErrUnverifiedLogin     = errors.New("confirm your email address before signing in, we have sent you a link")
+)
+
 type AuthService struct {
-    db        *gorm.DB
-    jwtSecret string
+    db           *gorm.DB
+    keys         *jwtkeys.KeySet
+    mailer       mailer.Mailer
+    appURL       string
+    registration RegistrationPolicy
 }
 
 type Claims struct {
     UserID uint   `json:"user_id"`
     Email  string `json:"email"`
+    Role   string `json:"role"`
     jwt.RegisteredClaims
 }
 
-func NewAuthService(db *gorm.DB, jwtSecret string) *AuthService {
+// ClientInfo describes the device a login comes from.
+type ClientInfo struct {
+    UserAgent string
+    IPAddress string
+}
+
+// TokenPair is what a successful login or refresh hands back to the client.
+type TokenPair struct {
+    AccessToken  string
+    RefreshToken string
+    ExpiresIn    int64 // access token lifetime in seconds
+}
+
+func NewAuthService(db *gorm.DB, keys *jwtkeys.KeySet, mailer mailer.Mailer, appURL string, registration RegistrationPolicy) *AuthService {
     return &AuthService{
-        db:        db,
-        jwtSecret: jwtSecret,
+        db:           db,
+        keys:         keys,
+        mailer:       mailer,
+        appURL:       appURL,
+        registration: registration,
     }
 }
 
-func (s *AuthService) Register(name, email, password string) (*models.User, error) {
+// Register creates an account if the registration policy allows it. The
+// invite code is only looked at in invite-only mode.
+func (s *AuthService) Register(name, email, password, inviteCode string) (*models.User, error) {
     // Check if user already exists
     var existingUser models.User
     if err := s.db.Where("email = ?", email).First(&existingUser).Error; err == nil {
-        return nil, errors.New("user with this email already exists")
+        return nil, ErrEmailTaken
     }
 
     // Hash password
@@ -48,51 +86,166 @@ func (s *AuthService) Register(name, email, password string) (*models.User, erro
         Password: string(hashedPassword),
     }
 
-    if err := s.db.Create(&user).Error; err != nil {
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        if err := s.checkRegistration(tx, email, inviteCode); err != nil {
+            return err
+        }
+        return tx.Create(&user).Error
+    })
+    if err != nil {
         return nil, err
     }
 
+    // A failed send shouldn't fail registration, the user can ask for a resend
+    if err := s.sendVerificationLink(&user, user.Email, ""); err != nil {
+        log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+    }
+
     return &user, nil
 }
 
-func (s *AuthService) Login(email, password string) (*models.User, string, error) {
+func (s *AuthService) Login(email, password string, client ClientInfo) (*LoginResult, error) {
     var user models.User
     if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
-        return nil, "", errors.New("invalid credentials")
+        return nil, ErrInvalidCredentials
     }
 
     // Check password
     if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-        return nil, "", errors.New("invalid credentials")
+        return nil, ErrInvalidCredentials
+    }
+
+    return s.loginUser(&user, client)
+}
+
+// StartSession signs an already authenticated user in. Every login starts a
+// new session and refresh token family.
+func (s *AuthService) StartSession(user *models.User, client ClientInfo) (*TokenPair, error) {
+    if s.requiresVerifiedEmail(user) {
+        return nil, ErrUnverifiedLogin
     }
 
-    // Generate JWT token
-    token, err := s.generateToken(user.ID, user.Email)
+    session, err := s.createSession(user.ID, client)
     if err != nil {
-        return nil, "", err
+        return nil, err
     }
 
-    return &user, token, nil
+    return s.issueTokenPair(s.db, user, session.TokenID)
 }
 
-func (s *AuthService) generateToken(userID uint, email string) (string, error) {
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// can be used once; presenting one that was already rotated is treated as
+// theft and revokes the whole session.
+func (s *AuthService) Refresh(refreshToken string) (*TokenPair, error) {
+    var stored models.RefreshToken
+    if err := s.db.Where("token_hash = ?", hashToken(refreshToken)).First(&stored).Error; err != nil {
+        return nil, ErrInvalidRefreshToken
+    }
+
+    if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+        return nil, ErrInvalidRefreshToken
+    }
+
+    if stored.UsedAt != nil {
+        if err := s.revokeSession(stored.FamilyID); err != nil {
+            return nil, err
+        }
+        return nil, ErrRefreshTokenReused
+    }
+
+    var tokens *TokenPair
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        // Guard against two concurrent refreshes both winning the race
+        result := tx.Model(&models.RefreshToken{}).
+            Where("id = ? AND used_at IS NULL", stored.ID).
+            Update("used_at", time.Now())
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrRefreshTokenReused
+        }
+
+        var user models.User
+        if err := tx.First(&user, stored.UserID).Error; err != nil || user.DisabledAt != nil || s.requiresVerifiedEmail(&user) {
+            return ErrInvalidRefreshToken
+        }
+
+        var err error
+        tokens, err = s.issueTokenPair(tx, &user, stored.FamilyID)
+        return err
+    })
+
+    if errors.Is(err, ErrRefreshTokenReused) {
+        if revokeErr := s.revokeSession(stored.FamilyID); revokeErr != nil {
+            return nil, revokeErr
+        }
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    s.touchSession(stored.FamilyID)
+    return tokens, nil
+}
+
+// Logout revokes the session the given refresh token belongs to, which also
+// invalidates any access tokens issued from it.
+func (s *AuthService) Logout(refreshToken string) error {
+    var stored models.RefreshToken
+    if err := s.db.Where("token_hash = ?", hashToken(refreshToken)).First(&stored).Error; err != nil {
+        return ErrInvalidRefreshToken
+    }
+
+    return s.revokeSession(stored.FamilyID)
+}
+
+func (s *AuthService) issueTokenPair(db *gorm.DB, user *models.User, familyID string) (*TokenPair, error) {
+    accessToken, err := s.generateToken(user, familyID)
+    if err != nil {
+        return nil, err
+    }
+
+    refreshToken, refreshHash, err := generateOpaqueToken()
+    if err != nil {
+        return nil, err
+    }
+
+    record := models.RefreshToken{
+        UserID:    user.ID,
+        FamilyID:  familyID,
+        TokenHash: refreshHash,
+        ExpiresAt: time.Now().Add(refreshTokenTTL),
+    }
+    if err := db.Create(&record).Error; err != nil {
+        return nil, err
+    }
+
+    return &TokenPair{
+        AccessToken:  accessToken,
+        RefreshToken: refreshToken,
+        ExpiresIn:    int64(accessTokenTTL.Seconds()),
+    }, nil
+}
+
+func (s *AuthService) generateToken(user *models.User, sessionID string) (string, error) {
     claims := Claims{
-        UserID: userID,
-        Email:  email,
+        UserID: user.ID,
+        Email:  user.Email,
+        Role:   user.Role,
         RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+            ID:        sessionID, // jti
+            ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessTokenTTL)),
             IssuedAt:  jwt.NewNumericDate(time.Now()),
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString([]byte(s.jwtSecret))
+    return s.keys.Sign(claims)
 }
 
 func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
-    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-        return []byte(s.jwtSecret), nil
-    })
+    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keys.Keyfunc,
+        jwt.WithValidMethods(s.keys.ValidMethods()))
 
     if err != nil {
         return nil, err
@@ -104,4 +257,10 @@ func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
     }
 
     return claims, nil
+}
+
+// PublicKeys returns the verification keys other services can fetch from
+// the JWKS endpoint.
+func (s *AuthService) PublicKeys() []jwtkeys.JWK {
+    return s.keys.JWKS()
 }
\ No newline at end of file
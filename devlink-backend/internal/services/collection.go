@@ -0,0 +1,289 @@
+package services
+
+import (
+    "errors"
+
+    "devlink-backend/internal/mailer"
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+const maxCollectionDepth = 5
+
+var (
+    ErrCollectionNotFound = errors.New("collection not found or access denied")
+    ErrInvalidParent      = errors.New("a collection cannot be moved into itself or one of its sub-collections")
+    ErrCollectionTooDeep  = errors.New("collections can be nested at most 5 levels deep")
+    ErrNotInCollection    = errors.New("resource is not in this collection")
+    ErrCollectionRole     = errors.New("your role in this collection does not allow that")
+    ErrNotCollectionOwner = errors.New("only the collection's creator can move it")
+)
+
+type CollectionService struct {
+    db     *gorm.DB
+    mailer mailer.Mailer
+    appURL string
+}
+
+type CreateCollectionRequest struct {
+    Name        string `json:"name" binding:"required,max=100"`
+    Description string `json:"description"`
+    Icon        string `json:"icon" binding:"max=50"`
+    ParentID    *uint  `json:"parent_id"`
+    Position    int    `json:"position"`
+}
+
+type UpdateCollectionRequest struct {
+    Name        *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+    Description *string `json:"description,omitempty"`
+    Icon        *string `json:"icon,omitempty" binding:"omitempty,max=50"`
+    ParentID    *uint   `json:"parent_id,omitempty"`
+    MoveToRoot  bool    `json:"move_to_root,omitempty"` // detach from the parent
+    Position    *int    `json:"position,omitempty"`
+}
+
+type CollectionResourcesRequest struct {
+    ResourceIDs []uint `json:"resource_ids" binding:"required,min=1"`
+}
+
+// CollectionSummary is a collection with the number of resources in it
+// and the requesting user's role.
+type CollectionSummary struct {
+    models.Collection
+    ResourceCount int64  `json:"resource_count"`
+    Role          string `json:"role"`
+}
+
+func NewCollectionService(db *gorm.DB, mailer mailer.Mailer, appURL string) *CollectionService {
+    return &CollectionService{
+        db:     db,
+        mailer: mailer,
+        appURL: appURL,
+    }
+}
+
+// GetUserCollections returns the user's own collections and the ones shared
+// with them, flat and in display order. Clients build the tree from
+// parent_id; a shared collection's parent is not part of the list.
+func (s *CollectionService) GetUserCollections(userID uint) ([]CollectionSummary, error) {
+    var collections []CollectionSummary
+    err := s.db.Model(&models.Collection{}).
+        Select("collections.*, (SELECT COUNT(*) FROM collection_resources "+
+            "JOIN resources ON resources.id = collection_resources.resource_id AND resources.deleted_at IS NULL "+
+            "WHERE collection_resources.collection_id = collections.id) AS resource_count, "+
+            "CASE WHEN collections.user_id = ? THEN ? ELSE (SELECT role FROM collection_members "+
+            "WHERE collection_members.collection_id = collections.id AND collection_members.user_id = ?) END AS role",
+            userID, models.CollectionOwner, userID).
+        Where("user_id = ? OR id IN (?)", userID,
+            s.db.Model(&models.CollectionMember{}).Select("collection_id").Where("user_id = ?", userID)).
+        Order("position, name").
+        Find(&collections).Error
+    if err != nil {
+        return nil, err
+    }
+    return collections, nil
+}
+
+// GetCollection returns a collection the user can at least view, together
+// with their role in it.
+func (s *CollectionService) GetCollection(collectionID, userID uint) (*models.Collection, string, error) {
+    return s.authorize(collectionID, userID, models.CollectionViewer)
+}
+
+func (s *CollectionService) CreateCollection(userID uint, req CreateCollectionRequest) (*models.Collection, error) {
+    if req.ParentID != nil {
+        if err := s.checkParent(userID, 0, *req.ParentID); err != nil {
+            return nil, err
+        }
+    }
+
+    collection := models.Collection{
+        UserID:      userID,
+        ParentID:    req.ParentID,
+        Name:        req.Name,
+        Description: req.Description,
+        Icon:        req.Icon,
+        Position:    req.Position,
+    }
+
+    if err := s.db.Create(&collection).Error; err != nil {
+        return nil, err
+    }
+
+    return &collection, nil
+}
+
+func (s *CollectionService) UpdateCollection(collectionID, userID uint, req UpdateCollectionRequest) (*models.Collection, error) {
+    collection, _, err := s.authorize(collectionID, userID, models.CollectionOwner)
+    if err != nil {
+        return nil, err
+    }
+
+    // The parent lives in the creator's tree, so only they can move it
+    if (req.MoveToRoot || req.ParentID != nil) && collection.UserID != userID {
+        return nil, ErrNotCollectionOwner
+    }
+
+    // Update only provided fields
+    updates := make(map[string]interface{})
+    if req.Name != nil {
+        updates["name"] = *req.Name
+    }
+    if req.Description != nil {
+        updates["description"] = *req.Description
+    }
+    if req.Icon != nil {
+        updates["icon"] = *req.Icon
+    }
+    if req.Position != nil {
+        updates["position"] = *req.Position
+    }
+    if req.MoveToRoot {
+        updates["parent_id"] = nil
+    } else if req.ParentID != nil {
+        if err := s.checkParent(userID, collection.ID, *req.ParentID); err != nil {
+            return nil, err
+        }
+        updates["parent_id"] = *req.ParentID
+    }
+
+    if len(updates) > 0 {
+        if err := s.db.Model(collection).Updates(updates).Error; err != nil {
+            return nil, err
+        }
+    }
+
+    collection, _, err = s.GetCollection(collectionID, userID)
+    return collection, err
+}
+
+// DeleteCollection removes a collection but not its resources. Its
+// sub-collections move up to take its place.
+func (s *CollectionService) DeleteCollection(collectionID, userID uint) error {
+    collection, _, err := s.authorize(collectionID, userID, models.CollectionOwner)
+    if err != nil {
+        return err
+    }
+
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(&models.Collection{}).
+            Where("parent_id = ?", collection.ID).
+            Update("parent_id", collection.ParentID).Error; err != nil {
+            return err
+        }
+
+        if err := tx.Model(collection).Association("Resources").Clear(); err != nil {
+            return err
+        }
+
+        if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionMember{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("collection_id = ?", collection.ID).Delete(&models.CollectionInvite{}).Error; err != nil {
+            return err
+        }
+
+        return tx.Delete(collection).Error
+    })
+}
+
+// AddResources puts the user's own resources into a collection they can
+// edit. Resources already in it are left alone. Workspace resources only
+// qualify while the user is still a member of their workspace.
+func (s *CollectionService) AddResources(collectionID, userID uint, resourceIDs []uint) error {
+    collection, _, err := s.authorize(collectionID, userID, models.CollectionEditor)
+    if err != nil {
+        return err
+    }
+
+    var resources []models.Resource
+    if err := s.db.Where("id IN ? AND user_id = ?", resourceIDs, userID).
+        Where("workspace_id IS NULL OR workspace_id IN (?)", memberWorkspaceIDs(s.db, userID)).
+        Find(&resources).Error; err != nil {
+        return err
+    }
+    if len(resources) != len(uniqueIDs(resourceIDs)) {
+        return ErrResourceNotFound
+    }
+
+    return s.db.Model(collection).Association("Resources").Append(resources)
+}
+
+func (s *CollectionService) RemoveResource(collectionID, userID, resourceID uint) error {
+    collection, _, err := s.authorize(collectionID, userID, models.CollectionEditor)
+    if err != nil {
+        return err
+    }
+
+    result := s.db.Exec("DELETE FROM collection_resources WHERE collection_id = ? AND resource_id = ?",
+        collection.ID, resourceID)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrNotInCollection
+    }
+    return nil
+}
+
+// checkParent makes sure parentID is one of the user's collections, is not
+// collectionID or below it, and keeps the tree within maxCollectionDepth.
+func (s *CollectionService) checkParent(userID, collectionID, parentID uint) error {
+    height := 1
+    if collectionID != 0 {
+        var err error
+        if height, err = s.subtreeHeight(collectionID); err != nil {
+            return err
+        }
+    }
+
+    depth := 0
+    for id := &parentID; id != nil; {
+        if *id == collectionID {
+            return ErrInvalidParent
+        }
+        depth++
+        if depth+height > maxCollectionDepth {
+            return ErrCollectionTooDeep
+        }
+
+        var ancestor models.Collection
+        if err := s.db.Select("id, parent_id").
+            Where("id = ? AND user_id = ?", *id, userID).
+            First(&ancestor).Error; err != nil {
+            return ErrCollectionNotFound
+        }
+        id = ancestor.ParentID
+    }
+    return nil
+}
+
+// subtreeHeight counts the levels from a collection down to its deepest
+// sub-collection, the collection itself being one.
+func (s *CollectionService) subtreeHeight(collectionID uint) (int, error) {
+    height := 1
+    level := []uint{collectionID}
+    for {
+        var children []uint
+        if err := s.db.Model(&models.Collection{}).Where("parent_id IN ?", level).Pluck("id", &children).Error; err != nil {
+            return 0, err
+        }
+        if len(children) == 0 {
+            return height, nil
+        }
+        height++
+        level = children
+    }
+}
+
+func uniqueIDs(ids []uint) []uint {
+    seen := make(map[uint]bool, len(ids))
+    unique := make([]uint, 0, len(ids))
+    for _, id := range ids {
+        if !seen[id] {
+            seen[id] = true
+            unique = append(unique, id)
+        }
+    }
+    return unique
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/mailer"
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+const (
+    collectionInviteTTL = 7 * 24 * time.Hour
+
+    // Invites send email to arbitrary addresses, so they are capped per
+    // collection and per inviter over a rolling day, revoked ones included.
+    inviteWindow            = 24 * time.Hour
+    maxInvitesPerCollection = 20
+    maxInvitesPerUser       = 50
+)
+
+var (
+    ErrInvalidCollectionRole    = errors.New("role must be one of viewer, editor, owner")
+    ErrAlreadyMember            = errors.New("that user already has access to this collection")
+    ErrMemberNotFound           = errors.New("member not found")
+    ErrCollectionInviteNotFound = errors.New("invitation not found")
+    ErrInvalidCollectionInvite  = errors.New("invitation is invalid, expired or was sent to a different email address")
+    ErrTooManyInvites           = errors.New("too many invitations sent today, try again tomorrow")
+)
+
+type InviteMemberRequest struct {
+    Email string `json:"email" binding:"required,email"`
+    Role  string `json:"role" binding:"required"`
+}
+
+type UpdateMemberRequest struct {
+    Role string `json:"role" binding:"required"`
+}
+
+type AcceptCollectionInviteRequest struct {
+    Token string `json:"token" binding:"required"`
+}
+
+// CollectionMemberInfo is one person with access to a collection.
+type CollectionMemberInfo struct {
+    UserID  uint   `json:"user_id"`
+    Name    string `json:"name"`
+    Email   string `json:"email"`
+    Role    string `json:"role"`
+    Creator bool   `json:"creator"`
+}
+
+// collectionRank orders collection roles; zero means no access.
+func collectionRank(role string) int {
+    switch role {
+    case models.CollectionOwner:
+        return 3
+    case models.CollectionEditor:
+        return 2
+    case models.CollectionViewer:
+        return 1
+    default:
+        return 0
+    }
+}
+
+// collectionRole returns the collection and the user's role in it: owner
+// for its creator, the member role for people it is shared with.
+func collectionRole(db *gorm.DB, collectionID, userID uint) (*models.Collection, string, error) {
+    var collection models.Collection
+    if err := db.First(&collection, collectionID).Error; err != nil {
+        return nil, "", ErrCollectionNotFound
+    }
+    if collection.UserID == userID {
+        return &collection, models.CollectionOwner, nil
+    }
+
+    var member models.CollectionMember
+    if err := db.Where("collection_id = ? AND user_id = ?", collectionID, userID).First(&member).Error; err != nil {
+        return nil, "", ErrCollectionNotFound
+    }
+    return &collection, member.Role, nil
+}
+
+// sharedResourceIDs selects the resources reachable through collections
+// shared with the user in one of the given roles.
+func sharedResourceIDs(db *gorm.DB, userID uint, roles ...string) *gorm.DB {
+    return db.Table("collection_resources").
+        Select("collection_resources.resource_id").
+        Joins("JOIN collection_members ON collection_members.collection_id = collection_resources.collection_id").
+        Joins("JOIN collections ON collections.id = collection_resources.collection_id AND collections.deleted_at IS NULL").
+        Where("collection_members.user_id = ? AND collection_members.role IN ?", userID, roles)
+}
+
+// authorize loads a collection and checks the user holds at least minRole.
+// Users without any access get ErrCollectionNotFound so they can't probe IDs.
+func (s *CollectionService) authorize(collectionID, userID uint, minRole string) (*models.Collection, string, error) {
+    collection, role, err := collectionRole(s.db, collectionID, userID)
+    if err != nil {
+        return nil, "", err
+    }
+    if collectionRank(role) < collectionRank(minRole) {
+        return nil, "", ErrCollectionRole
+    }
+    return collection, role, nil
+}
+
+// GetMembers lists everyone with access, the creator first.
+func (s *CollectionService) GetMembers(collectionID, userID uint) ([]CollectionMemberInfo, error) {
+    collection, _, err := s.authorize(collectionID, userID, models.CollectionViewer)
+    if err != nil {
+        return nil, err
+    }
+
+    var creator models.User
+    if err := s.db.First(&creator, collection.UserID).Error; err != nil {
+        return nil, err
+    }
+    members := []CollectionMemberInfo{{
+        UserID:  creator.ID,
+        Name:    creator.Name,
+        Email:   creator.Email,
+        Role:    models.CollectionOwner,
+        Creator: true,
+    }}
+
+    var rows []models.CollectionMember
+    if err := s.db.Where("collection_id = ?", collection.ID).Preload("User").Order("created_at").Find(&rows).Error; err != nil {
+        return nil, err
+    }
+    for _, row := range rows {
+        members = append(members, CollectionMemberInfo{
+            UserID: row.UserID,
+            Name:   row.User.Name,
+            Email:  row.User.Email,
+            Role:   row.Role,
+        })
+    }
+
+    return members, nil
+}
+
+// InviteMember emails an invitation link. The recipient joins once they
+// accept it while logged in with that email address.
+func (s *CollectionService) InviteMember(collectionID, userID uint, req InviteMemberRequest) (*models.CollectionInvite, error) {
+    if collectionRank(req.Role) == 0 {
+        return nil, ErrInvalidCollectionRole
+    }
+
+    collection, _, err := s.authorize(collectionID, userID, models.CollectionOwner)
+    if err != nil {
+        return nil, err
+    }
+
+    email := strings.ToLower(strings.TrimSpace(req.Email))
+    var invitee models.User
+    if err := s.db.Where("LOWER(email) = ?", email).First(&invitee).Error; err == nil {
+        if invitee.ID == collection.UserID {
+            return nil, ErrAlreadyMember
+        }
+        var existing int64
+        if err := s.db.Model(&models.CollectionMember{}).
+            Where("collection_id = ? AND user_id = ?", collection.ID, invitee.ID).
+            Count(&existing).Error; err != nil {
+            return nil, err
+        }
+        if existing > 0 {
+            return nil, ErrAlreadyMember
+        }
+    }
+
+    var inviter models.User
+    if err := s.db.First(&inviter, userID).Error; err != nil {
+        return nil, err
+    }
+
+    token, tokenHash, err := generateOpaqueToken()
+    if err != nil {
+        return nil, err
+    }
+
+    invite := models.CollectionInvite{
+        CollectionID: collection.ID,
+        Email:        email,
+        Role:         req.Role,
+        TokenHash:    tokenHash,
+        InvitedByID:  userID,
+        ExpiresAt:    time.Now().Add(collectionInviteTTL),
+    }
+
+    // The invite only exists if its email went out, so a failed send
+    // leaves nothing behind that could be accepted or counted.
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        if err := checkInviteLimits(tx, collection.ID, userID); err != nil {
+            return err
+        }
+        if err := tx.Create(&invite).Error; err != nil {
+            return err
+        }
+
+        link := fmt.Sprintf("%s/collections/invite?token=%s", s.appURL, token)
+        return s.mailer.Send(mailer.Message{
+            To:      email,
+            Subject: fmt.Sprintf("%s shared \"%s\" with you on DevLink", inviter.Name, collection.Name),
+            Body: fmt.Sprintf("Hi,\n\n%s invited you to the DevLink collection \"%s\" as %s.\n"+
+                "Open the link below and log in with this email address to accept:\n\n%s\n\n"+
+                "The invitation expires in 7 days.\n", inviter.Name, collection.Name, req.Role, link),
+        })
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &invite, nil
+}
+
+// checkInviteLimits refuses another invite once the collection or the
+// inviter has sent too many within inviteWindow.
+func checkInviteLimits(tx *gorm.DB, collectionID, userID uint) error {
+    since := time.Now().Add(-inviteWindow)
+
+    var perCollection, perUser int64
+    if err := tx.Model(&models.CollectionInvite{}).
+        Where("collection_id = ? AND created_at > ?", collectionID, since).
+        Count(&perCollection).Error; err != nil {
+        return err
+    }
+    if err := tx.Model(&models.CollectionInvite{}).
+        Where("invited_by_id = ? AND created_at > ?", userID, since).
+        Count(&perUser).Error; err != nil {
+        return err
+    }
+    if perCollection >= maxInvitesPerCollection || perUser >= maxInvitesPerUser {
+        return ErrTooManyInvites
+    }
+    return nil
+}
+
+// GetInvites lists invitations that are still waiting to be accepted.
+func (s *CollectionService) GetInvites(collectionID, userID uint) ([]models.CollectionInvite, error) {
+    if _, _, err := s.authorize(collectionID, userID, models.CollectionOwner); err != nil {
+        return nil, err
+    }
+
+    var invites []models.CollectionInvite
+    if err := s.db.Where("collection_id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?",
+        collectionID, time.Now()).
+        Order("created_at DESC").
+        Find(&invites).Error; err != nil {
+        return nil, err
+    }
+    return invites, nil
+}
+
+func (s *CollectionService) RevokeInvite(collectionID, userID, inviteID uint) error {
+    if _, _, err := s.authorize(collectionID, userID, models.CollectionOwner); err != nil {
+        return err
+    }
+
+    result := s.db.Model(&models.CollectionInvite{}).
+        Where("id = ? AND collection_id = ? AND accepted_at IS NULL AND revoked_at IS NULL", inviteID, collectionID).
+        Update("revoked_at", time.Now())
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrCollectionInviteNotFound
+    }
+    return nil
+}
+
+// AcceptInvite adds the user to the invitation's collection. The invite
+// only works for the account with the address it was sent to.
+func (s *CollectionService) AcceptInvite(userID uint, token string) (*models.Collection, error) {
+    var user models.User
+    if err := s.db.First(&user, userID).Error; err != nil {
+        return nil, errors.New("user not found")
+    }
+
+    var invite models.CollectionInvite
+    if err := s.db.Where("token_hash = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?",
+        hashToken(token), time.Now()).First(&invite).Error; err != nil {
+        return nil, ErrInvalidCollectionInvite
+    }
+    if !strings.EqualFold(invite.Email, user.Email) {
+        return nil, ErrInvalidCollectionInvite
+    }
+
+    var collection models.Collection
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.First(&collection, invite.CollectionID).Error; err != nil {
+            return ErrInvalidCollectionInvite
+        }
+
+        result := tx.Model(&models.CollectionInvite{}).
+            Where("id = ? AND accepted_at IS NULL", invite.ID).
+            Update("accepted_at", time.Now())
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrInvalidCollectionInvite
+        }
+
+        // The creator already owns it; anyone else gets the invited role
+        if collection.UserID == userID {
+            return nil
+        }
+        member := models.CollectionMember{
+            CollectionID: collection.ID,
+            UserID:       userID,
+            Role:         invite.Role,
+            InvitedByID:  invite.InvitedByID,
+        }
+        return tx.Where(models.CollectionMember{CollectionID: collection.ID, UserID: userID}).
+            Assign(models.CollectionMember{Role: invite.Role}).
+            FirstOrCreate(&member).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &collection, nil
+}
+
+func (s *CollectionService) UpdateMemberRole(collectionID, userID, memberUserID uint, role string) error {
+    if collectionRank(role) == 0 {
+        return ErrInvalidCollectionRole
+    }
+    if _, _, err := s.authorize(collectionID, userID, models.CollectionOwner); err != nil {
+        return err
+    }
+
+    result := s.db.Model(&models.CollectionMember{}).
+        Where("collection_id = ? AND user_id = ?", collectionID, memberUserID).
+        Update("role", role)
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrMemberNotFound
+    }
+    return nil
+}
+
+// RemoveMember takes a user's access away. Owners can remove anyone but
+// the creator, and every member can remove themselves.
+func (s *CollectionService) RemoveMember(collectionID, userID, memberUserID uint) error {
+    minRole := models.CollectionOwner
+    if memberUserID == userID {
+        minRole = models.CollectionViewer
+    }
+    if _, _, err := s.authorize(collectionID, userID, minRole); err != nil {
+        return err
+    }
+
+    result := s.db.Where("collection_id = ? AND user_id = ?", collectionID, memberUserID).
+        Delete(&models.CollectionMember{})
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrMemberNotFound
+    }
+    return nil
+}
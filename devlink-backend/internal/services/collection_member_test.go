@@ -0,0 +1,90 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "devlink-backend/internal/mailer"
+    "devlink-backend/internal/models"
+)
+
+type failingMailer struct{}
+
+func (failingMailer) Send(mailer.Message) error {
+    return errors.New("smtp: connection refused")
+}
+
+func TestInviteMemberNeedsTheEmailToGoOut(t *testing.T) {
+    db := newTestDB(t)
+    owner := createTestUser(t, db, "owner@example.com", true)
+    collection, err := NewCollectionService(db, &testMailer{}, "http://app.test").
+        CreateCollection(owner.ID, CreateCollectionRequest{Name: "Reading list"})
+    if err != nil {
+        t.Fatalf("CreateCollection: %v", err)
+    }
+
+    collections := NewCollectionService(db, failingMailer{}, "http://app.test")
+    if _, err := collections.InviteMember(collection.ID, owner.ID, InviteMemberRequest{Email: "friend@example.com", Role: models.CollectionViewer}); err == nil {
+        t.Fatal("InviteMember succeeded although the email could not be sent")
+    }
+
+    var invites int64
+    db.Model(&models.CollectionInvite{}).Count(&invites)
+    if invites != 0 {
+        t.Errorf("%d invites saved without an email, want 0", invites)
+    }
+}
+
+func TestInviteMemberIsThrottled(t *testing.T) {
+    db := newTestDB(t)
+    owner := createTestUser(t, db, "owner@example.com", true)
+    mail := &testMailer{}
+    collections := NewCollectionService(db, mail, "http://app.test")
+
+    first, err := collections.CreateCollection(owner.ID, CreateCollectionRequest{Name: "Reading list"})
+    if err != nil {
+        t.Fatalf("CreateCollection: %v", err)
+    }
+    invite := func(collectionID uint, n int) error {
+        _, err := collections.InviteMember(collectionID, owner.ID, InviteMemberRequest{
+            Email: fmt.Sprintf("friend%d@example.com", n),
+            Role:  models.CollectionViewer,
+        })
+        return err
+    }
+
+    for n := 0; n < maxInvitesPerCollection; n++ {
+        if err := invite(first.ID, n); err != nil {
+            t.Fatalf("invite %d: %v", n, err)
+        }
+    }
+    if err := invite(first.ID, maxInvitesPerCollection); !errors.Is(err, ErrTooManyInvites) {
+        t.Fatalf("invite over the collection limit: err = %v, want ErrTooManyInvites", err)
+    }
+
+    // Spreading invites over more collections hits the per-user cap
+    sent := maxInvitesPerCollection
+    for sent < maxInvitesPerUser {
+        collection, err := collections.CreateCollection(owner.ID, CreateCollectionRequest{Name: fmt.Sprintf("List %d", sent)})
+        if err != nil {
+            t.Fatalf("CreateCollection: %v", err)
+        }
+        for n := 0; n < maxInvitesPerCollection && sent < maxInvitesPerUser; n++ {
+            if err := invite(collection.ID, sent); err != nil {
+                t.Fatalf("invite %d: %v", sent, err)
+            }
+            sent++
+        }
+    }
+    other, err := collections.CreateCollection(owner.ID, CreateCollectionRequest{Name: "One more"})
+    if err != nil {
+        t.Fatalf("CreateCollection: %v", err)
+    }
+    if err := invite(other.ID, sent); !errors.Is(err, ErrTooManyInvites) {
+        t.Errorf("invite over the user limit: err = %v, want ErrTooManyInvites", err)
+    }
+    if len(mail.sent) != maxInvitesPerUser {
+        t.Errorf("sent %d emails, want %d", len(mail.sent), maxInvitesPerUser)
+    }
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+    "context"
+    "io"
+    "log"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/webpage"
+    "gorm.io/gorm"
+)
+
+const linkCheckUserAgent = "DevLinkBot/1.0 (+link checker)"
+
+// LinkCheckPolicy controls how often and how politely links are checked.
+type LinkCheckPolicy struct {
+    RecheckAfter time.Duration // how old a check must be before the link is checked again
+    HostDelay    time.Duration // minimum gap between two requests to the same host
+    BatchSize    int           // links checked per run
+    Concurrency  int           // hosts checked in parallel
+    BrokenAfter  int           // consecutive failures before a link counts as broken
+    Timeout      time.Duration // per request
+}
+
+func DefaultLinkCheckPolicy() LinkCheckPolicy {
+    return LinkCheckPolicy{
+        RecheckAfter: 24 * time.Hour,
+        HostDelay:    2 * time.Second,
+        BatchSize:    500,
+        Concurrency:  8,
+        BrokenAfter:  2,
+        Timeout:      10 * time.Second,
+    }
+}
+
+// LinkChecker periodically requests every resource URL and records whether
+// it still works, where it redirects to, and how often it has failed.
+type LinkChecker struct {
+    db     *gorm.DB
+    client *http.Client
+    policy LinkCheckPolicy
+
+    mu       sync.Mutex
+    lastSeen map[string]time.Time // host -> time of the last request
+}
+
+// linkResult is the outcome of checking one URL.
+type linkResult struct {
+    statusCode int
+    finalURL   string
+    ok         bool
+    throttled  bool // the host asked us to slow down; not the link's fault
+}
+
+// NewLinkChecker uses client for its requests, or a client that only
+// reaches public addresses when client is nil.
+func NewLinkChecker(db *gorm.DB, client *http.Client, policy LinkCheckPolicy) *LinkChecker {
+    if client == nil {
+        client = webpage.NewSafeClient(policy.Timeout)
+    }
+    return &LinkChecker{
+        db:       db,
+        client:   client,
+        policy:   policy,
+        lastSeen: make(map[string]time.Time),
+    }
+}
+
+// Run checks a batch of due links every interval until ctx is cancelled.
+func (c *LinkChecker) Run(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if checked, err := c.CheckDue(ctx); err != nil {
+            log.Printf("Link check failed: %v", err)
+        } else if checked > 0 {
+            log.Printf("Checked %d link(s)", checked)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// CheckDue checks the links that were never checked or whose last check is
+// older than RecheckAfter, oldest first. Links on the same host are
+// checked one after another with HostDelay between them.
+func (c *LinkChecker) CheckDue(ctx context.Context) (int, error) {
+    var resources []models.Resource
+    if err := c.db.Select("id", "url", "link_failures").
+        Where("link_checked_at IS NULL OR link_checked_at < ?", time.Now().Add(-c.policy.RecheckAfter)).
+        Order("link_checked_at NULLS FIRST, id").
+        Limit(c.policy.BatchSize).
+        Find(&resources).Error; err != nil {
+        return 0, err
+    }
+
+    byHost := make(map[string][]models.Resource)
+    for _, resource := range resources {
+        host := ""
+        if u, err := url.Parse(resource.URL); err == nil {
+            host = strings.ToLower(u.Hostname())
+        }
+        byHost[host] = append(byHost[host], resource)
+    }
+
+    var wg sync.WaitGroup
+    slots := make(chan struct{}, c.policy.Concurrency)
+    for host, queue := range byHost {
+        wg.Add(1)
+        go func(host string, queue []models.Resource) {
+            defer wg.Done()
+            slots <- struct{}{}
+            defer func() { <-slots }()
+
+            for _, resource := range queue {
+                if !c.waitForHost(ctx, host) {
+                    return
+                }
+                if err := c.record(resource, c.check(ctx, resource.URL)); err != nil {
+                    log.Printf("Failed to record link check for resource %d: %v", resource.ID, err)
+                }
+            }
+        }(host, queue)
+    }
+    wg.Wait()
+
+    return len(resources), ctx.Err()
+}
+
+// waitForHost blocks until host may be contacted again and claims the slot.
+// It returns false if ctx is cancelled first.
+func (c *LinkChecker) waitForHost(ctx context.Context, host string) bool {
+    c.mu.Lock()
+    wait := time.Until(c.lastSeen[host].Add(c.policy.HostDelay))
+    if wait < 0 {
+        wait = 0
+    }
+    c.lastSeen[host] = time.Now().Add(wait)
+    c.mu.Unlock()
+
+    if wait == 0 {
+        return ctx.Err() == nil
+    }
+    timer := time.NewTimer(wait)
+    defer timer.Stop()
+    select {
+    case <-ctx.Done():
+        return false
+    case <-timer.C:
+        return true
+    }
+}
+
+// check tries a HEAD request first and falls back to GET for servers that
+// don't support HEAD properly.
+func (c *LinkChecker) check(ctx context.Context, rawURL string) linkResult {
+    result := c.request(ctx, http.MethodHead, rawURL)
+    if !result.ok && !result.throttled {
+        result = c.request(ctx, http.MethodGet, rawURL)
+    }
+    return result
+}
+
+func (c *LinkChecker) request(ctx context.Context, method, rawURL string) linkResult {
+    ctx, cancel := context.WithTimeout(ctx, c.policy.Timeout)
+    defer cancel()
+
+    req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+    if err != nil {
+        return linkResult{}
+    }
+    req.Header.Set("User-Agent", linkCheckUserAgent)
+
+    resp, err := c.client.Do(req)
+    if err != nil {
+        return linkResult{}
+    }
+    defer resp.Body.Close()
+    // Drain a little so the connection can be reused, but never the whole page
+    io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+    return linkResult{
+        statusCode: resp.StatusCode,
+        finalURL:   resp.Request.URL.String(),
+        ok:         resp.StatusCode < 400,
+        throttled:  resp.StatusCode == http.StatusTooManyRequests,
+    }
+}
+
+// record stores a check's outcome. A single failure keeps the previous
+// status so one hiccup doesn't flag a link as broken.
+func (c *LinkChecker) record(resource models.Resource, result linkResult) error {
+    // A throttled check says nothing about the link. Leaving it untouched
+    // keeps it due, so the next round tries again instead of waiting out
+    // RecheckAfter.
+    if result.throttled {
+        return nil
+    }
+
+    updates := map[string]interface{}{
+        "link_checked_at":  time.Now(),
+        "link_status_code": result.statusCode,
+    }
+
+    switch {
+    case result.ok:
+        updates["link_failures"] = 0
+        updates["link_final_url"] = result.finalURL
+        if !sameURL(result.finalURL, resource.URL) {
+            updates["link_status"] = models.LinkRedirected
+        } else {
+            updates["link_status"] = models.LinkOK
+        }
+    default:
+        failures := resource.LinkFailures + 1
+        updates["link_failures"] = failures
+        if failures >= c.policy.BrokenAfter {
+            updates["link_status"] = models.LinkBroken
+        }
+    }
+
+    // Skip the update when the URL was edited while the check was running
+    return c.db.Model(&models.Resource{}).
+        Where("id = ? AND url = ?", resource.ID, resource.URL).
+        UpdateColumns(updates).Error
+}
+
+// sameURL ignores the difference a trailing slash makes, which servers
+// add or remove without the link having moved.
+func sameURL(a, b string) bool {
+    return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
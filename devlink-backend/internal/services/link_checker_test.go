@@ -0,0 +1,73 @@
+package services
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "devlink-backend/internal/models"
+)
+
+func TestLinkCheckerLeavesThrottledLinksDue(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/ok":
+            w.WriteHeader(http.StatusOK)
+        case "/busy":
+            w.WriteHeader(http.StatusTooManyRequests)
+        default:
+            http.NotFound(w, r)
+        }
+    }))
+    defer server.Close()
+
+    db := newTestDB(t)
+    user := createTestUser(t, db, "user@example.com", true)
+    checkedAt := time.Now().Add(-48 * time.Hour)
+    resources := map[string]*models.Resource{}
+    for _, path := range []string{"/ok", "/busy", "/gone"} {
+        resource := &models.Resource{
+            Title:          path,
+            URL:            server.URL + path,
+            UserID:         user.ID,
+            LinkStatus:     models.LinkOK,
+            LinkStatusCode: http.StatusOK,
+            LinkCheckedAt:  &checkedAt,
+        }
+        if err := db.Create(resource).Error; err != nil {
+            t.Fatalf("create resource: %v", err)
+        }
+        resources[path] = resource
+    }
+
+    policy := DefaultLinkCheckPolicy()
+    policy.HostDelay = 0
+    checker := NewLinkChecker(db, server.Client(), policy)
+
+    if checked, err := checker.CheckDue(context.Background()); err != nil || checked != 3 {
+        t.Fatalf("CheckDue = %d, %v; want 3 links checked", checked, err)
+    }
+
+    reload := func(path string) models.Resource {
+        var resource models.Resource
+        db.First(&resource, resources[path].ID)
+        return resource
+    }
+    if busy := reload("/busy"); !busy.LinkCheckedAt.Equal(checkedAt) || busy.LinkStatusCode != http.StatusOK ||
+        busy.LinkStatus != models.LinkOK || busy.LinkFailures != 0 {
+        t.Errorf("throttled link was recorded: %+v", busy)
+    }
+    if ok := reload("/ok"); !ok.LinkCheckedAt.After(checkedAt) {
+        t.Error("working link's check time was not updated")
+    }
+    if gone := reload("/gone"); gone.LinkFailures != 1 || gone.LinkStatusCode != http.StatusNotFound {
+        t.Errorf("missing link recorded %d failures with status %d, want 1 and 404", gone.LinkFailures, gone.LinkStatusCode)
+    }
+
+    // Only the throttled link is still due
+    if checked, err := checker.CheckDue(context.Background()); err != nil || checked != 1 {
+        t.Errorf("second CheckDue = %d, %v; want the throttled link again", checked, err)
+    }
+}
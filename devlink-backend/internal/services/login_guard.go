@@ -0,0 +1,200 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "math"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/mailer"
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/throttle"
+    "gorm.io/gorm"
+)
+
+// LoginGuardPolicy controls how quickly failed logins are slowed down.
+type LoginGuardPolicy struct {
+    FreeAttempts    int           // failures before any delay kicks in
+    BaseDelay       time.Duration // first delay, doubled with every further failure
+    MaxDelay        time.Duration // ceiling for the exponential delay
+    AccountLockout  int           // failures on one account before it is locked
+    IPLockout       int           // failures from one IP before it is locked
+    LockoutDuration time.Duration
+    Window          time.Duration // how long failures are remembered
+}
+
+func DefaultLoginGuardPolicy() LoginGuardPolicy {
+    return LoginGuardPolicy{
+        FreeAttempts:    3,
+        BaseDelay:       time.Second,
+        MaxDelay:        5 * time.Minute,
+        AccountLockout:  10,
+        IPLockout:       50,
+        LockoutDuration: 15 * time.Minute,
+        Window:          time.Hour,
+    }
+}
+
+// LockedOutError tells the caller when another attempt will be accepted.
+type LockedOutError struct {
+    RetryAfter time.Duration
+}
+
+func (e *LockedOutError) Error() string {
+    return fmt.Sprintf("too many failed attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginGuard throttles password logins per client IP and per account.
+type LoginGuard struct {
+    store  throttle.Store
+    policy LoginGuardPolicy
+    db     *gorm.DB
+    mailer mailer.Mailer
+    scope  string // key prefix, so each guard sharing a store counts separately
+}
+
+func NewLoginGuard(store throttle.Store, policy LoginGuardPolicy, db *gorm.DB, mailer mailer.Mailer) *LoginGuard {
+    return &LoginGuard{
+        store:  store,
+        policy: policy,
+        db:     db,
+        mailer: mailer,
+        scope:  "login",
+    }
+}
+
+// NewShareLinkGuard throttles passwords of share links the same way, per
+// client IP and per link. Its counters are separate from the login ones
+// and nobody is notified of a lockout.
+func NewShareLinkGuard(store throttle.Store, policy LoginGuardPolicy) *LoginGuard {
+    return &LoginGuard{
+        store:  store,
+        policy: policy,
+        scope:  "share",
+    }
+}
+
+// Check returns a *LockedOutError if either the IP or the account is
+// currently blocked. Store failures are logged and let through, so an
+// unavailable Redis doesn't lock everybody out.
+func (g *LoginGuard) Check(ctx context.Context, ip, email string) error {
+    var longest time.Duration
+    for _, key := range []string{g.ipKey(ip), g.accountKey(email)} {
+        remaining, err := g.store.LockedFor(ctx, key)
+        if err != nil {
+            log.Printf("Login guard check failed: %v", err)
+            continue
+        }
+        if remaining > longest {
+            longest = remaining
+        }
+    }
+
+    if longest > 0 {
+        return &LockedOutError{RetryAfter: longest}
+    }
+    return nil
+}
+
+// RecordFailure counts a failed attempt and applies any resulting delay or
+// lockout. The returned error, if any, is a *LockedOutError for the caller
+// to pass on.
+func (g *LoginGuard) RecordFailure(ctx context.Context, ip, email string) error {
+    var longest time.Duration
+
+    ipDelay, _ := g.fail(ctx, g.ipKey(ip), g.policy.IPLockout)
+    if ipDelay > longest {
+        longest = ipDelay
+    }
+
+    accountDelay, lockedNow := g.fail(ctx, g.accountKey(email), g.policy.AccountLockout)
+    if accountDelay > longest {
+        longest = accountDelay
+    }
+    if lockedNow {
+        g.notifyLockout(email, ip)
+    }
+
+    if longest > 0 {
+        return &LockedOutError{RetryAfter: longest}
+    }
+    return nil
+}
+
+// RecordSuccess clears the account's failures. The IP counter is left to
+// expire so one valid login can't reset an attacker's budget.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, email string) {
+    if err := g.store.Reset(ctx, g.accountKey(email)); err != nil {
+        log.Printf("Login guard reset failed: %v", err)
+    }
+}
+
+// fail increments key and locks it for the delay the new count calls for.
+// lockedNow is true only on the attempt that crosses the lockout threshold.
+func (g *LoginGuard) fail(ctx context.Context, key string, lockout int) (time.Duration, bool) {
+    count, err := g.store.Incr(ctx, key, g.policy.Window)
+    if err != nil {
+        log.Printf("Login guard update failed: %v", err)
+        return 0, false
+    }
+
+    delay := g.delayFor(int(count), lockout)
+    if delay == 0 {
+        return 0, false
+    }
+
+    if err := g.store.Lock(ctx, key, delay); err != nil {
+        log.Printf("Login guard lock failed: %v", err)
+        return 0, false
+    }
+
+    return delay, int(count) == lockout
+}
+
+func (g *LoginGuard) delayFor(count, lockout int) time.Duration {
+    if count >= lockout {
+        return g.policy.LockoutDuration
+    }
+    if count <= g.policy.FreeAttempts {
+        return 0
+    }
+
+    delay := time.Duration(float64(g.policy.BaseDelay) * math.Pow(2, float64(count-g.policy.FreeAttempts-1)))
+    if delay > g.policy.MaxDelay {
+        return g.policy.MaxDelay
+    }
+    return delay
+}
+
+func (g *LoginGuard) notifyLockout(email, ip string) {
+    if g.mailer == nil {
+        return
+    }
+
+    var user models.User
+    if err := g.db.Where("email = ?", email).First(&user).Error; err != nil {
+        return
+    }
+
+    err := g.mailer.Send(mailer.Message{
+        To:      user.Email,
+        Subject: "Your DevLink account was temporarily locked",
+        Body: fmt.Sprintf("Hi %s,\n\nWe locked your DevLink account for %s after %d failed login attempts. "+
+            "The last attempt came from %s.\n\nIf this was you, wait and try again. If not, consider "+
+            "resetting your password and enabling two-factor authentication.\n",
+            user.Name, g.policy.LockoutDuration, g.policy.AccountLockout, ip),
+    })
+    if err != nil {
+        log.Printf("Failed to send lockout notification to user %d: %v", user.ID, err)
+    }
+}
+
+func (g *LoginGuard) ipKey(ip string) string {
+    return g.scope + ":ip:" + ip
+}
+
+func (g *LoginGuard) accountKey(email string) string {
+    return g.scope + ":account:" + strings.ToLower(strings.TrimSpace(email))
+}
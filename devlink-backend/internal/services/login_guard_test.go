@@ -0,0 +1,122 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "devlink-backend/internal/throttle"
+    "github.com/alicebob/miniredis/v2"
+    "github.com/redis/go-redis/v9"
+)
+
+func testLoginGuardPolicy() LoginGuardPolicy {
+    return LoginGuardPolicy{
+        FreeAttempts:    2,
+        BaseDelay:       time.Second,
+        MaxDelay:        4 * time.Second,
+        AccountLockout:  7,
+        IPLockout:       100,
+        LockoutDuration: 15 * time.Minute,
+        Window:          time.Hour,
+    }
+}
+
+func newTestRedisStore(t *testing.T, server *miniredis.Miniredis) throttle.Store {
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+    return throttle.NewRedisStore(client, "devlink:")
+}
+
+func retryAfter(err error) time.Duration {
+    var locked *LockedOutError
+    if errors.As(err, &locked) {
+        return locked.RetryAfter
+    }
+    return 0
+}
+
+func TestLoginGuardBackoffAndLockout(t *testing.T) {
+    db := newTestDB(t)
+    mail := &testMailer{}
+    createTestUser(t, db, "user@example.com", true)
+    guard := NewLoginGuard(newTestRedisStore(t, miniredis.RunT(t)), testLoginGuardPolicy(), db, mail)
+    ctx := context.Background()
+
+    // Two free attempts, then doubling delays capped at MaxDelay, then the lockout
+    want := []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second, 15 * time.Minute}
+    for i, delay := range want {
+        got := retryAfter(guard.RecordFailure(ctx, "203.0.113.7", "user@example.com"))
+        if got < delay-time.Second/10 || got > delay {
+            t.Errorf("failure %d: retry after %s, want %s", i+1, got, delay)
+        }
+    }
+
+    if got := retryAfter(guard.Check(ctx, "198.51.100.1", "User@Example.com")); got < 14*time.Minute {
+        t.Errorf("locked account from another IP: retry after %s, want the lockout", got)
+    }
+    if len(mail.sent) != 1 || mail.sent[0].To != "user@example.com" {
+        t.Errorf("sent %d lockout emails, want 1 to the account owner", len(mail.sent))
+    }
+}
+
+func TestLoginGuardResetOnSuccess(t *testing.T) {
+    db := newTestDB(t)
+    guard := NewLoginGuard(newTestRedisStore(t, miniredis.RunT(t)), testLoginGuardPolicy(), db, &testMailer{})
+    ctx := context.Background()
+
+    for i := 0; i < 4; i++ {
+        guard.RecordFailure(ctx, "203.0.113.7", "user@example.com")
+    }
+    guard.RecordSuccess(ctx, "user@example.com")
+
+    if err := guard.Check(ctx, "198.51.100.1", "user@example.com"); err != nil {
+        t.Errorf("account still throttled after a successful login: %v", err)
+    }
+    if err := guard.RecordFailure(ctx, "198.51.100.1", "user@example.com"); err != nil {
+        t.Errorf("first failure after a success was delayed: %v", err)
+    }
+
+    // The IP keeps its count, one valid login must not clear an attacker's record
+    if err := guard.Check(ctx, "203.0.113.7", "someone@example.com"); err == nil {
+        t.Error("a successful login reset the IP's failures")
+    }
+}
+
+func TestLoginGuardWindow(t *testing.T) {
+    server := miniredis.RunT(t)
+    guard := NewLoginGuard(newTestRedisStore(t, server), testLoginGuardPolicy(), newTestDB(t), &testMailer{})
+    ctx := context.Background()
+
+    for i := 0; i < 2; i++ {
+        guard.RecordFailure(ctx, "203.0.113.7", "user@example.com")
+    }
+    server.FastForward(time.Hour + time.Second)
+
+    if err := guard.RecordFailure(ctx, "203.0.113.7", "user@example.com"); err != nil {
+        t.Errorf("failures older than the window still counted: %v", err)
+    }
+}
+
+func TestLoginGuardSharedAcrossInstances(t *testing.T) {
+    server := miniredis.RunT(t)
+    db := newTestDB(t)
+    first := NewLoginGuard(newTestRedisStore(t, server), testLoginGuardPolicy(), db, &testMailer{})
+    second := NewLoginGuard(newTestRedisStore(t, server), testLoginGuardPolicy(), db, &testMailer{})
+    ctx := context.Background()
+
+    // An attacker spreading attempts over instances still hits the lockout
+    for i := 0; i < 7; i++ {
+        guard := first
+        if i%2 == 1 {
+            guard = second
+        }
+        guard.RecordFailure(ctx, "203.0.113.7", "user@example.com")
+    }
+    for _, guard := range []*LoginGuard{first, second} {
+        if got := retryAfter(guard.Check(ctx, "198.51.100.1", "user@example.com")); got < 14*time.Minute {
+            t.Errorf("retry after %s, want the lockout on every instance", got)
+        }
+    }
+}
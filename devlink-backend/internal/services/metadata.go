@@ -0,0 +1,52 @@
+package services
+
+import (
+    "context"
+    "errors"
+
+    "devlink-backend/internal/webpage"
+)
+
+var ErrPreviewUnavailable = errors.New("link previews are not available")
+
+// MetadataSource looks up what a page says about itself. ResourceService
+// uses it for link previews and to fill in details left out on create.
+type MetadataSource interface {
+    Fetch(ctx context.Context, rawURL string) (*webpage.Metadata, error)
+}
+
+type PreviewRequest struct {
+    URL string `json:"url" binding:"required,url"`
+}
+
+// PreviewURL fetches a page's title, description, image and favicon so the
+// frontend can prefill the new resource form.
+func (s *ResourceService) PreviewURL(ctx context.Context, rawURL string) (*webpage.Metadata, error) {
+    if s.metadata == nil {
+        return nil, ErrPreviewUnavailable
+    }
+    return s.metadata.Fetch(ctx, rawURL)
+}
+
+// prefill fills in the title, description and favicon the user left
+// empty. Fetching is best effort: when the page can't be read the
+// resource is still created, titled with its URL if need be.
+func (s *ResourceService) prefill(ctx context.Context, req *CreateResourceRequest) {
+    if s.metadata != nil && (req.Title == "" || req.Description == "" || req.FaviconURL == "") {
+        if meta, err := s.metadata.Fetch(ctx, req.URL); err == nil {
+            if req.Title == "" {
+                req.Title = meta.Title
+            }
+            if req.Description == "" {
+                req.Description = meta.Description
+            }
+            if req.FaviconURL == "" {
+                req.FaviconURL = meta.FaviconURL
+            }
+        }
+    }
+
+    if req.Title == "" {
+        req.Title = req.URL
+    }
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+    "crypto/rand"
+    "encoding/base32"
+    "errors"
+    "log"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/totp"
+    "golang.org/x/crypto/bcrypt"
+    "gorm.io/gorm"
+)
+
+const (
+    mfaChallengeTTL   = 5 * time.Minute
+    mfaMaxAttempts    = 5
+    recoveryCodeCount = 10
+    totpIssuer        = "DevLink"
+)
+
+var (
+    ErrInvalidMFAChallenge = errors.New("login challenge is invalid or has expired, please log in again")
+    ErrInvalidMFACode      = errors.New("invalid authentication code")
+    ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+    ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+    ErrMFANotSetUp         = errors.New("start two-factor setup before enabling it")
+    ErrInvalidPassword     = errors.New("current password is incorrect")
+)
+
+// LoginResult is the outcome of the first login step. Accounts with 2FA get
+// an MFA token to exchange via VerifyMFA instead of a token pair.
+type LoginResult struct {
+    User     *models.User
+    Tokens   *TokenPair
+    MFAToken string
+}
+
+// TOTPEnrollment is what the user needs to add DevLink to an authenticator app.
+type TOTPEnrollment struct {
+    Secret string
+    URI    string
+}
+
+// loginUser finishes a login for a user whose first factor already checked out.
+func (s *AuthService) loginUser(user *models.User, client ClientInfo) (*LoginResult, error) {
+    if user.DisabledAt != nil {
+        return nil, ErrAccountDisabled
+    }
+    if s.requiresVerifiedEmail(user) {
+        // The link from registration may be lost or expired
+        if err := s.sendVerificationLink(user, user.Email, ""); err != nil {
+            log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+        }
+        return nil, ErrUnverifiedLogin
+    }
+
+    if user.TOTPEnabledAt != nil {
+        token, err := s.createMFAChallenge(user.ID)
+        if err != nil {
+            return nil, err
+        }
+        return &LoginResult{User: user, MFAToken: token}, nil
+    }
+
+    tokens, err := s.StartSession(user, client)
+    if err != nil {
+        return nil, err
+    }
+    return &LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// MFAChallengeEmail returns the email of the account an open login
+// challenge belongs to, so second-factor guesses can be throttled together
+// with the account's password attempts.
+func (s *AuthService) MFAChallengeEmail(mfaToken string) (string, error) {
+    var user models.User
+    if err := s.db.Joins("JOIN mfa_challenges ON mfa_challenges.user_id = users.id").
+        Where("mfa_challenges.token_hash = ? AND mfa_challenges.used_at IS NULL AND mfa_challenges.expires_at > ?", hashToken(mfaToken), time.Now()).
+        First(&user).Error; err != nil {
+        return "", ErrInvalidMFAChallenge
+    }
+    return user.Email, nil
+}
+
+// VerifyMFA completes a two-step login with a TOTP or recovery code.
+func (s *AuthService) VerifyMFA(mfaToken, code string, client ClientInfo) (*LoginResult, error) {
+    var challenge models.MFAChallenge
+    if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(mfaToken), time.Now()).
+        First(&challenge).Error; err != nil {
+        return nil, ErrInvalidMFAChallenge
+    }
+
+    // Count the attempt before checking the code so guesses are capped
+    result := s.db.Model(&models.MFAChallenge{}).
+        Where("id = ? AND attempts < ?", challenge.ID, mfaMaxAttempts).
+        Update("attempts", gorm.Expr("attempts + 1"))
+    if result.Error != nil {
+        return nil, result.Error
+    }
+    if result.RowsAffected == 0 {
+        return nil, ErrInvalidMFAChallenge
+    }
+
+    var user models.User
+    if err := s.db.First(&user, challenge.UserID).Error; err != nil {
+        return nil, ErrInvalidMFAChallenge
+    }
+    if user.DisabledAt != nil {
+        return nil, ErrAccountDisabled
+    }
+
+    if err := s.checkSecondFactor(&user, code); err != nil {
+        return nil, err
+    }
+
+    result = s.db.Model(&models.MFAChallenge{}).
+        Where("id = ? AND used_at IS NULL", challenge.ID).
+        Update("used_at", time.Now())
+    if result.Error != nil {
+        return nil, result.Error
+    }
+    if result.RowsAffected == 0 {
+        return nil, ErrInvalidMFAChallenge
+    }
+
+    tokens, err := s.StartSession(&user, client)
+    if err != nil {
+        return nil, err
+    }
+    return &LoginResult{User: &user, Tokens: tokens}, nil
+}
+
+// SetupTOTP generates a new secret for the user. It is not enforced until
+// EnableTOTP confirms the user's app produces matching codes.
+func (s *AuthService) SetupTOTP(userID uint) (*TOTPEnrollment, error) {
+    var user models.User
+    if err := s.db.First(&user, userID).Error; err != nil {
+        return nil, errors.New("user not found")
+    }
+    if user.TOTPEnabledAt != nil {
+        return nil, ErrMFAAlreadyEnabled
+    }
+
+    secret, err := totp.GenerateSecret()
+    if err != nil {
+        return nil, err
+    }
+    if err := s.db.Model(&user).Update("totp_secret", secret).Error; err != nil {
+        return nil, err
+    }
+
+    return &TOTPEnrollment{
+        Secret: secret,
+        URI:    totp.URI(totpIssuer, user.Email, secret),
+    }, nil
+}
+
+// EnableTOTP turns on 2FA once the user proves their app is set up, and
+// returns a fresh set of recovery codes. They are only ever shown here.
+func (s *AuthService) EnableTOTP(userID uint, code string) ([]string, error) {
+    var user models.User
+    if err := s.db.First(&user, userID).Error; err != nil {
+        return nil, errors.New("user not found")
+    }
+    if user.TOTPEnabledAt != nil {
+        return nil, ErrMFAAlreadyEnabled
+    }
+    if user.TOTPSecret == "" {
+        return nil, ErrMFANotSetUp
+    }
+
+    step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+    if !ok {
+        return nil, ErrInvalidMFACode
+    }
+
+    var codes []string
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(&user).Updates(map[string]interface{}{
+            "totp_enabled_at": time.Now(),
+            "totp_last_step":  step,
+        }).Error; err != nil {
+            return err
+        }
+
+        var err error
+        codes, err = replaceRecoveryCodes(tx, user.ID)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return codes, nil
+}
+
+// DisableTOTP turns 2FA off. The caller must re-authenticate with both the
+// account password and a current TOTP or recovery code.
+func (s *AuthService) DisableTOTP(userID uint, password, code string) error {
+    user, err := s.reauthenticate(userID, password, code)
+    if err != nil {
+        return err
+    }
+
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(user).Updates(map[string]interface{}{
+            "totp_secret":     "",
+            "totp_enabled_at": nil,
+            "totp_last_step":  0,
+        }).Error; err != nil {
+            return err
+        }
+
+        return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+    })
+}
+
+// RegenerateRecoveryCodes invalidates the old recovery codes and issues new ones.
+func (s *AuthService) RegenerateRecoveryCodes(userID uint, password, code string) ([]string, error) {
+    user, err := s.reauthenticate(userID, password, code)
+    if err != nil {
+        return nil, err
+    }
+
+    var codes []string
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        var err error
+        codes, err = replaceRecoveryCodes(tx, user.ID)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return codes, nil
+}
+
+func (s *AuthService) reauthenticate(userID uint, password, code string) (*models.User, error) {
+    var user models.User
+    if err := s.db.First(&user, userID).Error; err != nil {
+        return nil, errors.New("user not found")
+    }
+    if user.TOTPEnabledAt == nil {
+        return nil, ErrMFANotEnabled
+    }
+
+    if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+        return nil, ErrInvalidPassword
+    }
+
+    if err := s.checkSecondFactor(&user, code); err != nil {
+        return nil, err
+    }
+
+    return &user, nil
+}
+
+// checkSecondFactor accepts either a TOTP code or an unused recovery code.
+func (s *AuthService) checkSecondFactor(user *models.User, code string) error {
+    if step, ok := totp.Validate(user.TOTPSecret, code, time.Now()); ok {
+        // Each time step can only be used once
+        result := s.db.Model(&models.User{}).
+            Where("id = ? AND totp_last_step < ?", user.ID, step).
+            Update("totp_last_step", step)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrInvalidMFACode
+        }
+        return nil
+    }
+
+    result := s.db.Model(&models.RecoveryCode{}).
+        Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalizeRecoveryCode(code))).
+        Update("used_at", time.Now())
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrInvalidMFACode
+    }
+    return nil
+}
+
+func (s *AuthService) createMFAChallenge(userID uint) (string, error) {
+    token, tokenHash, err := generateOpaqueToken()
+    if err != nil {
+        return "", err
+    }
+
+    challenge := models.MFAChallenge{
+        UserID:    userID,
+        TokenHash: tokenHash,
+        ExpiresAt: time.Now().Add(mfaChallengeTTL),
+    }
+    if err := s.db.Create(&challenge).Error; err != nil {
+        return "", err
+    }
+
+    return token, nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+    if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+        return nil, err
+    }
+
+    codes := make([]string, 0, recoveryCodeCount)
+    records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+    for i := 0; i < recoveryCodeCount; i++ {
+        code, err := newRecoveryCode()
+        if err != nil {
+            return nil, err
+        }
+        codes = append(codes, code)
+        records = append(records, models.RecoveryCode{
+            UserID:   userID,
+            CodeHash: hashToken(normalizeRecoveryCode(code)),
+        })
+    }
+
+    if err := tx.Create(&records).Error; err != nil {
+        return nil, err
+    }
+    return codes, nil
+}
+
+// newRecoveryCode returns 80 random bits formatted as xxxx-xxxx-xxxx-xxxx.
+func newRecoveryCode() (string, error) {
+    buf := make([]byte, 10)
+    if _, err := rand.Read(buf); err != nil {
+        return "", err
+    }
+
+    raw := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+    return raw[0:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:16], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+    code = strings.ToLower(code)
+    return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "devlink-backend/internal/totp"
+)
+
+func TestCheckSecondFactorRejectsReplay(t *testing.T) {
+    db := newTestDB(t)
+    authService, _ := newTestAuthService(db)
+    user := createTestUser(t, db, "user@example.com", true)
+
+    secret, err := totp.GenerateSecret()
+    if err != nil {
+        t.Fatalf("GenerateSecret: %v", err)
+    }
+    now := time.Now()
+    if err := db.Model(user).Updates(map[string]interface{}{"totp_secret": secret, "totp_enabled_at": now}).Error; err != nil {
+        t.Fatalf("enable 2FA: %v", err)
+    }
+
+    code, _ := totp.Code(secret, totp.Step(now))
+    if err := authService.checkSecondFactor(user, code); err != nil {
+        t.Fatalf("fresh code: %v", err)
+    }
+    if err := authService.checkSecondFactor(user, code); !errors.Is(err, ErrInvalidMFACode) {
+        t.Errorf("replayed code: err = %v, want ErrInvalidMFACode", err)
+    }
+
+    // An older step inside the window is refused once a newer one was used
+    previous, _ := totp.Code(secret, totp.Step(now)-1)
+    if err := authService.checkSecondFactor(user, previous); !errors.Is(err, ErrInvalidMFACode) {
+        t.Errorf("code of an earlier step: err = %v, want ErrInvalidMFACode", err)
+    }
+}
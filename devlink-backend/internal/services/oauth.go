@@ -0,0 +1,220 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "sort"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/oauth"
+    "golang.org/x/crypto/bcrypt"
+    "gorm.io/gorm"
+)
+
+const oauthStateTTL = 10 * time.Minute
+
+var (
+    ErrUnknownProvider      = errors.New("unknown login provider")
+    ErrInvalidOAuthState    = errors.New("login request expired or is invalid, please try again")
+    ErrUnverifiedOAuthEmail = errors.New("the provider did not return a verified email address")
+    ErrUnverifiedAccount    = errors.New("an account with this email exists but its address is not verified yet; sign in with your password and verify it first")
+)
+
+type OAuthService struct {
+    db          *gorm.DB
+    authService *AuthService
+    providers   map[string]oauth.Provider
+}
+
+func NewOAuthService(db *gorm.DB, authService *AuthService, providers ...oauth.Provider) *OAuthService {
+    byName := make(map[string]oauth.Provider, len(providers))
+    for _, provider := range providers {
+        byName[provider.Name()] = provider
+    }
+
+    return &OAuthService{
+        db:          db,
+        authService: authService,
+        providers:   byName,
+    }
+}
+
+// Providers lists the names of the configured providers.
+func (s *OAuthService) Providers() []string {
+    names := make([]string, 0, len(s.providers))
+    for name := range s.providers {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// StartLogin records a new authorization request and returns the provider
+// URL to send the browser to, plus the state value the callback must echo.
+func (s *OAuthService) StartLogin(ctx context.Context, providerName string) (string, string, error) {
+    provider, ok := s.providers[providerName]
+    if !ok {
+        return "", "", ErrUnknownProvider
+    }
+
+    state, err := oauth.RandomString(32)
+    if err != nil {
+        return "", "", err
+    }
+    verifier, err := oauth.NewCodeVerifier()
+    if err != nil {
+        return "", "", err
+    }
+    nonce, err := oauth.RandomString(16)
+    if err != nil {
+        return "", "", err
+    }
+
+    authURL, err := provider.AuthCodeURL(ctx, state, oauth.CodeChallengeS256(verifier), nonce)
+    if err != nil {
+        return "", "", err
+    }
+
+    // Clean up abandoned attempts while we're here
+    s.db.Where("expires_at < ?", time.Now()).Delete(&models.OAuthState{})
+
+    record := models.OAuthState{
+        StateHash:    hashToken(state),
+        Provider:     providerName,
+        CodeVerifier: verifier,
+        Nonce:        nonce,
+        ExpiresAt:    time.Now().Add(oauthStateTTL),
+    }
+    if err := s.db.Create(&record).Error; err != nil {
+        return "", "", err
+    }
+
+    return authURL, state, nil
+}
+
+// CompleteLogin validates the callback state, redeems the code and signs in
+// the matching user, linking or creating the account as needed.
+func (s *OAuthService) CompleteLogin(ctx context.Context, providerName, state, code string, client ClientInfo) (*LoginResult, error) {
+    provider, ok := s.providers[providerName]
+    if !ok {
+        return nil, ErrUnknownProvider
+    }
+
+    // States are single use: delete first so a replayed callback finds nothing
+    var record models.OAuthState
+    if err := s.db.Where("state_hash = ? AND provider = ?", hashToken(state), providerName).First(&record).Error; err != nil {
+        return nil, ErrInvalidOAuthState
+    }
+    result := s.db.Delete(&models.OAuthState{}, record.ID)
+    if result.Error != nil {
+        return nil, result.Error
+    }
+    if result.RowsAffected == 0 || time.Now().After(record.ExpiresAt) {
+        return nil, ErrInvalidOAuthState
+    }
+
+    info, err := provider.Exchange(ctx, code, record.CodeVerifier, record.Nonce)
+    if err != nil {
+        return nil, err
+    }
+
+    user, err := s.resolveUser(providerName, info)
+    if err != nil {
+        return nil, err
+    }
+
+    // Accounts with 2FA still need their second factor after a provider login
+    return s.authService.loginUser(user, client)
+}
+
+// resolveUser finds the user for a provider identity. Existing links win;
+// otherwise an account is only created for a verified provider email, and
+// only linked when the local account has verified the address too.
+// Anyone can register an unverified account for someone else's address,
+// so linking one would keep its password working for whoever did.
+func (s *OAuthService) resolveUser(providerName string, info *oauth.UserInfo) (*models.User, error) {
+    var user models.User
+
+    var identity models.UserIdentity
+    err := s.db.Where("provider = ? AND subject = ?", providerName, info.Subject).First(&identity).Error
+    if err == nil {
+        if err := s.db.First(&user, identity.UserID).Error; err != nil {
+            return nil, err
+        }
+        return &user, nil
+    }
+    if !errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, err
+    }
+
+    if info.Email == "" || !info.EmailVerified {
+        return nil, ErrUnverifiedOAuthEmail
+    }
+    email := strings.ToLower(info.Email)
+
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        err := tx.Where("LOWER(email) = ?", email).First(&user).Error
+        switch {
+        case err == nil:
+            if user.EmailVerifiedAt == nil {
+                return ErrUnverifiedAccount
+            }
+        case errors.Is(err, gorm.ErrRecordNotFound):
+            // Provider logins create accounts too, so they follow the same rules
+            if err := s.authService.checkRegistration(tx, email, ""); err != nil {
+                return err
+            }
+            created, err := newExternalUser(info, email)
+            if err != nil {
+                return err
+            }
+            if err := tx.Create(created).Error; err != nil {
+                return err
+            }
+            user = *created
+        default:
+            return err
+        }
+
+        return tx.Create(&models.UserIdentity{
+            UserID:   user.ID,
+            Provider: providerName,
+            Subject:  info.Subject,
+            Email:    email,
+        }).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &user, nil
+}
+
+// newExternalUser builds a user for a first-time provider login. The random
+// password is never shown; the user can set a real one via password reset.
+func newExternalUser(info *oauth.UserInfo, email string) (*models.User, error) {
+    secret, _, err := generateOpaqueToken()
+    if err != nil {
+        return nil, err
+    }
+    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+    if err != nil {
+        return nil, err
+    }
+
+    name := info.Name
+    if name == "" {
+        name = strings.Split(email, "@")[0]
+    }
+
+    now := time.Now()
+    return &models.User{
+        Name:            name,
+        Email:           email,
+        Password:        string(hashedPassword),
+        EmailVerifiedAt: &now,
+    }, nil
+}
+
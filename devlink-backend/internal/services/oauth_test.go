@@ -0,0 +1,198 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "net/url"
+    "testing"
+    "time"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/oauth"
+    "gorm.io/gorm"
+)
+
+// fakeProvider stands in for an identity provider. Like a real one it only
+// hands out the identity when the verifier matches the challenge it was
+// shown and echoes the nonce it was given.
+type fakeProvider struct {
+    info      oauth.UserInfo
+    challenge string
+    nonce     string
+}
+
+func (p *fakeProvider) Name() string {
+    return "fake"
+}
+
+func (p *fakeProvider) AuthCodeURL(ctx context.Context, state, codeChallenge, nonce string) (string, error) {
+    p.challenge, p.nonce = codeChallenge, nonce
+    return "https://idp.test/authorize?" + url.Values{"state": {state}}.Encode(), nil
+}
+
+func (p *fakeProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oauth.UserInfo, error) {
+    if oauth.CodeChallengeS256(codeVerifier) != p.challenge {
+        return nil, errors.New("code verifier does not match the challenge")
+    }
+    if nonce != p.nonce {
+        return nil, errors.New("nonce mismatch")
+    }
+    info := p.info
+    return &info, nil
+}
+
+func newTestOAuthService(t *testing.T, info oauth.UserInfo) (*OAuthService, *fakeProvider, *gorm.DB) {
+    t.Helper()
+    db := newTestDB(t)
+    auth, _ := newTestAuthService(db)
+    provider := &fakeProvider{info: info}
+    return NewOAuthService(db, auth, provider), provider, db
+}
+
+func startTestLogin(t *testing.T, s *OAuthService) string {
+    t.Helper()
+    _, state, err := s.StartLogin(context.Background(), "fake")
+    if err != nil {
+        t.Fatalf("StartLogin: %v", err)
+    }
+    return state
+}
+
+func TestOAuthLoginCreatesAccount(t *testing.T) {
+    s, _, db := newTestOAuthService(t, oauth.UserInfo{Subject: "sub-1", Email: "New@Example.com", EmailVerified: true, Name: "New"})
+
+    result, err := s.CompleteLogin(context.Background(), "fake", startTestLogin(t, s), "code", ClientInfo{})
+    if err != nil {
+        t.Fatalf("CompleteLogin: %v", err)
+    }
+    if result.Tokens == nil || result.Tokens.AccessToken == "" {
+        t.Fatal("expected tokens for a new account")
+    }
+    if result.User.Email != "new@example.com" || result.User.EmailVerifiedAt == nil {
+        t.Errorf("user = %s verified %v, want new@example.com verified", result.User.Email, result.User.EmailVerifiedAt)
+    }
+
+    var identity models.UserIdentity
+    if err := db.Where("provider = ? AND subject = ?", "fake", "sub-1").First(&identity).Error; err != nil {
+        t.Fatalf("identity not linked: %v", err)
+    }
+    if identity.UserID != result.User.ID {
+        t.Errorf("identity linked to user %d, want %d", identity.UserID, result.User.ID)
+    }
+}
+
+func TestOAuthLoginState(t *testing.T) {
+    s, _, db := newTestOAuthService(t, oauth.UserInfo{Subject: "sub-1", Email: "user@example.com", EmailVerified: true})
+    ctx := context.Background()
+
+    if _, err := s.CompleteLogin(ctx, "fake", "made-up", "code", ClientInfo{}); !errors.Is(err, ErrInvalidOAuthState) {
+        t.Errorf("unknown state: err = %v, want ErrInvalidOAuthState", err)
+    }
+
+    state := startTestLogin(t, s)
+    if _, err := s.CompleteLogin(ctx, "other", state, "code", ClientInfo{}); !errors.Is(err, ErrUnknownProvider) {
+        t.Errorf("other provider: err = %v, want ErrUnknownProvider", err)
+    }
+    if _, err := s.CompleteLogin(ctx, "fake", state, "code", ClientInfo{}); err != nil {
+        t.Fatalf("CompleteLogin: %v", err)
+    }
+    if _, err := s.CompleteLogin(ctx, "fake", state, "code", ClientInfo{}); !errors.Is(err, ErrInvalidOAuthState) {
+        t.Errorf("replayed state: err = %v, want ErrInvalidOAuthState", err)
+    }
+
+    expired := startTestLogin(t, s)
+    db.Model(&models.OAuthState{}).Where("state_hash = ?", hashToken(expired)).Update("expires_at", time.Now().Add(-time.Minute))
+    if _, err := s.CompleteLogin(ctx, "fake", expired, "code", ClientInfo{}); !errors.Is(err, ErrInvalidOAuthState) {
+        t.Errorf("expired state: err = %v, want ErrInvalidOAuthState", err)
+    }
+}
+
+func TestOAuthLoginSendsPKCEVerifierAndNonce(t *testing.T) {
+    s, provider, db := newTestOAuthService(t, oauth.UserInfo{Subject: "sub-1", Email: "user@example.com", EmailVerified: true})
+
+    state := startTestLogin(t, s)
+    var record models.OAuthState
+    if err := db.Where("state_hash = ?", hashToken(state)).First(&record).Error; err != nil {
+        t.Fatalf("state not stored: %v", err)
+    }
+    if record.CodeVerifier == "" || oauth.CodeChallengeS256(record.CodeVerifier) != provider.challenge {
+        t.Error("the stored verifier does not match the challenge sent to the provider")
+    }
+    if record.Nonce == "" || record.Nonce != provider.nonce {
+        t.Error("the stored nonce does not match the one sent to the provider")
+    }
+
+    // The fake provider fails the exchange unless both come back
+    if _, err := s.CompleteLogin(context.Background(), "fake", state, "code", ClientInfo{}); err != nil {
+        t.Fatalf("CompleteLogin: %v", err)
+    }
+}
+
+func TestOAuthLoginLinksVerifiedAccount(t *testing.T) {
+    s, _, db := newTestOAuthService(t, oauth.UserInfo{Subject: "sub-1", Email: "Owner@Example.com", EmailVerified: true})
+    owner := createTestUser(t, db, "owner@example.com", true)
+
+    result, err := s.CompleteLogin(context.Background(), "fake", startTestLogin(t, s), "code", ClientInfo{})
+    if err != nil {
+        t.Fatalf("CompleteLogin: %v", err)
+    }
+    if result.User.ID != owner.ID {
+        t.Errorf("signed in as user %d, want the existing account %d", result.User.ID, owner.ID)
+    }
+
+    var count int64
+    db.Model(&models.UserIdentity{}).Where("user_id = ?", owner.ID).Count(&count)
+    if count != 1 {
+        t.Errorf("identities = %d, want 1", count)
+    }
+}
+
+func TestOAuthLoginRefusesUnverifiedAccount(t *testing.T) {
+    // Someone registered the victim's address and never verified it
+    s, _, db := newTestOAuthService(t, oauth.UserInfo{Subject: "victim", Email: "victim@example.com", EmailVerified: true})
+    squatter := createTestUser(t, db, "victim@example.com", false)
+
+    if _, err := s.CompleteLogin(context.Background(), "fake", startTestLogin(t, s), "code", ClientInfo{}); !errors.Is(err, ErrUnverifiedAccount) {
+        t.Fatalf("err = %v, want ErrUnverifiedAccount", err)
+    }
+
+    var count int64
+    db.Model(&models.UserIdentity{}).Count(&count)
+    if count != 0 {
+        t.Errorf("identities = %d, want none", count)
+    }
+    var user models.User
+    db.First(&user, squatter.ID)
+    if user.EmailVerifiedAt != nil {
+        t.Error("the unverified account was marked verified")
+    }
+}
+
+func TestOAuthLoginRequiresVerifiedProviderEmail(t *testing.T) {
+    s, _, db := newTestOAuthService(t, oauth.UserInfo{Subject: "sub-1", Email: "owner@example.com", EmailVerified: false})
+    createTestUser(t, db, "owner@example.com", true)
+
+    if _, err := s.CompleteLogin(context.Background(), "fake", startTestLogin(t, s), "code", ClientInfo{}); !errors.Is(err, ErrUnverifiedOAuthEmail) {
+        t.Fatalf("err = %v, want ErrUnverifiedOAuthEmail", err)
+    }
+}
+
+func TestOAuthLoginPrefersExistingIdentity(t *testing.T) {
+    s, provider, db := newTestOAuthService(t, oauth.UserInfo{Subject: "sub-1", Email: "first@example.com", EmailVerified: true})
+    first := createTestUser(t, db, "first@example.com", true)
+    createTestUser(t, db, "second@example.com", true)
+
+    if _, err := s.CompleteLogin(context.Background(), "fake", startTestLogin(t, s), "code", ClientInfo{}); err != nil {
+        t.Fatalf("CompleteLogin: %v", err)
+    }
+
+    // The provider account changed its address to another user's
+    provider.info.Email = "second@example.com"
+    result, err := s.CompleteLogin(context.Background(), "fake", startTestLogin(t, s), "code", ClientInfo{})
+    if err != nil {
+        t.Fatalf("CompleteLogin: %v", err)
+    }
+    if result.User.ID != first.ID {
+        t.Errorf("signed in as user %d, want the linked account %d", result.User.ID, first.ID)
+    }
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "devlink-backend/internal/mailer"
+    "devlink-backend/internal/models"
+    "golang.org/x/crypto/bcrypt"
+    "gorm.io/gorm"
+)
+
+const passwordResetTTL = time.Hour
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// RequestPasswordReset emails a single-use reset link. Unknown addresses are
+// ignored silently so the endpoint can't be used to discover accounts.
+func (s *AuthService) RequestPasswordReset(email string) error {
+    var user models.User
+    if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+        return nil
+    }
+
+    token, tokenHash, err := generateOpaqueToken()
+    if err != nil {
+        return err
+    }
+
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        // Only the most recently requested link should work
+        if err := tx.Model(&models.PasswordResetToken{}).
+            Where("user_id = ? AND used_at IS NULL", user.ID).
+            Update("used_at", time.Now()).Error; err != nil {
+            return err
+        }
+
+        return tx.Create(&models.PasswordResetToken{
+            UserID:    user.ID,
+            TokenHash: tokenHash,
+            ExpiresAt: time.Now().Add(passwordResetTTL),
+        }).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    link := fmt.Sprintf("%s/reset-password?token=%s", s.appURL, token)
+    return s.mailer.Send(mailer.Message{
+        To:      user.Email,
+        Subject: "Reset your DevLink password",
+        Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your DevLink account.\n"+
+            "Use the link below within the next hour to choose a new one:\n\n%s\n\n"+
+            "If this wasn't you, you can ignore this email.\n", user.Name, link),
+    })
+}
+
+// ResetPassword consumes a reset token, sets the new password and signs the
+// user out of every existing session.
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+    var reset models.PasswordResetToken
+    if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).
+        First(&reset).Error; err != nil {
+        return ErrInvalidResetToken
+    }
+
+    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+    if err != nil {
+        return err
+    }
+
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        result := tx.Model(&models.PasswordResetToken{}).
+            Where("id = ? AND used_at IS NULL", reset.ID).
+            Update("used_at", time.Now())
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrInvalidResetToken
+        }
+
+        return tx.Model(&models.User{}).
+            Where("id = ?", reset.UserID).
+            Update("password", string(hashedPassword)).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    _, err = s.RevokeOtherSessions(reset.UserID, "")
+    return err
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+    "errors"
+
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+var ErrEmailNotVerified = errors.New("verify your email address before making resources public")
+
+// PublishPolicy decides whether a user may make resources public.
+// ResourceService consults it whenever is_public is being turned on.
+type PublishPolicy interface {
+    CanPublish(userID uint) error
+}
+
+// VerifiedEmailPolicy only lets users with a confirmed email address publish.
+type VerifiedEmailPolicy struct {
+    db *gorm.DB
+}
+
+func NewVerifiedEmailPolicy(db *gorm.DB) *VerifiedEmailPolicy {
+    return &VerifiedEmailPolicy{db: db}
+}
+
+func (p *VerifiedEmailPolicy) CanPublish(userID uint) error {
+    var user models.User
+    if err := p.db.Select("id", "email_verified_at").First(&user, userID).Error; err != nil {
+        return err
+    }
+
+    if user.EmailVerifiedAt == nil {
+        return ErrEmailNotVerified
+    }
+
+    return nil
+}
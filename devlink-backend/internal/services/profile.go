@@ -0,0 +1,144 @@
+package services
+
+import (
+    "errors"
+    "net/url"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/models"
+    "golang.org/x/crypto/bcrypt"
+)
+
+var (
+    ErrInvalidTimezone = errors.New("timezone must be an IANA name such as Europe/Berlin")
+    ErrSameEmail       = errors.New("new email is the same as the current one")
+    ErrInvalidLinkURL  = errors.New("avatar_url and website must be http or https URLs")
+)
+
+type UpdateProfileRequest struct {
+    Name          *string `json:"name,omitempty" binding:"omitempty,min=2"`
+    Bio           *string `json:"bio,omitempty" binding:"omitempty,max=500"`
+    AvatarURL     *string `json:"avatar_url,omitempty" binding:"omitempty,url"`
+    Website       *string `json:"website,omitempty" binding:"omitempty,url"`
+    Timezone      *string `json:"timezone,omitempty"`
+    DefaultPublic *bool   `json:"default_public,omitempty"`
+}
+
+func (s *AuthService) GetProfile(userID uint) (*models.User, error) {
+    var user models.User
+    if err := s.db.First(&user, userID).Error; err != nil {
+        return nil, errors.New("user not found")
+    }
+    return &user, nil
+}
+
+func (s *AuthService) UpdateProfile(userID uint, req UpdateProfileRequest) (*models.User, error) {
+    user, err := s.GetProfile(userID)
+    if err != nil {
+        return nil, err
+    }
+
+    // Update fields
+    updates := make(map[string]interface{})
+    if req.Name != nil {
+        updates["name"] = strings.TrimSpace(*req.Name)
+    }
+    if req.Bio != nil {
+        updates["bio"] = *req.Bio
+    }
+    // Both end up in links and images on profile pages, so javascript: and
+    // similar schemes are refused. An empty value clears the field.
+    if req.AvatarURL != nil {
+        if !isWebURL(*req.AvatarURL) {
+            return nil, ErrInvalidLinkURL
+        }
+        updates["avatar_url"] = *req.AvatarURL
+    }
+    if req.Website != nil {
+        if !isWebURL(*req.Website) {
+            return nil, ErrInvalidLinkURL
+        }
+        updates["website"] = *req.Website
+    }
+    if req.Timezone != nil {
+        if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+            return nil, ErrInvalidTimezone
+        }
+        updates["timezone"] = *req.Timezone
+    }
+    if req.DefaultPublic != nil {
+        updates["default_public"] = *req.DefaultPublic
+    }
+
+    if len(updates) == 0 {
+        return user, nil
+    }
+
+    if err := s.db.Model(user).Updates(updates).Error; err != nil {
+        return nil, err
+    }
+
+    return user, nil
+}
+
+// ChangePassword sets a new password after checking the current one, then
+// signs out every other session. The session making the change stays.
+func (s *AuthService) ChangePassword(userID uint, currentPassword, newPassword, keepTokenID string) error {
+    user, err := s.GetProfile(userID)
+    if err != nil {
+        return err
+    }
+
+    if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+        return ErrInvalidPassword
+    }
+
+    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+    if err != nil {
+        return err
+    }
+
+    if err := s.db.Model(user).Update("password", string(hashedPassword)).Error; err != nil {
+        return err
+    }
+
+    _, err = s.RevokeOtherSessions(user.ID, keepTokenID)
+    return err
+}
+
+// RequestEmailChange sends a confirmation link to the new address. The
+// account keeps its current email until the link is opened.
+func (s *AuthService) RequestEmailChange(userID uint, password, newEmail string) error {
+    user, err := s.GetProfile(userID)
+    if err != nil {
+        return err
+    }
+
+    if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+        return ErrInvalidPassword
+    }
+
+    if strings.EqualFold(newEmail, user.Email) {
+        return ErrSameEmail
+    }
+    if isReservedEmail(newEmail) {
+        return ErrReservedEmail
+    }
+
+    var existingUser models.User
+    if err := s.db.Where("email = ?", newEmail).First(&existingUser).Error; err == nil {
+        return ErrEmailTaken
+    }
+
+    return s.sendVerificationLink(user, newEmail, user.Email)
+}
+
+// isWebURL reports whether raw is empty or an absolute http(s) URL.
+func isWebURL(raw string) bool {
+    if raw == "" {
+        return true
+    }
+    u, err := url.Parse(raw)
+    return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestUpdateProfileRestrictsLinkSchemes(t *testing.T) {
+    db := newTestDB(t)
+    authService, _ := newTestAuthService(db)
+    user := createTestUser(t, db, "user@example.com", true)
+
+    tests := []struct {
+        url string
+        ok  bool
+    }{
+        {"https://example.com/me.png", true},
+        {"HTTP://example.com", true},
+        {"", true},
+        {"javascript:alert(1)", false},
+        {"JavaScript:alert(1)", false},
+        {"data:image/svg+xml;base64,PHN2Zz4=", false},
+        {"vbscript:msgbox", false},
+        {"//example.com/me.png", false},
+        {"https:///no-host", false},
+    }
+
+    for _, tt := range tests {
+        for name, req := range map[string]UpdateProfileRequest{
+            "avatar_url": {AvatarURL: &tt.url},
+            "website":    {Website: &tt.url},
+        } {
+            _, err := authService.UpdateProfile(user.ID, req)
+            if tt.ok && err != nil {
+                t.Errorf("%s %q: %v", name, tt.url, err)
+            }
+            if !tt.ok && !errors.Is(err, ErrInvalidLinkURL) {
+                t.Errorf("%s %q: err = %v, want ErrInvalidLinkURL", name, tt.url, err)
+            }
+        }
+    }
+}
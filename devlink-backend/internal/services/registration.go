@@ -0,0 +1,156 @@
+package services
+
+import (
+    "errors"
+    "strings"
+    "time"
+
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+const (
+    RegistrationOpen   = "open"
+    RegistrationClosed = "closed"
+    RegistrationInvite = "invite"
+    RegistrationDomain = "domain"
+)
+
+var (
+    ErrRegistrationClosed    = errors.New("registration is closed")
+    ErrInviteRequired        = errors.New("registration requires an invite code")
+    ErrInvalidInviteCode     = errors.New("invite code is invalid, used up or expired")
+    ErrEmailDomainNotAllowed = errors.New("registration is restricted to approved email domains")
+)
+
+// RegistrationPolicy decides who may create an account.
+type RegistrationPolicy struct {
+    Mode           string   // one of the Registration* modes
+    AllowedDomains []string // for RegistrationDomain, e.g. "example.com"
+}
+
+type CreateInviteRequest struct {
+    Note          string `json:"note"`
+    MaxUses       int    `json:"max_uses" binding:"omitempty,min=1"`
+    ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1"`
+}
+
+// RegistrationMode tells clients which sign-up form to show.
+func (s *AuthService) RegistrationMode() string {
+    return s.registration.Mode
+}
+
+// checkRegistration enforces the policy for a new account. In invite mode
+// the code is consumed inside tx so a failed signup doesn't use it up.
+func (s *AuthService) checkRegistration(tx *gorm.DB, email, inviteCode string) error {
+    if isReservedEmail(email) {
+        return ErrReservedEmail
+    }
+
+    switch s.registration.Mode {
+    case RegistrationOpen:
+        return nil
+    case RegistrationDomain:
+        if !s.registration.allowsDomain(email) {
+            return ErrEmailDomainNotAllowed
+        }
+        return nil
+    case RegistrationInvite:
+        if inviteCode == "" {
+            return ErrInviteRequired
+        }
+        return consumeInvite(tx, inviteCode)
+    default:
+        return ErrRegistrationClosed
+    }
+}
+
+// requiresVerifiedEmail reports whether the user must confirm their address
+// before signing in. In domain mode the address is what grants access, so
+// typing one on an allowed domain is not enough.
+func (s *AuthService) requiresVerifiedEmail(user *models.User) bool {
+    return s.registration.Mode == RegistrationDomain && user.EmailVerifiedAt == nil
+}
+
+func (p RegistrationPolicy) allowsDomain(email string) bool {
+    at := strings.LastIndex(email, "@")
+    if at < 0 {
+        return false
+    }
+    domain := strings.ToLower(email[at+1:])
+
+    for _, allowed := range p.AllowedDomains {
+        if domain == strings.ToLower(allowed) {
+            return true
+        }
+    }
+    return false
+}
+
+func consumeInvite(tx *gorm.DB, code string) error {
+    result := tx.Model(&models.InviteCode{}).
+        Where("code_hash = ? AND revoked_at IS NULL AND uses < max_uses", hashToken(normalizeRecoveryCode(code))).
+        Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+        Update("uses", gorm.Expr("uses + ?", 1))
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrInvalidInviteCode
+    }
+    return nil
+}
+
+// CreateInvite issues a new invite code and returns the raw value alongside
+// the stored record. Codes have the same shape as 2FA recovery codes.
+func (s *AdminService) CreateInvite(actorID uint, req CreateInviteRequest) (*models.InviteCode, string, error) {
+    code, err := newRecoveryCode()
+    if err != nil {
+        return nil, "", err
+    }
+
+    invite := models.InviteCode{
+        CodeHash:    hashToken(normalizeRecoveryCode(code)),
+        Prefix:      code[:4],
+        Note:        req.Note,
+        MaxUses:     1,
+        CreatedByID: actorID,
+    }
+    if req.MaxUses > 0 {
+        invite.MaxUses = req.MaxUses
+    }
+    if req.ExpiresInDays > 0 {
+        expiresAt := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+        invite.ExpiresAt = &expiresAt
+    }
+
+    if err := s.db.Create(&invite).Error; err != nil {
+        return nil, "", err
+    }
+
+    return &invite, code, nil
+}
+
+func (s *AdminService) GetInvites() ([]models.InviteCode, error) {
+    var invites []models.InviteCode
+    if err := s.db.Order("created_at DESC").Find(&invites).Error; err != nil {
+        return nil, err
+    }
+    return invites, nil
+}
+
+func (s *AdminService) RevokeInvite(inviteID uint) error {
+    result := s.db.Model(&models.InviteCode{}).
+        Where("id = ? AND revoked_at IS NULL", inviteID).
+        Update("revoked_at", time.Now())
+
+    if result.Error != nil {
+        return result.Error
+    }
+
+    if result.RowsAffected == 0 {
+        return errors.New("invite not found")
+    }
+
+    return nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "devlink-backend/internal/jwtkeys"
+)
+
+func TestDomainModeRequiresVerifiedEmail(t *testing.T) {
+    db := newTestDB(t)
+    mail := &testMailer{}
+    authService := NewAuthService(db, jwtkeys.NewHMACKeySet("test-secret"), mail, "http://app.test", RegistrationPolicy{
+        Mode:           RegistrationDomain,
+        AllowedDomains: []string{"example.com"},
+    })
+    tokens := NewAccessTokenService(db, authService)
+
+    if _, err := authService.Register("Outsider", "outsider@other.com", "password123", ""); !errors.Is(err, ErrEmailDomainNotAllowed) {
+        t.Errorf("Register on another domain: err = %v, want ErrEmailDomainNotAllowed", err)
+    }
+
+    // Typing an address on the allowed domain gets an account, not access
+    user, err := authService.Register("Outsider", "anyone@example.com", "password123", "")
+    if err != nil {
+        t.Fatalf("Register: %v", err)
+    }
+    if _, err := authService.StartSession(user, ClientInfo{}); !errors.Is(err, ErrUnverifiedLogin) {
+        t.Errorf("StartSession: err = %v, want ErrUnverifiedLogin", err)
+    }
+    sent := len(mail.sent)
+    if _, err := authService.Login("anyone@example.com", "password123", ClientInfo{}); !errors.Is(err, ErrUnverifiedLogin) {
+        t.Errorf("Login: err = %v, want ErrUnverifiedLogin", err)
+    }
+    if len(mail.sent) != sent+1 {
+        t.Errorf("login sent %d emails, want a fresh verification link", len(mail.sent)-sent)
+    }
+    _, raw, err := tokens.CreateToken(user.ID, CreateAccessTokenRequest{Name: "ci", Scopes: []string{ScopeResourcesRead}})
+    if err != nil {
+        t.Fatalf("CreateToken: %v", err)
+    }
+    if _, err := tokens.Authenticate(raw); !errors.Is(err, ErrInvalidAccessToken) {
+        t.Errorf("Authenticate: err = %v, want ErrInvalidAccessToken", err)
+    }
+
+    // Confirming the address unlocks everything
+    if err := db.Model(user).Update("email_verified_at", time.Now()).Error; err != nil {
+        t.Fatalf("verify: %v", err)
+    }
+    result, err := authService.Login("anyone@example.com", "password123", ClientInfo{})
+    if err != nil || result.Tokens == nil {
+        t.Fatalf("Login after verifying: %v", err)
+    }
+    if _, err := tokens.Authenticate(raw); err != nil {
+        t.Errorf("Authenticate after verifying: %v", err)
+    }
+
+    // Sessions from before the policy applied stop refreshing
+    if err := db.Model(user).Update("email_verified_at", nil).Error; err != nil {
+        t.Fatalf("unverify: %v", err)
+    }
+    if _, err := authService.Refresh(result.Tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+        t.Errorf("Refresh: err = %v, want ErrInvalidRefreshToken", err)
+    }
+}
@@ -1,23 +1,40 @@
 package services
 
 import (
+    "context"
     "errors"
+    "sort"
+    "strings"
 
     "devlink-backend/internal/models"
+    "devlink-backend/internal/searchindex"
+    "devlink-backend/internal/searchquery"
     "gorm.io/gorm"
 )
 
+var (
+    ErrResourceNotFound = errors.New("resource not found or access denied")
+    ErrNotResourceOwner = errors.New("only the resource's owner can change its visibility")
+    ErrNoRedirect       = errors.New("the link checker has not seen this link redirect")
+    ErrInvalidQuery     = errors.New("invalid search query")
+)
+
 type ResourceService struct {
-    db *gorm.DB
+    db            *gorm.DB
+    publishPolicy PublishPolicy
+    metadata      MetadataSource
+    index         SearchIndex // nil searches with Postgres full-text search
 }
 
 type CreateResourceRequest struct {
-    Title       string `json:"title" binding:"required"`
-    URL         string `json:"url" binding:"required,url"`
-    Description string `json:"description"`
-    Category    string `json:"category"`
-    Tags        string `json:"tags"`
-    IsPublic    bool   `json:"is_public"`
+    Title       string  `json:"title"` // taken from the page when omitted
+    URL         string  `json:"url" binding:"required,url"`
+    Description string  `json:"description"`
+    Category    string  `json:"category"`
+    FaviconURL  string  `json:"favicon_url" binding:"omitempty,url"`
+    Tags        TagList `json:"tags"`
+    IsPublic    *bool   `json:"is_public"` // omitted means the user's default visibility
+    Archive     bool    `json:"archive"`   // keep snapshots of the page
 }
 
 type UpdateResourceRequest struct {
@@ -25,103 +42,150 @@ type UpdateResourceRequest struct {
     URL         *string `json:"url,omitempty"`
     Description *string `json:"description,omitempty"`
     Category    *string `json:"category,omitempty"`
-    Tags        *string `json:"tags,omitempty"`
+    Tags        TagList `json:"tags,omitempty"`
     IsPublic    *bool   `json:"is_public,omitempty"`
+    Archive     *bool   `json:"archive,omitempty"`
 }
 
 type ResourceFilters struct {
-    Category string `form:"category"`
-    Tags     string `form:"tags"`
-    Search   string `form:"search"`
-    IsPublic *bool  `form:"is_public"`
-    Page     int    `form:"page,default=1"`
-    Limit    int    `form:"limit,default=20"`
+    Query      string `form:"q"`          // search syntax of the searchquery package
+    Collection *uint  `form:"collection"` // only resources in this collection
+    Page       int    `form:"page,default=1"`
+    Limit      int    `form:"limit,default=20"`
+}
+
+// ResourcePage is one page of a resource listing.
+type ResourcePage struct {
+    Resources  []models.Resource
+    Total      int64
+    Highlights map[uint]SearchHighlight // where the search matched, by resource ID
+    Facets     *searchindex.Facets      // categories and tags of all results, when searching the index
 }
 
-func NewResourceService(db *gorm.DB) *ResourceService {
-    return &ResourceService{db: db}
+func NewResourceService(db *gorm.DB, publishPolicy PublishPolicy, metadata MetadataSource, index SearchIndex) *ResourceService {
+    return &ResourceService{
+        db:            db,
+        publishPolicy: publishPolicy,
+        metadata:      metadata,
+        index:         index,
+    }
 }
 
-func (s *ResourceService) CreateResource(userID uint, req CreateResourceRequest) (*models.Resource, error) {
+// CreateResource saves a resource in the user's personal space, or in the
+// given workspace when workspaceID is not 0. Missing details are filled in
+// from the page.
+func (s *ResourceService) CreateResource(ctx context.Context, workspaceID, userID uint, req CreateResourceRequest) (*models.Resource, error) {
+    var workspace *uint
+    if workspaceID != 0 {
+        if _, err := workspaceRole(s.db, workspaceID, userID); err != nil {
+            return nil, err
+        }
+        workspace = &workspaceID
+    }
+
+    s.prefill(ctx, &req)
+
+    isPublic := false
+    if req.IsPublic != nil {
+        isPublic = *req.IsPublic
+        if isPublic {
+            if err := s.checkPublish(userID); err != nil {
+                return nil, err
+            }
+        }
+    } else {
+        // Fall back to private when the profile default can't be honoured yet
+        var user models.User
+        if err := s.db.Select("default_public").First(&user, userID).Error; err != nil {
+            return nil, err
+        }
+        isPublic = user.DefaultPublic && s.checkPublish(userID) == nil
+    }
+
     resource := models.Resource{
         Title:       req.Title,
         URL:         req.URL,
         Description: req.Description,
         Category:    req.Category,
-        Tags:        req.Tags,
-        IsPublic:    req.IsPublic,
+        FaviconURL:  req.FaviconURL,
+        IsPublic:    isPublic,
+        Archive:     req.Archive,
         UserID:      userID,
+        WorkspaceID: workspace,
     }
 
-    if err := s.db.Create(&resource).Error; err != nil {
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&resource).Error; err != nil {
+            return err
+        }
+        if err := setResourceTags(tx, &resource, req.Tags); err != nil {
+            return err
+        }
+        return refreshSearchVectors(tx, s.index, []uint{resource.ID})
+    })
+    if err != nil {
         return nil, err
     }
+    reindexResources(s.db, s.index, []uint{resource.ID})
 
     return &resource, nil
 }
 
-func (s *ResourceService) GetResourceByID(resourceID, userID uint) (*models.Resource, error) {
+func (s *ResourceService) GetResourceByID(workspaceID, resourceID, userID uint) (*models.Resource, error) {
     var resource models.Resource
-    query := s.db.Where("id = ?", resourceID)
+    query, _, err := s.scope(workspaceID, userID)
+    if err != nil {
+        return nil, err
+    }
+    query = query.Where("id = ?", resourceID)
     
-    // Users can only access their own resources or public ones
-    query = query.Where("user_id = ? OR is_public = ?", userID, true)
+    // Users can only access their own resources, ones shared with them through a collection,
+    // or public ones that haven't been hidden by a moderator. In a workspace, public means
+    // visible to its members.
+    query = query.Where("user_id = ? OR (is_public = ? AND hidden_at IS NULL) OR id IN (?)", userID, true,
+        sharedResourceIDs(s.db, userID, models.CollectionViewer, models.CollectionEditor, models.CollectionOwner))
     
-    if err := query.First(&resource).Error; err != nil {
+    if err := query.Preload("Tags").First(&resource).Error; err != nil {
         return nil, err
     }
 
     return &resource, nil
 }
 
-func (s *ResourceService) GetUserResources(userID uint, filters ResourceFilters) ([]models.Resource, int64, error) {
-    var resources []models.Resource
-    var total int64
-
-    query := s.db.Model(&models.Resource{}).Where("user_id = ?", userID)
-
-    // Apply filters
-    if filters.Category != "" {
-        query = query.Where("category ILIKE ?", "%"+filters.Category+"%")
-    }
-
-    if filters.Tags != "" {
-        query = query.Where("tags ILIKE ?", "%"+filters.Tags+"%")
-    }
-
-    if filters.Search != "" {
-        searchTerm := "%" + filters.Search + "%"
-        query = query.Where("title ILIKE ? OR description ILIKE ? OR url ILIKE ?", 
-            searchTerm, searchTerm, searchTerm)
-    }
-
-    if filters.IsPublic != nil {
-        query = query.Where("is_public = ?", *filters.IsPublic)
+// GetUserResources lists the user's personal resources, or in a workspace
+// their own resources plus everything shared with the workspace.
+func (s *ResourceService) GetUserResources(workspaceID, userID uint, filters ResourceFilters) (*ResourcePage, error) {
+    q, err := parseQuery(filters.Query)
+    if err != nil {
+        return nil, err
     }
 
-    // Count total before pagination
-    if err := query.Count(&total).Error; err != nil {
-        return nil, 0, err
+    query, _, err := s.scope(workspaceID, userID)
+    if err != nil {
+        return nil, err
     }
 
-    // Apply pagination
-    offset := (filters.Page - 1) * filters.Limit
-    if err := query.Order("created_at DESC").
-        Limit(filters.Limit).
-        Offset(offset).
-        Find(&resources).Error; err != nil {
-        return nil, 0, err
+    // A collection shows everything in it, including resources other members added
+    if filters.Collection != nil {
+        if _, _, err := collectionRole(s.db, *filters.Collection, userID); err != nil {
+            return nil, err
+        }
+        query = query.Where("resources.id IN (?)", s.db.Table("collection_resources").
+            Select("resource_id").
+            Where("collection_id = ?", *filters.Collection))
+    } else if workspaceID != 0 {
+        query = query.Where("user_id = ? OR is_public = ?", userID, true)
+    } else {
+        query = query.Where("user_id = ?", userID)
     }
 
-    return resources, total, nil
+    return s.list(query, q, filters)
 }
 
-func (s *ResourceService) UpdateResource(resourceID, userID uint, req UpdateResourceRequest) (*models.Resource, error) {
-    var resource models.Resource
-    
-    // Check if resource exists and belongs to user
-    if err := s.db.Where("id = ? AND user_id = ?", resourceID, userID).First(&resource).Error; err != nil {
-        return nil, errors.New("resource not found or access denied")
+func (s *ResourceService) UpdateResource(workspaceID, resourceID, userID uint, req UpdateResourceRequest) (*models.Resource, error) {
+    resource, err := s.editableResource(workspaceID, resourceID, userID)
+    if err != nil {
+        return nil, err
     }
 
     // Update only provided fields
@@ -129,8 +193,14 @@ func (s *ResourceService) UpdateResource(resourceID, userID uint, req UpdateReso
     if req.Title != nil {
         updates["title"] = *req.Title
     }
-    if req.URL != nil {
+    if req.URL != nil && *req.URL != resource.URL {
         updates["url"] = *req.URL
+        // A new URL starts over with the link checker
+        updates["link_status"] = models.LinkUnchecked
+        updates["link_status_code"] = 0
+        updates["link_final_url"] = ""
+        updates["link_checked_at"] = nil
+        updates["link_failures"] = 0
     }
     if req.Description != nil {
         updates["description"] = *req.Description
@@ -138,73 +208,275 @@ func (s *ResourceService) UpdateResource(resourceID, userID uint, req UpdateReso
     if req.Category != nil {
         updates["category"] = *req.Category
     }
-    if req.Tags != nil {
-        updates["tags"] = *req.Tags
-    }
     if req.IsPublic != nil {
+        // Collaborators can edit a resource but not publish or unpublish it
+        if resource.UserID != userID {
+            return nil, ErrNotResourceOwner
+        }
+        // Only going from private to public needs the policy's blessing
+        if *req.IsPublic && !resource.IsPublic {
+            if err := s.checkPublish(userID); err != nil {
+                return nil, err
+            }
+        }
         updates["is_public"] = *req.IsPublic
     }
+    if req.Archive != nil {
+        updates["archive"] = *req.Archive
+    }
 
-    if err := s.db.Model(&resource).Updates(updates).Error; err != nil {
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        if len(updates) > 0 {
+            if err := tx.Model(resource).Updates(updates).Error; err != nil {
+                return err
+            }
+        }
+        if req.Tags != nil {
+            if err := setResourceTags(tx, resource, req.Tags); err != nil {
+                return err
+            }
+        } else if err := tx.Model(resource).Association("Tags").Find(&resource.Tags); err != nil {
+            return err
+        }
+        return refreshSearchVectors(tx, s.index, []uint{resource.ID})
+    })
+    if err != nil {
         return nil, err
     }
+    reindexResources(s.db, s.index, []uint{resource.ID})
 
+    return resource, nil
+}
+
+// editableResource loads a resource the user may change: their own, one in
+// a collection they can edit, or any resource of a workspace they administer.
+func (s *ResourceService) editableResource(workspaceID, resourceID, userID uint) (*models.Resource, error) {
+    var resource models.Resource
+    query, role, err := s.scope(workspaceID, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    if workspaceRank(role) < workspaceRank(models.WorkspaceRoleAdmin) {
+        query = query.Where("user_id = ? OR id IN (?)", userID,
+            sharedResourceIDs(s.db, userID, models.CollectionEditor, models.CollectionOwner))
+    }
+    if err := query.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+        return nil, ErrResourceNotFound
+    }
     return &resource, nil
 }
 
-func (s *ResourceService) DeleteResource(resourceID, userID uint) error {
-    result := s.db.Where("id = ? AND user_id = ?", resourceID, userID).Delete(&models.Resource{})
+func (s *ResourceService) DeleteResource(workspaceID, resourceID, userID uint) error {
+    query, role, err := s.scope(workspaceID, userID)
+    if err != nil {
+        return err
+    }
+    if workspaceRank(role) < workspaceRank(models.WorkspaceRoleAdmin) {
+        query = query.Where("user_id = ? OR id IN (?)", userID, sharedResourceIDs(s.db, userID, models.CollectionOwner))
+    }
+
+    result := query.Where("id = ?", resourceID).Delete(&models.Resource{})
     
     if result.Error != nil {
         return result.Error
     }
     
     if result.RowsAffected == 0 {
-        return errors.New("resource not found or access denied")
+        return ErrResourceNotFound
+    }
+    if s.index != nil {
+        s.index.Delete(resourceID)
     }
     
     return nil
 }
 
-func (s *ResourceService) IncrementClickCount(resourceID uint) error {
-    return s.db.Model(&models.Resource{}).
-        Where("id = ?", resourceID).
-        Update("click_count", gorm.Expr("click_count + ?", 1)).Error
-}
-
-func (s *ResourceService) GetPublicResources(filters ResourceFilters) ([]models.Resource, int64, error) {
-    var resources []models.Resource
-    var total int64
+// FixRedirect replaces a redirected link's URL with the address it now
+// ends up at. It needs the same access as editing the resource.
+func (s *ResourceService) FixRedirect(workspaceID, resourceID, userID uint) (*models.Resource, error) {
+    resource, err := s.GetResourceByID(workspaceID, resourceID, userID)
+    if err != nil {
+        return nil, ErrResourceNotFound
+    }
+    if resource.LinkStatus != models.LinkRedirected || resource.LinkFinalURL == "" {
+        return nil, ErrNoRedirect
+    }
 
-    query := s.db.Model(&models.Resource{}).Where("is_public = ?", true).Preload("User")
+    finalURL := resource.LinkFinalURL
+    statusCode := resource.LinkStatusCode
+    checkedAt := resource.LinkCheckedAt
+    resource, err = s.UpdateResource(workspaceID, resourceID, userID, UpdateResourceRequest{URL: &finalURL})
+    if err != nil {
+        return nil, err
+    }
 
-    // Apply filters (same as above but for public resources)
-    if filters.Category != "" {
-        query = query.Where("category ILIKE ?", "%"+filters.Category+"%")
+    // The checker just saw the new URL answer, so there is no need to wait for the next round
+    if err := s.db.Model(resource).UpdateColumns(map[string]interface{}{
+        "link_status":      models.LinkOK,
+        "link_status_code": statusCode,
+        "link_final_url":   finalURL,
+        "link_checked_at":  checkedAt,
+    }).Error; err != nil {
+        return nil, err
     }
 
-    if filters.Tags != "" {
-        query = query.Where("tags ILIKE ?", "%"+filters.Tags+"%")
+    return resource, nil
+}
+
+// IncrementClickCount bumps the counter and keeps a click history entry.
+func (s *ResourceService) IncrementClickCount(resourceID uint, referrer string) error {
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        result := tx.Model(&models.Resource{}).
+            Where("id = ?", resourceID).
+            Update("click_count", gorm.Expr("click_count + ?", 1))
+        if result.Error != nil || result.RowsAffected == 0 {
+            return result.Error
+        }
+
+        return tx.Create(&models.ResourceClick{
+            ResourceID: resourceID,
+            Referrer:   referrer,
+        }).Error
+    })
+}
+
+func (s *ResourceService) GetPublicResources(filters ResourceFilters) (*ResourcePage, error) {
+    q, err := parseQuery(filters.Query)
+    if err != nil {
+        return nil, err
     }
 
-    if filters.Search != "" {
-        searchTerm := "%" + filters.Search + "%"
-        query = query.Where("title ILIKE ? OR description ILIKE ?", searchTerm, searchTerm)
+    // Workspace resources never reach the public feed, whatever their visibility
+    query := s.db.Model(&models.Resource{}).
+        Where("is_public = ? AND hidden_at IS NULL AND workspace_id IS NULL", true)
+
+    return s.list(query, q, filters, "User")
+}
+
+// list pages through the resources of query that match the search, best
+// matches first, with their tags and the given associations loaded.
+func (s *ResourceService) list(query *gorm.DB, q *searchquery.Query, filters ResourceFilters, preload ...string) (*ResourcePage, error) {
+    query = applyFilters(s.db, query, q)
+    if s.index != nil && strings.TrimSpace(q.Text) != "" {
+        return s.listIndexed(query, q.Text, filters, preload)
     }
+    query = applySearch(query, q.Text)
 
-    // Count total
-    if err := query.Count(&total).Error; err != nil {
-        return nil, 0, err
+    // Count total before pagination
+    page := &ResourcePage{}
+    if err := query.Count(&page.Total).Error; err != nil {
+        return nil, err
     }
 
-    // Apply pagination
+    // Apply pagination, best search matches first
     offset := (filters.Page - 1) * filters.Limit
-    if err := query.Order("created_at DESC").
+    query = orderBySearch(query, q.Text).
         Limit(filters.Limit).
         Offset(offset).
-        Find(&resources).Error; err != nil {
-        return nil, 0, err
+        Preload("Tags")
+    for _, association := range preload {
+        query = query.Preload(association)
+    }
+    if err := query.Find(&page.Resources).Error; err != nil {
+        return nil, err
+    }
+
+    ids := make([]uint, len(page.Resources))
+    for i, resource := range page.Resources {
+        ids[i] = resource.ID
+    }
+    highlights, err := highlights(s.db, ids, q.Text)
+    if err != nil {
+        return nil, err
+    }
+    page.Highlights = highlights
+    return page, nil
+}
+
+// listIndexed searches the index, keeps the hits query allows and pages
+// through them in the index's order.
+func (s *ResourceService) listIndexed(query *gorm.DB, text string, filters ResourceFilters, preload []string) (*ResourcePage, error) {
+    hits := s.index.Search(text, maxIndexHits)
+    hitIDs := make([]uint, len(hits))
+    for i, hit := range hits {
+        hitIDs[i] = hit.ID
+    }
+
+    var allowedIDs []uint
+    if len(hitIDs) > 0 {
+        if err := query.Session(&gorm.Session{}).
+            Where("resources.id IN ?", hitIDs).
+            Pluck("resources.id", &allowedIDs).Error; err != nil {
+            return nil, err
+        }
+    }
+    allowed := make(map[uint]bool, len(allowedIDs))
+    for _, id := range allowedIDs {
+        allowed[id] = true
+    }
+    var matched []uint
+    for _, id := range hitIDs {
+        if allowed[id] {
+            matched = append(matched, id)
+        }
+    }
+
+    facets := s.index.Facets(matched, facetSize)
+    page := &ResourcePage{
+        Total:      int64(len(matched)),
+        Highlights: make(map[uint]SearchHighlight),
+        Facets:     &facets,
+    }
+
+    from := min(len(matched), max(0, (filters.Page-1)*filters.Limit))
+    to := min(len(matched), from+max(0, filters.Limit))
+    pageIDs := matched[from:to]
+    if len(pageIDs) == 0 {
+        return page, nil
     }
 
-    return resources, total, nil
+    find := s.db.Where("id IN ?", pageIDs).Preload("Tags")
+    for _, association := range preload {
+        find = find.Preload(association)
+    }
+    if err := find.Find(&page.Resources).Error; err != nil {
+        return nil, err
+    }
+
+    rank := make(map[uint]int, len(pageIDs))
+    for i, id := range pageIDs {
+        rank[id] = i
+    }
+    sort.Slice(page.Resources, func(i, j int) bool {
+        return rank[page.Resources[i].ID] < rank[page.Resources[j].ID]
+    })
+    for _, resource := range page.Resources {
+        title, snippet := s.index.Highlight(resource.ID, text)
+        page.Highlights[resource.ID] = SearchHighlight{Title: title, Snippet: snippet}
+    }
+    return page, nil
+}
+
+// scope starts a query limited to one space: the personal space when
+// workspaceID is 0, otherwise the workspace, after checking the user is a
+// member. Every per-user query here goes through it so that nothing leaks
+// between workspaces. The role is empty in the personal space.
+func (s *ResourceService) scope(workspaceID, userID uint) (*gorm.DB, string, error) {
+    if workspaceID == 0 {
+        return s.db.Model(&models.Resource{}).Where("resources.workspace_id IS NULL"), "", nil
+    }
+
+    role, err := workspaceRole(s.db, workspaceID, userID)
+    if err != nil {
+        return nil, "", err
+    }
+    return s.db.Model(&models.Resource{}).Where("resources.workspace_id = ?", workspaceID), role, nil
+}
+
+func (s *ResourceService) checkPublish(userID uint) error {
+    if s.publishPolicy == nil {
+        return nil
+    }
+    return s.publishPolicy.CanPublish(userID)
 }
\ No newline at end of file
@@ -0,0 +1,244 @@
+package services
+
+import (
+    "fmt"
+    "html"
+    "regexp"
+    "strings"
+    "unicode/utf8"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/searchquery"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+const (
+    // Text search configuration used for both indexing and queries
+    searchConfig = "english"
+
+    // Snapshot text indexed per resource; the rest of a long page is ignored
+    maxSearchTextBytes = 100000
+
+    // Markers ts_headline puts around matches; replaced after escaping
+    highlightStart = "\x02"
+    highlightStop  = "\x03"
+)
+
+// searchVectorSQL computes a resource's search vector. Title matches weigh
+// the most, then tags, then the description, then the page's latest snapshot.
+const searchVectorSQL = `setweight(to_tsvector('` + searchConfig + `', coalesce(resources.title, '')), 'A') ||
+    setweight(to_tsvector('` + searchConfig + `', coalesce((SELECT string_agg(tags.name, ' ') FROM tags
+        JOIN resource_tags ON resource_tags.tag_id = tags.id
+        WHERE resource_tags.resource_id = resources.id), '')), 'B') ||
+    setweight(to_tsvector('` + searchConfig + `', coalesce(resources.description, '')), 'C') ||
+    setweight(to_tsvector('` + searchConfig + `', coalesce(` + latestSnapshotTextSQL + `, '')), 'D')`
+
+const latestSnapshotTextSQL = `(SELECT snapshots.search_text FROM snapshots
+        WHERE snapshots.resource_id = resources.id
+        ORDER BY snapshots.created_at DESC, snapshots.id DESC LIMIT 1)`
+
+const searchQuerySQL = "websearch_to_tsquery('" + searchConfig + "', ?)"
+
+// SearchHighlight shows where a search matched. Matches are wrapped in
+// <mark> and everything else is HTML-escaped.
+type SearchHighlight struct {
+    Title   string `json:"title"`
+    Snippet string `json:"snippet"` // from the description or the page snapshot, empty when neither matched
+}
+
+// refreshSearchVectors recomputes the search vector of the given resources.
+// ids is a slice of IDs or a subquery selecting them. With an embedded index
+// nothing searches the vectors, so they are only cleared, which is cheap and
+// lets BackfillSearchVectors rebuild them if Postgres search comes back.
+func refreshSearchVectors(tx *gorm.DB, index SearchIndex, ids interface{}) error {
+    if index != nil {
+        return tx.Exec("UPDATE resources SET search_vector = NULL WHERE resources.id IN (?)", ids).Error
+    }
+    return tx.Exec("UPDATE resources SET search_vector = "+searchVectorSQL+" WHERE resources.id IN (?)", ids).Error
+}
+
+// BackfillSearchVectors indexes resources saved before full-text search
+// existed, or while search used the embedded index, or by anything that
+// bypassed the services. Only needed when searching with Postgres.
+func BackfillSearchVectors(db *gorm.DB) error {
+    return db.Exec("UPDATE resources SET search_vector = " + searchVectorSQL + " WHERE resources.search_vector IS NULL").Error
+}
+
+// applyFilters narrows a resource query to the qualifiers of a parsed
+// search. Qualifiers combine with AND, values within one with OR.
+func applyFilters(db, query *gorm.DB, q *searchquery.Query) *gorm.DB {
+    for _, filter := range q.Filters {
+        var conds []string
+        var args []interface{}
+
+        switch filter.Field {
+        case searchquery.FieldTag:
+            tagged := db.Table("resource_tags").
+                Select("resource_tags.resource_id").
+                Joins("JOIN tags ON tags.id = resource_tags.tag_id").
+                Where("tags.name IN ?", NormalizeTags(filter.Values))
+            conds, args = append(conds, "resources.id IN (?)"), append(args, tagged)
+
+        case searchquery.FieldCategory:
+            categories := make([]string, len(filter.Values))
+            for i, category := range filter.Values {
+                categories[i] = strings.ToLower(category)
+            }
+            conds, args = append(conds, "LOWER(resources.category) IN ?"), append(args, categories)
+
+        case searchquery.FieldIs:
+            for _, value := range filter.Values {
+                switch value {
+                case searchquery.IsPublic, searchquery.IsPrivate:
+                    conds, args = append(conds, "resources.is_public = ?"), append(args, value == searchquery.IsPublic)
+                case searchquery.IsArchived:
+                    conds, args = append(conds, "resources.archive = ?"), append(args, true)
+                case searchquery.IsUnchecked:
+                    conds, args = append(conds, "resources.link_status = ?"), append(args, models.LinkUnchecked)
+                default:
+                    conds, args = append(conds, "resources.link_status = ?"), append(args, value)
+                }
+            }
+
+        case searchquery.FieldSite:
+            // The host or any subdomain of it, whatever the scheme, port or path
+            for _, site := range filter.Values {
+                conds = append(conds, "resources.url ~* ?")
+                args = append(args, `^[a-z][a-z0-9+.-]*://([^/@]*@)?([^/@]*\.)?`+regexp.QuoteMeta(site)+`(:[0-9]+)?([/?#]|$)`)
+            }
+
+        case searchquery.FieldClicks:
+            var bounds []string
+            if filter.Min != nil {
+                bounds, args = append(bounds, "resources.click_count >= ?"), append(args, *filter.Min)
+            }
+            if filter.Max != nil {
+                bounds, args = append(bounds, "resources.click_count <= ?"), append(args, *filter.Max)
+            }
+            conds = append(conds, strings.Join(bounds, " AND "))
+
+        case searchquery.FieldCreated, searchquery.FieldUpdated:
+            column := "resources.created_at"
+            if filter.Field == searchquery.FieldUpdated {
+                column = "resources.updated_at"
+            }
+            var bounds []string
+            if !filter.From.IsZero() {
+                bounds, args = append(bounds, column+" >= ?"), append(args, filter.From)
+            }
+            if !filter.Until.IsZero() {
+                bounds, args = append(bounds, column+" < ?"), append(args, filter.Until)
+            }
+            conds = append(conds, strings.Join(bounds, " AND "))
+        }
+
+        if len(conds) == 0 {
+            continue
+        }
+        sql := "(" + strings.Join(conds, " OR ") + ")"
+        if filter.Negate {
+            sql = "NOT " + sql
+        }
+        query = query.Where(sql, args...)
+    }
+
+    return query
+}
+
+// parseQuery reads the q parameter of a resource listing.
+func parseQuery(raw string) (*searchquery.Query, error) {
+    q, err := searchquery.Parse(raw)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+    }
+    return q, nil
+}
+
+// applySearch keeps resources matching a web-search style query: words,
+// "quoted phrases", OR and -excluded words.
+func applySearch(query *gorm.DB, search string) *gorm.DB {
+    search = strings.TrimSpace(search)
+    if search == "" {
+        return query
+    }
+    return query.Where("resources.search_vector @@ "+searchQuerySQL, search)
+}
+
+// orderBySearch puts the best matches first, newest first among equals.
+func orderBySearch(query *gorm.DB, search string) *gorm.DB {
+    search = strings.TrimSpace(search)
+    if search == "" {
+        return query.Order("resources.created_at DESC")
+    }
+    // One expression: GORM drops expressions when merging ORDER BY clauses
+    return query.Order(clause.OrderBy{Expression: clause.Expr{
+        SQL:  "ts_rank(resources.search_vector, " + searchQuerySQL + ") DESC, resources.created_at DESC",
+        Vars: []interface{}{search},
+    }})
+}
+
+// searchText trims snapshot text to what gets indexed. Invalid bytes are
+// replaced first, so the cut only has to step back to the start of the
+// rune it landed in.
+func searchText(text string) string {
+    text = strings.ToValidUTF8(text, "\uFFFD")
+    if len(text) <= maxSearchTextBytes {
+        return text
+    }
+    cut := maxSearchTextBytes
+    for i := 0; i < utf8.UTFMax && cut > 0 && !utf8.RuneStart(text[cut]); i++ {
+        cut--
+    }
+    return text[:cut]
+}
+
+// highlights marks where search matched in the title and the description
+// or page snapshot of each resource.
+func highlights(db *gorm.DB, resourceIDs []uint, search string) (map[uint]SearchHighlight, error) {
+    search = strings.TrimSpace(search)
+    if search == "" || len(resourceIDs) == 0 {
+        return map[uint]SearchHighlight{}, nil
+    }
+
+    const options = "StartSel=" + highlightStart + ", StopSel=" + highlightStop +
+        ", MaxWords=30, MinWords=12, MaxFragments=2, FragmentDelimiter=\" … \""
+    var rows []struct {
+        ID      uint
+        Title   string
+        Snippet string
+    }
+    err := db.Raw(`SELECT resources.id,
+            ts_headline('`+searchConfig+`', resources.title, q.query, 'HighlightAll=true, StartSel=`+highlightStart+`, StopSel=`+highlightStop+`') AS title,
+            CASE WHEN to_tsvector('`+searchConfig+`', coalesce(resources.description, '')) @@ q.query
+                THEN ts_headline('`+searchConfig+`', resources.description, q.query, '`+options+`')
+                ELSE coalesce(ts_headline('`+searchConfig+`', `+latestSnapshotTextSQL+`, q.query, '`+options+`'), '')
+            END AS snippet
+        FROM resources, (SELECT `+searchQuerySQL+` AS query) q
+        WHERE resources.id IN ?`, search, resourceIDs).Scan(&rows).Error
+    if err != nil {
+        return nil, err
+    }
+
+    result := make(map[uint]SearchHighlight, len(rows))
+    for _, row := range rows {
+        snippet := row.Snippet
+        // ts_headline returns the start of the text when nothing matched
+        if !strings.Contains(snippet, highlightStart) {
+            snippet = ""
+        }
+        result[row.ID] = SearchHighlight{
+            Title:   markHighlights(row.Title),
+            Snippet: markHighlights(snippet),
+        }
+    }
+    return result, nil
+}
+
+// markHighlights escapes text for HTML and turns the match markers into
+// <mark> elements.
+func markHighlights(text string) string {
+    text = html.EscapeString(text)
+    text = strings.ReplaceAll(text, highlightStart, "<mark>")
+    return strings.ReplaceAll(text, highlightStop, "</mark>")
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+    "log"
+    "strings"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/searchindex"
+    "gorm.io/gorm"
+)
+
+const (
+    maxIndexHits     = 5000 // best matches of a search checked against the database
+    facetSize        = 20   // categories and tags listed per facet
+    reindexBatchSize = 500
+)
+
+// SearchIndex is a full-text index of resources kept outside the database,
+// such as searchindex.Index. Without one, resources are searched with
+// Postgres full-text search.
+//
+// The index only finds candidates: every search is checked against the
+// database for access and qualifiers, so entries of resources deleted some
+// other way do no harm until the next rebuild drops them.
+type SearchIndex interface {
+    Put(doc searchindex.Document)
+    Delete(id uint)
+    Replace(docs []searchindex.Document)
+    Search(text string, limit int) []searchindex.Hit
+    Facets(ids []uint, size int) searchindex.Facets
+    Highlight(id uint, text string) (title, snippet string)
+}
+
+// reindexResources brings the index entries of the given resources up to
+// date, dropping the ones that no longer exist. The database stays the
+// source of truth, so failures are only logged.
+func reindexResources(db *gorm.DB, index SearchIndex, ids []uint) {
+    if index == nil || len(ids) == 0 {
+        return
+    }
+
+    var resources []models.Resource
+    if err := db.Preload("Tags").Where("id IN ?", ids).Find(&resources).Error; err != nil {
+        log.Printf("Updating search index for resources %v failed: %v", ids, err)
+        return
+    }
+    docs, err := indexDocuments(db, resources)
+    if err != nil {
+        log.Printf("Updating search index for resources %v failed: %v", ids, err)
+        return
+    }
+
+    found := make(map[uint]bool, len(docs))
+    for _, doc := range docs {
+        index.Put(doc)
+        found[doc.ID] = true
+    }
+    for _, id := range ids {
+        if !found[id] {
+            index.Delete(id)
+        }
+    }
+}
+
+// RebuildSearchIndex replaces the contents of the index with every resource
+// in the database and returns how many there are.
+func RebuildSearchIndex(db *gorm.DB, index SearchIndex) (int, error) {
+    var docs []searchindex.Document
+    var resources []models.Resource
+    err := db.Preload("Tags").FindInBatches(&resources, reindexBatchSize, func(tx *gorm.DB, batch int) error {
+        batchDocs, err := indexDocuments(db, resources)
+        if err != nil {
+            return err
+        }
+        docs = append(docs, batchDocs...)
+        return nil
+    }).Error
+    if err != nil {
+        return 0, err
+    }
+
+    index.Replace(docs)
+    return len(docs), nil
+}
+
+// indexDocuments turns resources, with their tags loaded, into documents,
+// adding the text of each one's latest page snapshot.
+func indexDocuments(db *gorm.DB, resources []models.Resource) ([]searchindex.Document, error) {
+    if len(resources) == 0 {
+        return nil, nil
+    }
+
+    ids := make([]uint, len(resources))
+    for i, resource := range resources {
+        ids[i] = resource.ID
+    }
+    var snapshots []models.Snapshot
+    if err := db.Select("resource_id", "search_text").
+        Where("resource_id IN ?", ids).
+        Where(`snapshots.id = (SELECT latest.id FROM snapshots latest
+            WHERE latest.resource_id = snapshots.resource_id
+            ORDER BY latest.created_at DESC, latest.id DESC LIMIT 1)`).
+        Find(&snapshots).Error; err != nil {
+        return nil, err
+    }
+    content := make(map[uint]string, len(snapshots))
+    for _, snapshot := range snapshots {
+        content[snapshot.ResourceID] = snapshot.SearchText
+    }
+
+    docs := make([]searchindex.Document, len(resources))
+    for i, resource := range resources {
+        tags := make([]string, len(resource.Tags))
+        for j, tag := range resource.Tags {
+            tags[j] = tag.Name
+        }
+        docs[i] = searchindex.Document{
+            ID:          resource.ID,
+            Title:       resource.Title,
+            Description: resource.Description,
+            URL:         resource.URL,
+            Category:    strings.ToLower(resource.Category),
+            Tags:        tags,
+            Content:     content[resource.ID],
+        }
+    }
+    return docs, nil
+}
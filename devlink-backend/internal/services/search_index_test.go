@@ -0,0 +1,82 @@
+package services
+
+import (
+    "context"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "devlink-backend/internal/searchindex"
+    "gorm.io/gorm"
+)
+
+func TestRebuildSearchIndexSavesToDisk(t *testing.T) {
+    db := newTestDB(t)
+    user := createTestUser(t, db, "user@example.com", true)
+    for _, title := range []string{"Kubernetes runbook", "Postgres tuning"} {
+        if _, err := NewResourceService(db, nil, nil, nil).CreateResource(context.Background(), 0, user.ID, CreateResourceRequest{
+            Title: title,
+            URL:   "https://example.com/" + title,
+        }); err != nil {
+            t.Fatalf("CreateResource(%q): %v", title, err)
+        }
+    }
+
+    path := filepath.Join(t.TempDir(), "search.index")
+    index, err := searchindex.Open(path)
+    if err != nil {
+        t.Fatalf("Open: %v", err)
+    }
+    index.Put(searchindex.Document{ID: 999, Title: "Stale entry"})
+
+    indexed, err := RebuildSearchIndex(db, index)
+    if err != nil || indexed != 2 {
+        t.Fatalf("RebuildSearchIndex = %d, %v; want 2 resources", indexed, err)
+    }
+    if err := index.Flush(); err != nil {
+        t.Fatalf("Flush: %v", err)
+    }
+
+    reopened, err := searchindex.Open(path)
+    if err != nil {
+        t.Fatalf("Open: %v", err)
+    }
+    if reopened.Len() != 2 || len(reopened.Search("runbook", 10)) != 1 || len(reopened.Search("stale", 10)) != 0 {
+        t.Errorf("saved index has %d documents, want exactly the two resources", reopened.Len())
+    }
+}
+
+func TestEmbeddedIndexSkipsSearchVectors(t *testing.T) {
+    db := newTestDB(t)
+    var computed int
+    if err := db.Callback().Raw().Before("test:postgres_only").Register("test:count_vectors", func(db *gorm.DB) {
+        if strings.Contains(db.Statement.SQL.String(), "to_tsvector") {
+            computed++
+        }
+    }); err != nil {
+        t.Fatalf("register callback: %v", err)
+    }
+    user := createTestUser(t, db, "user@example.com", true)
+    ctx := context.Background()
+
+    create := func(resources *ResourceService) {
+        t.Helper()
+        resource, err := resources.CreateResource(ctx, 0, user.ID, CreateResourceRequest{Title: "Runbook", URL: "https://example.com/runbook"})
+        if err != nil {
+            t.Fatalf("CreateResource: %v", err)
+        }
+        title := "Runbook v2"
+        if _, err := resources.UpdateResource(0, resource.ID, user.ID, UpdateResourceRequest{Title: &title}); err != nil {
+            t.Fatalf("UpdateResource: %v", err)
+        }
+    }
+
+    create(NewResourceService(db, nil, nil, searchindex.New()))
+    if computed != 0 {
+        t.Errorf("computed search vectors %d times with an embedded index", computed)
+    }
+    create(NewResourceService(db, nil, nil, nil))
+    if computed == 0 {
+        t.Error("Postgres search did not compute search vectors")
+    }
+}
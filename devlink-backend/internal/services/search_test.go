@@ -0,0 +1,40 @@
+package services
+
+import (
+    "strings"
+    "testing"
+    "unicode/utf8"
+)
+
+func TestSearchText(t *testing.T) {
+    ascii := strings.Repeat("a", maxSearchTextBytes)
+    tests := []struct {
+        name string
+        text string
+        want string
+    }{
+        {"short text is kept", "héllo wörld", "héllo wörld"},
+        {"long text is cut", ascii + "tail", ascii},
+        {"a cut inside a rune backs off to its start", ascii[:maxSearchTextBytes-1] + "€uro", ascii[:maxSearchTextBytes-1]},
+        {"a rune ending at the limit is kept", ascii[:maxSearchTextBytes-3] + "€uro", ascii[:maxSearchTextBytes-3] + "€"},
+        {"invalid bytes are replaced, not cut at", "caf\xe9 " + ascii, "caf� " + ascii[:maxSearchTextBytes-len("caf� ")]},
+        {"short invalid text is repaired", "bad \xff\xfe byte", "bad � byte"},
+    }
+    for _, tt := range tests {
+        got := searchText(tt.text)
+        if got != tt.want {
+            t.Errorf("%s: got %d bytes ending %q, want %d bytes ending %q",
+                tt.name, len(got), tail(got), len(tt.want), tail(tt.want))
+        }
+        if !utf8.ValidString(got) || len(got) > maxSearchTextBytes {
+            t.Errorf("%s: result is invalid UTF-8 or too long (%d bytes)", tt.name, len(got))
+        }
+    }
+}
+
+func tail(s string) string {
+    if len(s) > 12 {
+        return s[len(s)-12:]
+    }
+    return s
+}
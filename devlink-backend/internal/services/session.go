@@ -0,0 +1,125 @@
+package services
+
+import (
+    "errors"
+    "time"
+
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+// How often last_seen_at is written back, so every request doesn't cost an UPDATE
+const sessionTouchInterval = time.Minute
+
+var ErrSessionRevoked = errors.New("session has been revoked")
+
+func (s *AuthService) createSession(userID uint, client ClientInfo) (*models.Session, error) {
+    tokenID, err := generateID()
+    if err != nil {
+        return nil, err
+    }
+
+    session := models.Session{
+        UserID:     userID,
+        TokenID:    tokenID,
+        UserAgent:  client.UserAgent,
+        IPAddress:  client.IPAddress,
+        LastSeenAt: time.Now(),
+    }
+
+    if err := s.db.Create(&session).Error; err != nil {
+        return nil, err
+    }
+
+    return &session, nil
+}
+
+// ValidateSession checks that the session behind an access token's jti is
+// still active and records that it was just used.
+func (s *AuthService) ValidateSession(tokenID string) error {
+    if tokenID == "" {
+        return ErrSessionRevoked
+    }
+
+    var session models.Session
+    if err := s.db.Where("token_id = ? AND revoked_at IS NULL", tokenID).First(&session).Error; err != nil {
+        return ErrSessionRevoked
+    }
+
+    if time.Since(session.LastSeenAt) > sessionTouchInterval {
+        s.touchSession(tokenID)
+    }
+
+    return nil
+}
+
+func (s *AuthService) GetUserSessions(userID uint) ([]models.Session, error) {
+    var sessions []models.Session
+    if err := s.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+        Order("last_seen_at DESC").
+        Find(&sessions).Error; err != nil {
+        return nil, err
+    }
+
+    return sessions, nil
+}
+
+func (s *AuthService) RevokeUserSession(sessionID, userID uint) error {
+    var session models.Session
+    if err := s.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session).Error; err != nil {
+        return errors.New("session not found")
+    }
+
+    return s.revokeSession(session.TokenID)
+}
+
+// RevokeOtherSessions signs the user out everywhere except the session
+// identified by keepTokenID.
+func (s *AuthService) RevokeOtherSessions(userID uint, keepTokenID string) (int, error) {
+    var sessions []models.Session
+    if err := s.db.Where("user_id = ? AND token_id <> ? AND revoked_at IS NULL", userID, keepTokenID).
+        Find(&sessions).Error; err != nil {
+        return 0, err
+    }
+
+    for _, session := range sessions {
+        if err := s.revokeSession(session.TokenID); err != nil {
+            return 0, err
+        }
+    }
+
+    return len(sessions), nil
+}
+
+// RevokeAllCredentials ends every session of the user and deletes their
+// personal access tokens, for when an account must lose all access at once.
+func (s *AuthService) RevokeAllCredentials(userID uint) error {
+    if _, err := s.RevokeOtherSessions(userID, ""); err != nil {
+        return err
+    }
+
+    return s.db.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{}).Error
+}
+
+func (s *AuthService) touchSession(tokenID string) {
+    s.db.Model(&models.Session{}).
+        Where("token_id = ?", tokenID).
+        Update("last_seen_at", time.Now())
+}
+
+// revokeSession marks a session revoked along with every refresh token in its family.
+func (s *AuthService) revokeSession(tokenID string) error {
+    now := time.Now()
+
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(&models.Session{}).
+            Where("token_id = ? AND revoked_at IS NULL", tokenID).
+            Update("revoked_at", now).Error; err != nil {
+            return err
+        }
+
+        return tx.Model(&models.RefreshToken{}).
+            Where("family_id = ? AND revoked_at IS NULL", tokenID).
+            Update("revoked_at", now).Error
+    })
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "devlink-backend/internal/models"
+    "golang.org/x/crypto/bcrypt"
+    "gorm.io/gorm"
+)
+
+var (
+    ErrShareTarget         = errors.New("specify exactly one of resource_id and collection_id")
+    ErrShareLinkNotFound   = errors.New("share link not found or no longer valid")
+    ErrSharePasswordNeeded = errors.New("this link is password protected")
+    ErrWrongSharePassword  = errors.New("incorrect password")
+    ErrShareWorkspace      = errors.New("workspace resources cannot be shared by link")
+)
+
+type ShareService struct {
+    db    *gorm.DB
+    guard *LoginGuard // throttles wrong link passwords, may be nil
+}
+
+type CreateShareLinkRequest struct {
+    ResourceID    *uint  `json:"resource_id"`
+    CollectionID  *uint  `json:"collection_id"`
+    Password      string `json:"password" binding:"omitempty,min=8,max=72"`
+    ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // 0 means no expiry
+}
+
+// SharedContent is what a share link opens: a single resource, or a
+// collection with the resources in it.
+type SharedContent struct {
+    Link       *models.ShareLink
+    Resource   *models.Resource
+    Collection *models.Collection
+    Resources  []models.Resource
+}
+
+func NewShareService(db *gorm.DB, guard *LoginGuard) *ShareService {
+    return &ShareService{
+        db:    db,
+        guard: guard,
+    }
+}
+
+// CreateShareLink makes a link for one of the user's own resources or a
+// collection they own. The raw token is returned only here. Links only ever
+// show personal resources, so workspace resources are refused outright.
+func (s *ShareService) CreateShareLink(userID uint, req CreateShareLinkRequest) (*models.ShareLink, string, error) {
+    if (req.ResourceID == nil) == (req.CollectionID == nil) {
+        return nil, "", ErrShareTarget
+    }
+
+    if req.ResourceID != nil {
+        var resource models.Resource
+        if err := s.db.Where("id = ? AND user_id = ?", *req.ResourceID, userID).First(&resource).Error; err != nil {
+            return nil, "", ErrResourceNotFound
+        }
+        if resource.WorkspaceID != nil {
+            return nil, "", ErrShareWorkspace
+        }
+    } else {
+        _, role, err := collectionRole(s.db, *req.CollectionID, userID)
+        if err != nil {
+            return nil, "", err
+        }
+        if role != models.CollectionOwner {
+            return nil, "", ErrCollectionRole
+        }
+    }
+
+    token, tokenHash, err := generateOpaqueToken()
+    if err != nil {
+        return nil, "", err
+    }
+
+    link := models.ShareLink{
+        UserID:       userID,
+        ResourceID:   req.ResourceID,
+        CollectionID: req.CollectionID,
+        TokenHash:    tokenHash,
+        Prefix:       token[:6],
+    }
+    if req.Password != "" {
+        hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+        if err != nil {
+            return nil, "", err
+        }
+        link.PasswordHash = string(hashed)
+    }
+    if req.ExpiresInDays > 0 {
+        expiresAt := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+        link.ExpiresAt = &expiresAt
+    }
+
+    if err := s.db.Create(&link).Error; err != nil {
+        return nil, "", err
+    }
+
+    return &link, token, nil
+}
+
+// GetShareLinks lists the links the user has created, newest first.
+func (s *ShareService) GetShareLinks(userID uint) ([]models.ShareLink, error) {
+    var links []models.ShareLink
+    if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&links).Error; err != nil {
+        return nil, err
+    }
+    return links, nil
+}
+
+func (s *ShareService) RevokeShareLink(userID, linkID uint) error {
+    result := s.db.Model(&models.ShareLink{}).
+        Where("id = ? AND user_id = ? AND revoked_at IS NULL", linkID, userID).
+        Update("revoked_at", time.Now())
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return ErrShareLinkNotFound
+    }
+    return nil
+}
+
+// OpenShareLink resolves a token for an anonymous visitor and counts the
+// view. Links to content that was deleted or hidden by a moderator stop
+// working like revoked ones, and workspace resources are never shown, even
+// in a collection or when the resource moved into a workspace later. Wrong passwords are throttled per link and
+// per client IP, failing with a *LockedOutError once they add up.
+func (s *ShareService) OpenShareLink(ctx context.Context, token, password, ip string) (*SharedContent, error) {
+    var link models.ShareLink
+    if err := s.db.Where("token_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)",
+        hashToken(token), time.Now()).First(&link).Error; err != nil {
+        return nil, ErrShareLinkNotFound
+    }
+
+    if link.PasswordHash != "" {
+        if password == "" {
+            return nil, ErrSharePasswordNeeded
+        }
+        if err := s.checkPassword(ctx, &link, password, ip); err != nil {
+            return nil, err
+        }
+    }
+
+    content := SharedContent{Link: &link}
+    if link.ResourceID != nil {
+        var resource models.Resource
+        if err := s.db.Where("id = ? AND hidden_at IS NULL AND workspace_id IS NULL", *link.ResourceID).
+            Preload("Tags").
+            First(&resource).Error; err != nil {
+            return nil, ErrShareLinkNotFound
+        }
+        content.Resource = &resource
+    } else {
+        var collection models.Collection
+        if err := s.db.First(&collection, *link.CollectionID).Error; err != nil {
+            return nil, ErrShareLinkNotFound
+        }
+        content.Collection = &collection
+
+        if err := s.db.Where("hidden_at IS NULL AND workspace_id IS NULL AND id IN (?)", s.db.Table("collection_resources").
+            Select("resource_id").
+            Where("collection_id = ?", collection.ID)).
+            Order("created_at DESC").
+            Preload("Tags").
+            Find(&content.Resources).Error; err != nil {
+            return nil, err
+        }
+    }
+
+    now := time.Now()
+    if err := s.db.Model(&link).Updates(map[string]interface{}{
+        "view_count":     gorm.Expr("view_count + ?", 1),
+        "last_viewed_at": now,
+    }).Error; err != nil {
+        return nil, err
+    }
+    link.ViewCount++
+    link.LastViewedAt = &now
+
+    return &content, nil
+}
+
+// checkPassword compares a visitor's password with the link's, counting
+// failures against the link and the IP. Links are keyed by their token
+// hash, so raw tokens never reach the throttle store.
+func (s *ShareService) checkPassword(ctx context.Context, link *models.ShareLink, password, ip string) error {
+    if s.guard != nil {
+        if err := s.guard.Check(ctx, ip, link.TokenHash); err != nil {
+            return err
+        }
+    }
+
+    if err := bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)); err != nil {
+        if s.guard != nil {
+            if lockErr := s.guard.RecordFailure(ctx, ip, link.TokenHash); lockErr != nil {
+                return lockErr
+            }
+        }
+        return ErrWrongSharePassword
+    }
+
+    if s.guard != nil {
+        s.guard.RecordSuccess(ctx, link.TokenHash)
+    }
+    return nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "testing"
+
+    "devlink-backend/internal/throttle"
+)
+
+func newTestShareLink(t *testing.T, guard *LoginGuard) (*ShareService, string) {
+    t.Helper()
+
+    db := newTestDB(t)
+    user := createTestUser(t, db, "owner@example.com", true)
+    resource, err := NewResourceService(db, nil, nil, nil).CreateResource(context.Background(), 0, user.ID, CreateResourceRequest{
+        Title: "Design notes",
+        URL:   "https://example.com/notes",
+    })
+    if err != nil {
+        t.Fatalf("CreateResource: %v", err)
+    }
+
+    shares := NewShareService(db, guard)
+    _, token, err := shares.CreateShareLink(user.ID, CreateShareLinkRequest{ResourceID: &resource.ID, Password: "correct horse"})
+    if err != nil {
+        t.Fatalf("CreateShareLink: %v", err)
+    }
+    return shares, token
+}
+
+func TestOpenShareLinkThrottlesWrongPasswords(t *testing.T) {
+    policy := testLoginGuardPolicy()
+    store := throttle.NewMemoryStore()
+    shares, token := newTestShareLink(t, NewShareLinkGuard(store, policy))
+    ctx := context.Background()
+
+    for i := 0; i < policy.FreeAttempts; i++ {
+        if _, err := shares.OpenShareLink(ctx, token, "wrong", "203.0.113.7"); !errors.Is(err, ErrWrongSharePassword) {
+            t.Fatalf("attempt %d: err = %v, want ErrWrongSharePassword", i+1, err)
+        }
+    }
+
+    var locked *LockedOutError
+    if _, err := shares.OpenShareLink(ctx, token, "wrong", "203.0.113.7"); !errors.As(err, &locked) {
+        t.Fatalf("attempt past the free ones: err = %v, want a *LockedOutError", err)
+    }
+
+    // The link stays throttled from other addresses, even for the right password
+    if _, err := shares.OpenShareLink(ctx, token, "correct horse", "198.51.100.1"); !errors.As(err, &locked) {
+        t.Errorf("right password on a throttled link: err = %v, want a *LockedOutError", err)
+    }
+
+    // Share links keep their own counters next to logins in the same store
+    logins := NewLoginGuard(store, policy, nil, nil)
+    if err := logins.Check(ctx, "203.0.113.7", "owner@example.com"); err != nil {
+        t.Errorf("share link failures throttled logins: %v", err)
+    }
+}
+
+func TestOpenShareLinkPasswordResetsFailures(t *testing.T) {
+    shares, token := newTestShareLink(t, NewShareLinkGuard(throttle.NewMemoryStore(), testLoginGuardPolicy()))
+    ctx := context.Background()
+
+    shares.OpenShareLink(ctx, token, "wrong", "203.0.113.7")
+    if _, err := shares.OpenShareLink(ctx, token, "correct horse", "203.0.113.7"); err != nil {
+        t.Fatalf("OpenShareLink with the right password: %v", err)
+    }
+    shares.OpenShareLink(ctx, token, "wrong", "198.51.100.1")
+    if _, err := shares.OpenShareLink(ctx, token, "wrong", "192.0.2.1"); !errors.Is(err, ErrWrongSharePassword) {
+        t.Errorf("err = %v, want ErrWrongSharePassword while within the free attempts again", err)
+    }
+}
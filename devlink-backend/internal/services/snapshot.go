@@ -0,0 +1,332 @@
+package services
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "html"
+    "log"
+    "time"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/storage"
+    "devlink-backend/internal/webpage"
+    "gorm.io/gorm"
+)
+
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// ArticleSource extracts the readable content of a page.
+type ArticleSource interface {
+    Article(ctx context.Context, url string) (*webpage.Article, error)
+}
+
+// SnapshotPolicy controls how often archived resources are snapshotted and
+// how many snapshots are kept.
+type SnapshotPolicy struct {
+    RefreshAfter time.Duration // how old the latest snapshot must be before another is taken
+    Keep         int           // snapshots kept per resource, oldest are dropped first
+    BatchSize    int           // resources snapshotted per archiver run
+}
+
+func DefaultSnapshotPolicy() SnapshotPolicy {
+    return SnapshotPolicy{
+        RefreshAfter: 7 * 24 * time.Hour,
+        Keep:         10,
+        BatchSize:    100,
+    }
+}
+
+// SnapshotService keeps copies of the pages of resources that opted in, so
+// they can still be read after the original changes or disappears.
+type SnapshotService struct {
+    db        *gorm.DB
+    resources *ResourceService
+    articles  ArticleSource
+    store     storage.Store
+    policy    SnapshotPolicy
+}
+
+func NewSnapshotService(db *gorm.DB, resources *ResourceService, articles ArticleSource, store storage.Store, policy SnapshotPolicy) *SnapshotService {
+    return &SnapshotService{
+        db:        db,
+        resources: resources,
+        articles:  articles,
+        store:     store,
+        policy:    policy,
+    }
+}
+
+// GetSnapshots lists a resource's snapshots, newest first, to anyone who
+// can see the resource.
+func (s *SnapshotService) GetSnapshots(workspaceID, resourceID, userID uint) ([]models.Snapshot, error) {
+    if _, err := s.resources.GetResourceByID(workspaceID, resourceID, userID); err != nil {
+        return nil, ErrResourceNotFound
+    }
+
+    var snapshots []models.Snapshot
+    if err := s.db.Omit("search_text").
+        Where("resource_id = ?", resourceID).
+        Order("created_at DESC, id DESC").
+        Find(&snapshots).Error; err != nil {
+        return nil, err
+    }
+    return snapshots, nil
+}
+
+// GetSnapshotContent returns a snapshot's stored HTML document, or its plain
+// text when text is true.
+func (s *SnapshotService) GetSnapshotContent(ctx context.Context, workspaceID, resourceID, snapshotID, userID uint, text bool) (*models.Snapshot, []byte, error) {
+    if _, err := s.resources.GetResourceByID(workspaceID, resourceID, userID); err != nil {
+        return nil, nil, ErrResourceNotFound
+    }
+
+    var snapshot models.Snapshot
+    if err := s.db.Where("id = ? AND resource_id = ?", snapshotID, resourceID).First(&snapshot).Error; err != nil {
+        return nil, nil, ErrSnapshotNotFound
+    }
+
+    key := snapshot.HTMLKey
+    if text {
+        key = snapshot.TextKey
+    }
+    content, err := s.store.Get(ctx, key)
+    if errors.Is(err, storage.ErrNotFound) {
+        return nil, nil, ErrSnapshotNotFound
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    return &snapshot, content, nil
+}
+
+// TakeSnapshot captures the resource's page now. When the content hasn't
+// changed since the latest snapshot, that snapshot is returned instead.
+func (s *SnapshotService) TakeSnapshot(ctx context.Context, workspaceID, resourceID, userID uint) (*models.Snapshot, bool, error) {
+    resource, err := s.resources.editableResource(workspaceID, resourceID, userID)
+    if err != nil {
+        return nil, false, err
+    }
+    return s.capture(ctx, resource)
+}
+
+// DeleteSnapshot removes one snapshot and its stored content.
+func (s *SnapshotService) DeleteSnapshot(ctx context.Context, workspaceID, resourceID, snapshotID, userID uint) error {
+    if _, err := s.resources.editableResource(workspaceID, resourceID, userID); err != nil {
+        return err
+    }
+
+    var snapshot models.Snapshot
+    if err := s.db.Where("id = ? AND resource_id = ?", snapshotID, resourceID).First(&snapshot).Error; err != nil {
+        return ErrSnapshotNotFound
+    }
+    return s.remove(ctx, []models.Snapshot{snapshot})
+}
+
+// RunArchiver snapshots due resources every interval until ctx is cancelled.
+func (s *SnapshotService) RunArchiver(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if taken, err := s.ArchiveDue(ctx); err != nil {
+            log.Printf("Snapshot archiving failed: %v", err)
+        } else if taken > 0 {
+            log.Printf("Took %d page snapshot(s)", taken)
+        }
+        if removed, err := s.PruneOrphans(ctx); err != nil {
+            log.Printf("Snapshot cleanup failed: %v", err)
+        } else if removed > 0 {
+            log.Printf("Removed %d snapshot(s) of deleted resources", removed)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// ArchiveDue snapshots resources that opted in to archiving and were not
+// attempted within the policy's RefreshAfter, least recently attempted
+// first. Every attempt counts, so unchanged pages and pages that fail to
+// load wait their turn like the rest instead of holding up the batch.
+func (s *SnapshotService) ArchiveDue(ctx context.Context) (int, error) {
+    var resources []models.Resource
+    if err := s.db.Where("archive = ? AND (archive_attempted_at IS NULL OR archive_attempted_at < ?)",
+        true, time.Now().Add(-s.policy.RefreshAfter)).
+        Order("archive_attempted_at NULLS FIRST, id").
+        Limit(s.policy.BatchSize).
+        Find(&resources).Error; err != nil {
+        return 0, err
+    }
+
+    taken := 0
+    for i := range resources {
+        if ctx.Err() != nil {
+            break
+        }
+        if _, created, err := s.capture(ctx, &resources[i]); err != nil {
+            log.Printf("Snapshot of resource %d (%s) failed: %v", resources[i].ID, resources[i].URL, err)
+        } else if created {
+            taken++
+        }
+    }
+    return taken, nil
+}
+
+// PruneOrphans removes snapshots of resources that have been deleted.
+func (s *SnapshotService) PruneOrphans(ctx context.Context) (int, error) {
+    var snapshots []models.Snapshot
+    live := s.db.Model(&models.Resource{}).Select("id")
+    if err := s.db.Where("resource_id NOT IN (?)", live).
+        Limit(1000).
+        Find(&snapshots).Error; err != nil {
+        return 0, err
+    }
+    if err := s.remove(ctx, snapshots); err != nil {
+        return 0, err
+    }
+    return len(snapshots), nil
+}
+
+// capture fetches the page and stores a new snapshot unless the content is
+// the same as the latest one. It reports whether a snapshot was created.
+// The attempt is recorded first, so the archiver moves on even when the
+// fetch fails or nothing changed.
+func (s *SnapshotService) capture(ctx context.Context, resource *models.Resource) (*models.Snapshot, bool, error) {
+    if err := s.db.Model(resource).UpdateColumn("archive_attempted_at", time.Now()).Error; err != nil {
+        return nil, false, err
+    }
+
+    article, err := s.articles.Article(ctx, resource.URL)
+    if err != nil {
+        return nil, false, err
+    }
+
+    sum := sha256.Sum256([]byte(article.Text))
+    hash := hex.EncodeToString(sum[:])
+
+    var latest models.Snapshot
+    err = s.db.Where("resource_id = ?", resource.ID).Order("created_at DESC, id DESC").First(&latest).Error
+    if err == nil && latest.ContentHash == hash {
+        return &latest, false, nil
+    }
+    if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, false, err
+    }
+
+    now := time.Now()
+    prefix := fmt.Sprintf("snapshots/%d/%d-%s", resource.ID, now.UnixNano(), hash[:12])
+    document := []byte(snapshotDocument(article, now))
+    text := []byte(article.Text)
+
+    snapshot := models.Snapshot{
+        ResourceID:  resource.ID,
+        URL:         article.URL,
+        Title:       article.Title,
+        ContentHash: hash,
+        HTMLKey:     prefix + ".html",
+        TextKey:     prefix + ".txt",
+        Size:        len(document) + len(text),
+        SearchText:  searchText(article.Text),
+        CreatedAt:   now,
+    }
+    if err := s.store.Put(ctx, snapshot.HTMLKey, document); err != nil {
+        return nil, false, err
+    }
+    if err := s.store.Put(ctx, snapshot.TextKey, text); err != nil {
+        s.store.Delete(ctx, snapshot.HTMLKey)
+        return nil, false, err
+    }
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&snapshot).Error; err != nil {
+            return err
+        }
+        return refreshSearchVectors(tx, s.resources.index, []uint{resource.ID})
+    })
+    if err != nil {
+        s.store.Delete(ctx, snapshot.HTMLKey)
+        s.store.Delete(ctx, snapshot.TextKey)
+        return nil, false, err
+    }
+    reindexResources(s.db, s.resources.index, []uint{resource.ID})
+
+    if err := s.trim(ctx, resource.ID); err != nil {
+        log.Printf("Trimming snapshots of resource %d failed: %v", resource.ID, err)
+    }
+    return &snapshot, true, nil
+}
+
+// trim drops the oldest snapshots beyond the policy's Keep.
+func (s *SnapshotService) trim(ctx context.Context, resourceID uint) error {
+    if s.policy.Keep <= 0 {
+        return nil
+    }
+    var old []models.Snapshot
+    if err := s.db.Where("resource_id = ?", resourceID).
+        Order("created_at DESC, id DESC").
+        Offset(s.policy.Keep).
+        Find(&old).Error; err != nil {
+        return err
+    }
+    return s.remove(ctx, old)
+}
+
+// remove deletes snapshot rows and then their stored content. Content that
+// fails to delete is only logged; the row is what makes a snapshot visible.
+func (s *SnapshotService) remove(ctx context.Context, snapshots []models.Snapshot) error {
+    if len(snapshots) == 0 {
+        return nil
+    }
+    ids := make([]uint, len(snapshots))
+    resourceIDs := make([]uint, len(snapshots))
+    for i, snapshot := range snapshots {
+        ids[i] = snapshot.ID
+        resourceIDs[i] = snapshot.ResourceID
+    }
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Delete(&models.Snapshot{}, ids).Error; err != nil {
+            return err
+        }
+        // The page text searched is the latest remaining snapshot's
+        return refreshSearchVectors(tx, s.resources.index, resourceIDs)
+    })
+    if err != nil {
+        return err
+    }
+    reindexResources(s.db, s.resources.index, resourceIDs)
+    for _, snapshot := range snapshots {
+        for _, key := range []string{snapshot.HTMLKey, snapshot.TextKey} {
+            if err := s.store.Delete(ctx, key); err != nil {
+                log.Printf("Deleting snapshot content %s failed: %v", key, err)
+            }
+        }
+    }
+    return nil
+}
+
+// snapshotDocument wraps extracted content in a standalone HTML page that
+// credits the original.
+func snapshotDocument(article *webpage.Article, takenAt time.Time) string {
+    title := html.EscapeString(article.Title)
+    source := html.EscapeString(article.URL)
+    return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>` + title + `</title>
+<style>body{max-width:42rem;margin:2rem auto;padding:0 1rem;font:1.05rem/1.6 system-ui,sans-serif;color:#222}img{max-width:100%;height:auto}pre{overflow-x:auto}.snapshot-source{font-size:.85rem;color:#666;border-bottom:1px solid #ddd;padding-bottom:.5rem}</style>
+</head>
+<body>
+<p class="snapshot-source">Snapshot of <a href="` + source + `" rel="nofollow noopener">` + source + `</a> taken ` + takenAt.UTC().Format("2006-01-02 15:04 UTC") + `</p>
+<h1>` + title + `</h1>
+` + article.HTML + `
+</body>
+</html>
+`
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/storage"
+    "devlink-backend/internal/webpage"
+)
+
+// testArticles serves fixed page text per URL and fails for unknown URLs.
+type testArticles struct {
+    mu      sync.Mutex
+    pages   map[string]string
+    fetched []string
+}
+
+func (a *testArticles) Article(ctx context.Context, url string) (*webpage.Article, error) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+
+    a.fetched = append(a.fetched, url)
+    text, ok := a.pages[url]
+    if !ok {
+        return nil, errors.New("connection refused")
+    }
+    return &webpage.Article{URL: url, Title: "Page", HTML: "<p>" + text + "</p>", Text: text}, nil
+}
+
+func newTestSnapshotService(t *testing.T, articles ArticleSource, policy SnapshotPolicy) (*SnapshotService, *ResourceService, *models.User) {
+    t.Helper()
+
+    db := newTestDB(t)
+    store, err := storage.NewLocalStore(t.TempDir())
+    if err != nil {
+        t.Fatalf("NewLocalStore: %v", err)
+    }
+    resources := NewResourceService(db, nil, nil, nil)
+    return NewSnapshotService(db, resources, articles, store, policy), resources, createTestUser(t, db, "owner@example.com", true)
+}
+
+func TestArchiveDueMovesPastFailedAndUnchangedPages(t *testing.T) {
+    articles := &testArticles{pages: map[string]string{
+        "https://example.com/unchanged": "Same as ever",
+        "https://example.com/fresh":     "Brand new",
+    }}
+    policy := DefaultSnapshotPolicy()
+    policy.BatchSize = 1
+    snapshots, resources, user := newTestSnapshotService(t, articles, policy)
+
+    for _, url := range []string{"https://example.com/broken", "https://example.com/unchanged", "https://example.com/fresh"} {
+        if _, err := resources.CreateResource(context.Background(), 0, user.ID, CreateResourceRequest{Title: url, URL: url, Archive: true}); err != nil {
+            t.Fatalf("CreateResource(%s): %v", url, err)
+        }
+    }
+
+    for run := 0; run < 4; run++ {
+        if _, err := snapshots.ArchiveDue(context.Background()); err != nil {
+            t.Fatalf("ArchiveDue run %d: %v", run+1, err)
+        }
+    }
+
+    want := []string{"https://example.com/broken", "https://example.com/unchanged", "https://example.com/fresh"}
+    if len(articles.fetched) != len(want) {
+        t.Fatalf("fetched %v, want each page once: %v", articles.fetched, want)
+    }
+    for i := range want {
+        if articles.fetched[i] != want[i] {
+            t.Errorf("fetch %d = %s, want %s", i+1, articles.fetched[i], want[i])
+        }
+    }
+}
+
+func TestTakeSnapshotKeepsOnlyChangedContent(t *testing.T) {
+    articles := &testArticles{pages: map[string]string{"https://example.com/guide": "First edition"}}
+    snapshots, resources, user := newTestSnapshotService(t, articles, DefaultSnapshotPolicy())
+    ctx := context.Background()
+
+    resource, err := resources.CreateResource(ctx, 0, user.ID, CreateResourceRequest{Title: "Guide", URL: "https://example.com/guide"})
+    if err != nil {
+        t.Fatalf("CreateResource: %v", err)
+    }
+
+    take := func() (*models.Snapshot, bool) {
+        t.Helper()
+        snapshot, created, err := snapshots.TakeSnapshot(ctx, 0, resource.ID, user.ID)
+        if err != nil {
+            t.Fatalf("TakeSnapshot: %v", err)
+        }
+        return snapshot, created
+    }
+
+    first, created := take()
+    if !created {
+        t.Fatal("first snapshot was not created")
+    }
+    again, created := take()
+    if created || again.ID != first.ID {
+        t.Errorf("unchanged page: got snapshot %d (created %v), want the existing %d", again.ID, created, first.ID)
+    }
+
+    articles.mu.Lock()
+    articles.pages["https://example.com/guide"] = "Second edition"
+    articles.mu.Unlock()
+    second, created := take()
+    if !created || second.ID == first.ID || second.ContentHash == first.ContentHash {
+        t.Errorf("changed page: got snapshot %d (created %v), want a new one", second.ID, created)
+    }
+
+    list, err := snapshots.GetSnapshots(0, resource.ID, user.ID)
+    if err != nil {
+        t.Fatalf("GetSnapshots: %v", err)
+    }
+    if len(list) != 2 {
+        t.Fatalf("got %d snapshots, want 2", len(list))
+    }
+    if list[0].ID != second.ID {
+        t.Errorf("newest snapshot is %d, want %d", list[0].ID, second.ID)
+    }
+    _, text, err := snapshots.GetSnapshotContent(ctx, 0, resource.ID, second.ID, user.ID, true)
+    if err != nil || string(text) != "Second edition" {
+        t.Errorf("stored text = %q, %v, want the second edition", text, err)
+    }
+}
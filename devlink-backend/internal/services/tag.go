@@ -0,0 +1,400 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "log"
+    "strings"
+
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+const (
+    maxTagLength       = 50 // characters, not bytes
+    maxTagsPerResource = 20
+)
+
+var ErrTooManyTags = errors.New("a resource can have at most 20 tags")
+
+// TagList accepts tags either as a JSON array or as the comma-separated
+// string older clients send.
+type TagList []string
+
+func (l *TagList) UnmarshalJSON(data []byte) error {
+    var list []string
+    if err := json.Unmarshal(data, &list); err == nil {
+        *l = list
+        return nil
+    }
+
+    var raw string
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return errors.New("tags must be an array of strings or a comma-separated string")
+    }
+    *l = ParseTags(raw)
+    return nil
+}
+
+// NormalizeTag lowercases a tag and joins words with dashes, so "Web Dev"
+// and "web-dev" are the same tag.
+func NormalizeTag(name string) string {
+    name = strings.ToLower(strings.Join(strings.Fields(name), "-"))
+    name = strings.Trim(name, "#")
+    if runes := []rune(name); len(runes) > maxTagLength {
+        name = string(runes[:maxTagLength])
+    }
+    return name
+}
+
+// NormalizeTags normalizes every tag and drops empty and duplicate ones.
+func NormalizeTags(names []string) []string {
+    seen := make(map[string]bool, len(names))
+    normalized := make([]string, 0, len(names))
+    for _, name := range names {
+        name = NormalizeTag(name)
+        if name == "" || seen[name] {
+            continue
+        }
+        seen[name] = true
+        normalized = append(normalized, name)
+    }
+    return normalized
+}
+
+// ParseTags reads a free-text tag string, either comma-separated or a JSON
+// array, into normalized tag names.
+func ParseTags(raw string) []string {
+    raw = strings.TrimSpace(raw)
+    if raw == "" {
+        return nil
+    }
+
+    var list []string
+    if strings.HasPrefix(raw, "[") && json.Unmarshal([]byte(raw), &list) == nil {
+        return NormalizeTags(list)
+    }
+
+    return NormalizeTags(strings.Split(raw, ","))
+}
+
+// TagNames flattens loaded tags into their names.
+func TagNames(tags []models.Tag) []string {
+    names := make([]string, 0, len(tags))
+    for _, tag := range tags {
+        names = append(names, tag.Name)
+    }
+    return names
+}
+
+// findOrCreateTags returns the tag rows for the given normalized names,
+// creating missing ones. Concurrent creators are tolerated.
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+    if len(names) == 0 {
+        return []models.Tag{}, nil
+    }
+
+    newTags := make([]models.Tag, 0, len(names))
+    for _, name := range names {
+        newTags = append(newTags, models.Tag{Name: name})
+    }
+    if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+        return nil, err
+    }
+
+    var tags []models.Tag
+    if err := tx.Where("name IN ?", names).Find(&tags).Error; err != nil {
+        return nil, err
+    }
+    return tags, nil
+}
+
+// setResourceTags replaces the tags of a resource.
+func setResourceTags(tx *gorm.DB, resource *models.Resource, names []string) error {
+    names = NormalizeTags(names)
+    if len(names) > maxTagsPerResource {
+        return ErrTooManyTags
+    }
+
+    tags, err := findOrCreateTags(tx, names)
+    if err != nil {
+        return err
+    }
+
+    if err := tx.Model(resource).Association("Tags").Replace(tags); err != nil {
+        return err
+    }
+    resource.Tags = tags
+    return nil
+}
+
+// MigrateLegacyTags converts the old free-text resources.tags column into
+// tag rows, then drops the column so it only ever runs once.
+func MigrateLegacyTags(db *gorm.DB) error {
+    if !db.Migrator().HasColumn("resources", "tags") {
+        return nil
+    }
+
+    return db.Transaction(func(tx *gorm.DB) error {
+        var rows []struct {
+            ID   uint
+            Tags string
+        }
+        if err := tx.Table("resources").
+            Select("id, tags").
+            Where("tags IS NOT NULL AND tags <> ''").
+            Find(&rows).Error; err != nil {
+            return err
+        }
+
+        for _, row := range rows {
+            names := ParseTags(row.Tags)
+            if len(names) > maxTagsPerResource {
+                names = names[:maxTagsPerResource]
+            }
+
+            tags, err := findOrCreateTags(tx, names)
+            if err != nil {
+                return err
+            }
+
+            for _, tag := range tags {
+                if err := tx.Exec("INSERT INTO resource_tags (resource_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+                    row.ID, tag.ID).Error; err != nil {
+                    return err
+                }
+            }
+        }
+
+        log.Printf("Migrated tags of %d resource(s) to the tags table", len(rows))
+        return tx.Migrator().DropColumn("resources", "tags")
+    })
+}
+
+var (
+    ErrTagNotFound = errors.New("tag not found")
+    ErrTagExists   = errors.New("you already use a tag with that name, merge the tags instead")
+    ErrEmptyTag    = errors.New("tag name cannot be empty")
+    ErrNoMergeTags = errors.New("merge needs at least one source tag other than the target")
+)
+
+type TagService struct {
+    db    *gorm.DB
+    index SearchIndex // nil when searching with Postgres
+}
+
+// TagCount is a tag together with the number of resources carrying it.
+type TagCount struct {
+    Name  string `json:"name"`
+    Count int64  `json:"count"`
+}
+
+type TagFilters struct {
+    Prefix string `form:"prefix"` // autocomplete: only tags starting with this
+    Limit  int    `form:"limit,default=100" binding:"min=1,max=500"`
+}
+
+type RenameTagRequest struct {
+    Name string `json:"name" binding:"required"`
+}
+
+type MergeTagsRequest struct {
+    Sources []string `json:"sources" binding:"required,min=1"`
+    Target  string   `json:"target" binding:"required"`
+}
+
+func NewTagService(db *gorm.DB, index SearchIndex) *TagService {
+    return &TagService{
+        db:    db,
+        index: index,
+    }
+}
+
+// GetUserTags lists the tags used in the current space, most used first:
+// the user's personal resources, or every resource of the workspace.
+func (s *TagService) GetUserTags(workspaceID, userID uint, filters TagFilters) ([]TagCount, error) {
+    scope, err := s.scope(workspaceID, userID, false)
+    if err != nil {
+        return nil, err
+    }
+    return s.findTagCounts(s.tagCounts().Scopes(scope), filters)
+}
+
+// GetPopularTags lists the most used tags across the public feed, which
+// never includes workspace resources.
+func (s *TagService) GetPopularTags(filters TagFilters) ([]TagCount, error) {
+    query := s.tagCounts().
+        Where("resources.is_public = ? AND resources.hidden_at IS NULL AND resources.workspace_id IS NULL", true)
+    return s.findTagCounts(query, filters)
+}
+
+// RenameTag renames a tag on all of the resources the user may edit in the
+// current space. Other users' resources keep the old name.
+func (s *TagService) RenameTag(workspaceID, userID uint, oldName, newName string) (*TagCount, error) {
+    oldName, newName = NormalizeTag(oldName), NormalizeTag(newName)
+    if newName == "" {
+        return nil, ErrEmptyTag
+    }
+    scope, err := s.scope(workspaceID, userID, true)
+    if err != nil {
+        return nil, err
+    }
+    if newName == oldName {
+        return s.tagCount(scope, oldName)
+    }
+
+    inUse, err := s.tagCount(scope, newName)
+    if err != nil && !errors.Is(err, ErrTagNotFound) {
+        return nil, err
+    }
+    if inUse != nil {
+        return nil, ErrTagExists
+    }
+
+    if err := s.retag(scope, []string{oldName}, newName); err != nil {
+        return nil, err
+    }
+
+    return s.tagCount(scope, newName)
+}
+
+// MergeTags moves every source tag on the resources the user may edit in the
+// current space onto the target, e.g. "golang" into "go". Resources that had
+// both end up with one.
+func (s *TagService) MergeTags(workspaceID, userID uint, sources []string, target string) (*TagCount, error) {
+    target = NormalizeTag(target)
+    if target == "" {
+        return nil, ErrEmptyTag
+    }
+
+    var from []string
+    for _, source := range NormalizeTags(sources) {
+        if source != target {
+            from = append(from, source)
+        }
+    }
+    if len(from) == 0 {
+        return nil, ErrNoMergeTags
+    }
+
+    scope, err := s.scope(workspaceID, userID, true)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.retag(scope, from, target); err != nil {
+        return nil, err
+    }
+
+    return s.tagCount(scope, target)
+}
+
+// scope limits a query joined with resources to the current space. In the
+// personal space that is the user's own resources outside any workspace. In
+// a workspace members see every resource, but only admins may retag other
+// members' resources, the same rule as editing them.
+func (s *TagService) scope(workspaceID, userID uint, write bool) (func(*gorm.DB) *gorm.DB, error) {
+    if workspaceID == 0 {
+        return func(db *gorm.DB) *gorm.DB {
+            return db.Where("resources.workspace_id IS NULL AND resources.user_id = ?", userID)
+        }, nil
+    }
+
+    role, err := workspaceRole(s.db, workspaceID, userID)
+    if err != nil {
+        return nil, err
+    }
+    ownOnly := write && workspaceRank(role) < workspaceRank(models.WorkspaceRoleAdmin)
+    return func(db *gorm.DB) *gorm.DB {
+        db = db.Where("resources.workspace_id = ?", workspaceID)
+        if ownOnly {
+            db = db.Where("resources.user_id = ?", userID)
+        }
+        return db
+    }, nil
+}
+
+// retag replaces the given tags with target on the resources in scope.
+func (s *TagService) retag(scope func(*gorm.DB) *gorm.DB, from []string, target string) error {
+    var affected []uint
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        var sourceIDs []uint
+        if err := tx.Model(&models.Tag{}).Where("name IN ?", from).Pluck("id", &sourceIDs).Error; err != nil {
+            return err
+        }
+
+        owned := tx.Table("resource_tags").
+            Select("DISTINCT resource_tags.resource_id").
+            Joins("JOIN resources ON resources.id = resource_tags.resource_id AND resources.deleted_at IS NULL").
+            Where("resource_tags.tag_id IN ?", sourceIDs).
+            Scopes(scope)
+
+        if err := owned.Pluck("resource_tags.resource_id", &affected).Error; err != nil {
+            return err
+        }
+        if len(affected) == 0 {
+            return ErrTagNotFound
+        }
+
+        tags, err := findOrCreateTags(tx, []string{target})
+        if err != nil {
+            return err
+        }
+
+        if err := tx.Exec("INSERT INTO resource_tags (resource_id, tag_id) "+
+            "SELECT DISTINCT resource_id, ? FROM resource_tags "+
+            "WHERE resource_id IN ? AND tag_id IN ? "+
+            "ON CONFLICT DO NOTHING", tags[0].ID, affected, sourceIDs).Error; err != nil {
+            return err
+        }
+
+        if err := tx.Exec("DELETE FROM resource_tags WHERE resource_id IN ? AND tag_id IN ?",
+            affected, sourceIDs).Error; err != nil {
+            return err
+        }
+        return refreshSearchVectors(tx, s.index, affected)
+    })
+    if err != nil {
+        return err
+    }
+    reindexResources(s.db, s.index, affected)
+    return nil
+}
+
+func (s *TagService) tagCount(scope func(*gorm.DB) *gorm.DB, name string) (*TagCount, error) {
+    var counts []TagCount
+    if err := s.tagCounts().
+        Where("tags.name = ?", name).
+        Scopes(scope).
+        Find(&counts).Error; err != nil {
+        return nil, err
+    }
+    if len(counts) == 0 {
+        return nil, ErrTagNotFound
+    }
+    return &counts[0], nil
+}
+
+func (s *TagService) tagCounts() *gorm.DB {
+    return s.db.Table("resource_tags").
+        Select("tags.name AS name, COUNT(*) AS count").
+        Joins("JOIN tags ON tags.id = resource_tags.tag_id").
+        Joins("JOIN resources ON resources.id = resource_tags.resource_id AND resources.deleted_at IS NULL").
+        Group("tags.name")
+}
+
+func (s *TagService) findTagCounts(query *gorm.DB, filters TagFilters) ([]TagCount, error) {
+    if prefix := NormalizeTag(filters.Prefix); prefix != "" {
+        query = query.Where("tags.name LIKE ?", escapeLike(prefix)+"%")
+    }
+
+    counts := []TagCount{}
+    if err := query.Order("count DESC, tags.name").Limit(filters.Limit).Find(&counts).Error; err != nil {
+        return nil, err
+    }
+    return counts, nil
+}
+
+func escapeLike(value string) string {
+    return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+    "strings"
+    "testing"
+    "unicode/utf8"
+)
+
+func TestNormalizeTag(t *testing.T) {
+    tests := []struct {
+        name string
+        want string
+    }{
+        {"Web Dev", "web-dev"},
+        {"  #golang# ", "golang"},
+        {"Machine   Learning", "machine-learning"},
+        {strings.Repeat("a", 60), strings.Repeat("a", maxTagLength)},
+        {strings.Repeat("é", 60), strings.Repeat("é", maxTagLength)},
+        {"a" + strings.Repeat("日本", 30), "a" + strings.Repeat("日本", 24) + "日"},
+    }
+
+    for _, tt := range tests {
+        got := NormalizeTag(tt.name)
+        if got != tt.want {
+            t.Errorf("NormalizeTag(%q) = %q, want %q", tt.name, got, tt.want)
+        }
+        if !utf8.ValidString(got) {
+            t.Errorf("NormalizeTag(%q) returned invalid UTF-8", tt.name)
+        }
+    }
+}
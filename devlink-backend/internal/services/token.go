@@ -0,0 +1,35 @@
+package services
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+)
+
+// generateOpaqueToken returns a random URL-safe token together with the hash
+// that should be persisted in its place.
+func generateOpaqueToken() (string, string, error) {
+    buf := make([]byte, 32)
+    if _, err := rand.Read(buf); err != nil {
+        return "", "", err
+    }
+
+    token := base64.RawURLEncoding.EncodeToString(buf)
+    return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
+
+// generateID returns a random hex identifier, used for token families and
+// other values that must be unguessable but are not secrets themselves.
+func generateID() (string, error) {
+    buf := make([]byte, 16)
+    if _, err := rand.Read(buf); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(buf), nil
+}
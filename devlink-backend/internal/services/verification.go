@@ -0,0 +1,172 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    "devlink-backend/internal/mailer"
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+const emailVerificationTTL = 24 * time.Hour
+
+var (
+    ErrInvalidVerificationToken = errors.New("invalid or expired verification link")
+    ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+    ErrEmailTaken               = errors.New("user with this email already exists")
+)
+
+// SendVerificationEmail emails a fresh confirmation link for the user's
+// current address, invalidating any earlier links.
+func (s *AuthService) SendVerificationEmail(userID uint) error {
+    var user models.User
+    if err := s.db.First(&user, userID).Error; err != nil {
+        return errors.New("user not found")
+    }
+
+    if user.EmailVerifiedAt != nil {
+        return ErrEmailAlreadyVerified
+    }
+
+    return s.sendVerificationLink(&user, user.Email, "")
+}
+
+// ConfirmEmail consumes a verification token and marks the address verified.
+// Links sent for an email change also switch the account to the new address.
+func (s *AuthService) ConfirmEmail(token string) error {
+    var verification models.EmailVerificationToken
+    if err := s.db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), time.Now()).
+        First(&verification).Error; err != nil {
+        return ErrInvalidVerificationToken
+    }
+
+    if verification.OldEmail != "" {
+        return s.confirmEmailChange(&verification)
+    }
+
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        result := tx.Model(&models.EmailVerificationToken{}).
+            Where("id = ? AND used_at IS NULL", verification.ID).
+            Update("used_at", time.Now())
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrInvalidVerificationToken
+        }
+
+        // The link only proves ownership of the address it was sent to
+        result = tx.Model(&models.User{}).
+            Where("id = ? AND email = ?", verification.UserID, verification.Email).
+            Update("email_verified_at", time.Now())
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrInvalidVerificationToken
+        }
+
+        return nil
+    })
+}
+
+func (s *AuthService) confirmEmailChange(verification *models.EmailVerificationToken) error {
+    var user models.User
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        result := tx.Model(&models.EmailVerificationToken{}).
+            Where("id = ? AND used_at IS NULL", verification.ID).
+            Update("used_at", time.Now())
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            return ErrInvalidVerificationToken
+        }
+
+        // Someone may have registered the address since the link was sent
+        var taken int64
+        if err := tx.Model(&models.User{}).Where("email = ?", verification.Email).Count(&taken).Error; err != nil {
+            return err
+        }
+        if taken > 0 {
+            return ErrEmailTaken
+        }
+
+        // Only apply the change if the account still has the address it was requested from
+        if err := tx.Where("id = ? AND email = ?", verification.UserID, verification.OldEmail).
+            First(&user).Error; err != nil {
+            return ErrInvalidVerificationToken
+        }
+
+        return tx.Model(&user).Updates(map[string]interface{}{
+            "email":             verification.Email,
+            "email_verified_at": time.Now(),
+        }).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    // Let the previous address know, in case the change wasn't the owner's doing
+    if err := s.mailer.Send(mailer.Message{
+        To:      verification.OldEmail,
+        Subject: "Your DevLink email address was changed",
+        Body: fmt.Sprintf("Hi %s,\n\nThe email address on your DevLink account was changed to %s.\n\n"+
+            "If you didn't make this change, reset your password and contact support.\n",
+            user.Name, verification.Email),
+    }); err != nil {
+        log.Printf("Failed to notify %s about email change for user %d: %v", verification.OldEmail, user.ID, err)
+    }
+
+    return nil
+}
+
+// sendVerificationLink emails a confirmation link for email. A non-empty
+// oldEmail turns the link into an email change request away from oldEmail.
+func (s *AuthService) sendVerificationLink(user *models.User, email, oldEmail string) error {
+    token, tokenHash, err := generateOpaqueToken()
+    if err != nil {
+        return err
+    }
+
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(&models.EmailVerificationToken{}).
+            Where("user_id = ? AND used_at IS NULL", user.ID).
+            Update("used_at", time.Now()).Error; err != nil {
+            return err
+        }
+
+        return tx.Create(&models.EmailVerificationToken{
+            UserID:    user.ID,
+            Email:     email,
+            OldEmail:  oldEmail,
+            TokenHash: tokenHash,
+            ExpiresAt: time.Now().Add(emailVerificationTTL),
+        }).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    link := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, token)
+    if oldEmail != "" {
+        return s.mailer.Send(mailer.Message{
+            To:      email,
+            Subject: "Confirm your new DevLink email address",
+            Body: fmt.Sprintf("Hi %s,\n\nYou asked to change the email address on your DevLink account to this one.\n"+
+                "Open the link below to confirm the change:\n\n%s\n\n"+
+                "The link expires in 24 hours. Until then you keep signing in with %s.\n",
+                user.Name, link, oldEmail),
+        })
+    }
+    return s.mailer.Send(mailer.Message{
+        To:      email,
+        Subject: "Confirm your DevLink email address",
+        Body: fmt.Sprintf("Hi %s,\n\nPlease confirm this email address for your DevLink account by opening:\n\n%s\n\n"+
+            "The link expires in 24 hours. You need a verified address before you can share resources publicly.\n",
+            user.Name, link),
+    })
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+    "errors"
+    "regexp"
+    "strconv"
+    "strings"
+
+    "devlink-backend/internal/models"
+    "gorm.io/gorm"
+)
+
+var (
+    ErrWorkspaceNotFound      = errors.New("workspace not found or access denied")
+    ErrWorkspaceRole          = errors.New("your role in this workspace does not allow that")
+    ErrInvalidWorkspaceRole   = errors.New("role must be one of member, admin, owner")
+    ErrInvalidWorkspaceSlug   = errors.New("slug may only contain lowercase letters, digits and single dashes, and needs a letter")
+    ErrWorkspaceSlugTaken     = errors.New("that slug is already in use")
+    ErrAlreadyWorkspaceMember = errors.New("that user is already a member of this workspace")
+    ErrLastWorkspaceOwner     = errors.New("a workspace needs at least one owner")
+    ErrMemberEmailUnverified  = errors.New("that user has not confirmed their email address yet")
+)
+
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// validWorkspaceSlug also wants a letter, so a slug can never be mistaken
+// for a workspace ID in the X-Workspace header.
+func validWorkspaceSlug(slug string) bool {
+    return workspaceSlugPattern.MatchString(slug) && strings.ContainsAny(slug, "abcdefghijklmnopqrstuvwxyz")
+}
+
+type WorkspaceService struct {
+    db    *gorm.DB
+    index SearchIndex
+}
+
+type CreateWorkspaceRequest struct {
+    Name string `json:"name" binding:"required,max=100"`
+    Slug string `json:"slug" binding:"max=50"` // derived from the name when empty
+}
+
+type UpdateWorkspaceRequest struct {
+    Name *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+    Slug *string `json:"slug,omitempty" binding:"omitempty,max=50"`
+}
+
+type AddWorkspaceMemberRequest struct {
+    Email string `json:"email" binding:"required,email"`
+    Role  string `json:"role" binding:"required"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+    Role string `json:"role" binding:"required"`
+}
+
+// WorkspaceSummary is a workspace together with the requesting user's role.
+type WorkspaceSummary struct {
+    models.Workspace
+    Role string `json:"role"`
+}
+
+// WorkspaceMemberInfo is one member of a workspace.
+type WorkspaceMemberInfo struct {
+    UserID uint   `json:"user_id"`
+    Name   string `json:"name"`
+    Email  string `json:"email"`
+    Role   string `json:"role"`
+}
+
+func NewWorkspaceService(db *gorm.DB, index SearchIndex) *WorkspaceService {
+    return &WorkspaceService{
+        db:    db,
+        index: index,
+    }
+}
+
+// workspaceRank orders workspace roles; zero means not a member.
+func workspaceRank(role string) int {
+    switch role {
+    case models.WorkspaceRoleOwner:
+        return 3
+    case models.WorkspaceRoleAdmin:
+        return 2
+    case models.WorkspaceRoleMember:
+        return 1
+    default:
+        return 0
+    }
+}
+
+// workspaceRole returns the user's role in a workspace. Non-members and
+// deleted workspaces both get ErrWorkspaceNotFound.
+func workspaceRole(db *gorm.DB, workspaceID, userID uint) (string, error) {
+    var member models.WorkspaceMember
+    err := db.Joins("JOIN workspaces ON workspaces.id = workspace_members.workspace_id AND workspaces.deleted_at IS NULL").
+        Where("workspace_members.workspace_id = ? AND workspace_members.user_id = ?", workspaceID, userID).
+        First(&member).Error
+    if err != nil {
+        return "", ErrWorkspaceNotFound
+    }
+    return member.Role, nil
+}
+
+// memberWorkspaceIDs selects the live workspaces the user belongs to.
+func memberWorkspaceIDs(db *gorm.DB, userID uint) *gorm.DB {
+    return db.Table("workspace_members").
+        Select("workspace_members.workspace_id").
+        Joins("JOIN workspaces ON workspaces.id = workspace_members.workspace_id AND workspaces.deleted_at IS NULL").
+        Where("workspace_members.user_id = ?", userID)
+}
+
+// Resolve finds a workspace by slug or ID for one of its members. It backs
+// the X-Workspace request header. Slugs win, so workspaces that got an
+// all-digit slug before slugs needed a letter still resolve by it.
+func (s *WorkspaceService) Resolve(ref string, userID uint) (*models.Workspace, string, error) {
+    var workspace models.Workspace
+    err := s.db.Where("slug = ?", strings.ToLower(ref)).First(&workspace).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        if id, parseErr := strconv.ParseUint(ref, 10, 32); parseErr == nil {
+            err = s.db.Where("id = ?", id).First(&workspace).Error
+        }
+    }
+    if err != nil {
+        return nil, "", ErrWorkspaceNotFound
+    }
+
+    role, err := workspaceRole(s.db, workspace.ID, userID)
+    if err != nil {
+        return nil, "", err
+    }
+    return &workspace, role, nil
+}
+
+func (s *WorkspaceService) authorize(workspaceID, userID uint, minRole string) (*models.Workspace, string, error) {
+    role, err := workspaceRole(s.db, workspaceID, userID)
+    if err != nil {
+        return nil, "", err
+    }
+    if workspaceRank(role) < workspaceRank(minRole) {
+        return nil, "", ErrWorkspaceRole
+    }
+
+    var workspace models.Workspace
+    if err := s.db.First(&workspace, workspaceID).Error; err != nil {
+        return nil, "", ErrWorkspaceNotFound
+    }
+    return &workspace, role, nil
+}
+
+func (s *WorkspaceService) GetUserWorkspaces(userID uint) ([]WorkspaceSummary, error) {
+    var workspaces []WorkspaceSummary
+    err := s.db.Model(&models.Workspace{}).
+        Select("workspaces.*, workspace_members.role AS role").
+        Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+        Where("workspace_members.user_id = ?", userID).
+        Order("workspaces.name").
+        Find(&workspaces).Error
+    if err != nil {
+        return nil, err
+    }
+    return workspaces, nil
+}
+
+func (s *WorkspaceService) GetWorkspace(workspaceID, userID uint) (*models.Workspace, string, error) {
+    return s.authorize(workspaceID, userID, models.WorkspaceRoleMember)
+}
+
+// CreateWorkspace makes a new workspace with the user as its owner.
+func (s *WorkspaceService) CreateWorkspace(userID uint, req CreateWorkspaceRequest) (*models.Workspace, error) {
+    slug := req.Slug
+    if slug == "" {
+        slug = slugify(req.Name)
+    }
+    if !validWorkspaceSlug(slug) {
+        return nil, ErrInvalidWorkspaceSlug
+    }
+
+    workspace := models.Workspace{
+        Name:        req.Name,
+        Slug:        slug,
+        CreatedByID: userID,
+    }
+
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        if err := checkSlugFree(tx, slug, 0); err != nil {
+            return err
+        }
+        if err := tx.Create(&workspace).Error; err != nil {
+            return err
+        }
+        return tx.Create(&models.WorkspaceMember{
+            WorkspaceID: workspace.ID,
+            UserID:      userID,
+            Role:        models.WorkspaceRoleOwner,
+        }).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return &workspace, nil
+}
+
+func (s *WorkspaceService) UpdateWorkspace(workspaceID, userID uint, req UpdateWorkspaceRequest) (*models.Workspace, error) {
+    workspace, _, err := s.authorize(workspaceID, userID, models.WorkspaceRoleAdmin)
+    if err != nil {
+        return nil, err
+    }
+
+    // Update only provided fields
+    updates := make(map[string]interface{})
+    if req.Name != nil {
+        updates["name"] = *req.Name
+    }
+    if req.Slug != nil {
+        if !validWorkspaceSlug(*req.Slug) {
+            return nil, ErrInvalidWorkspaceSlug
+        }
+        if err := checkSlugFree(s.db, *req.Slug, workspace.ID); err != nil {
+            return nil, err
+        }
+        updates["slug"] = *req.Slug
+    }
+
+    if len(updates) > 0 {
+        if err := s.db.Model(workspace).Updates(updates).Error; err != nil {
+            return nil, err
+        }
+    }
+
+    return workspace, nil
+}
+
+// DeleteWorkspace removes a workspace along with every resource saved in it.
+func (s *WorkspaceService) DeleteWorkspace(workspaceID, userID uint) error {
+    workspace, _, err := s.authorize(workspaceID, userID, models.WorkspaceRoleOwner)
+    if err != nil {
+        return err
+    }
+
+    var resourceIDs []uint
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Model(&models.Resource{}).Where("workspace_id = ?", workspace.ID).
+            Pluck("id", &resourceIDs).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("workspace_id = ?", workspace.ID).Delete(&models.Resource{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("workspace_id = ?", workspace.ID).Delete(&models.WorkspaceMember{}).Error; err != nil {
+            return err
+        }
+        return tx.Delete(workspace).Error
+    })
+    if err != nil {
+        return err
+    }
+
+    if s.index != nil {
+        for _, id := range resourceIDs {
+            s.index.Delete(id)
+        }
+    }
+    return nil
+}
+
+func (s *WorkspaceService) GetMembers(workspaceID, userID uint) ([]WorkspaceMemberInfo, error) {
+    if _, _, err := s.authorize(workspaceID, userID, models.WorkspaceRoleMember); err != nil {
+        return nil, err
+    }
+
+    var rows []models.WorkspaceMember
+    if err := s.db.Where("workspace_id = ?", workspaceID).Preload("User").Order("created_at").Find(&rows).Error; err != nil {
+        return nil, err
+    }
+
+    members := make([]WorkspaceMemberInfo, 0, len(rows))
+    for _, row := range rows {
+        members = append(members, WorkspaceMemberInfo{
+            UserID: row.UserID,
+            Name:   row.User.Name,
+            Email:  row.User.Email,
+            Role:   row.Role,
+        })
+    }
+    return members, nil
+}
+
+// AddMember adds an existing DevLink user to the workspace. Only owners can
+// hand out the owner role. The account must have confirmed its address, so
+// registering someone else's email never earns a seat in their team.
+func (s *WorkspaceService) AddMember(workspaceID, userID uint, req AddWorkspaceMemberRequest) (*WorkspaceMemberInfo, error) {
+    if workspaceRank(req.Role) == 0 {
+        return nil, ErrInvalidWorkspaceRole
+    }
+
+    _, role, err := s.authorize(workspaceID, userID, models.WorkspaceRoleAdmin)
+    if err != nil {
+        return nil, err
+    }
+    if workspaceRank(req.Role) > workspaceRank(role) {
+        return nil, ErrWorkspaceRole
+    }
+
+    var user models.User
+    if err := s.db.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(req.Email))).First(&user).Error; err != nil {
+        return nil, ErrUserNotFound
+    }
+    if user.EmailVerifiedAt == nil {
+        return nil, ErrMemberEmailUnverified
+    }
+
+    var existing int64
+    if err := s.db.Model(&models.WorkspaceMember{}).
+        Where("workspace_id = ? AND user_id = ?", workspaceID, user.ID).
+        Count(&existing).Error; err != nil {
+        return nil, err
+    }
+    if existing > 0 {
+        return nil, ErrAlreadyWorkspaceMember
+    }
+
+    if err := s.db.Create(&models.WorkspaceMember{
+        WorkspaceID: workspaceID,
+        UserID:      user.ID,
+        Role:        req.Role,
+    }).Error; err != nil {
+        return nil, err
+    }
+
+    return &WorkspaceMemberInfo{
+        UserID: user.ID,
+        Name:   user.Name,
+        Email:  user.Email,
+        Role:   req.Role,
+    }, nil
+}
+
+// UpdateMemberRole changes a member's role. Admins can manage members and
+// admins; only owners can promote to or demote from owner.
+func (s *WorkspaceService) UpdateMemberRole(workspaceID, userID, memberUserID uint, newRole string) error {
+    if workspaceRank(newRole) == 0 {
+        return ErrInvalidWorkspaceRole
+    }
+
+    _, role, err := s.authorize(workspaceID, userID, models.WorkspaceRoleAdmin)
+    if err != nil {
+        return err
+    }
+
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        var member models.WorkspaceMember
+        if err := tx.Where("workspace_id = ? AND user_id = ?", workspaceID, memberUserID).First(&member).Error; err != nil {
+            return ErrMemberNotFound
+        }
+        if (member.Role == models.WorkspaceRoleOwner || newRole == models.WorkspaceRoleOwner) &&
+            role != models.WorkspaceRoleOwner {
+            return ErrWorkspaceRole
+        }
+        if member.Role == models.WorkspaceRoleOwner && newRole != models.WorkspaceRoleOwner {
+            if err := checkOtherOwner(tx, workspaceID, memberUserID); err != nil {
+                return err
+            }
+        }
+        return tx.Model(&member).Update("role", newRole).Error
+    })
+}
+
+// RemoveMember takes a user out of the workspace. Admins can remove
+// members, owners anyone, and every member can leave on their own.
+func (s *WorkspaceService) RemoveMember(workspaceID, userID, memberUserID uint) error {
+    minRole := models.WorkspaceRoleAdmin
+    if memberUserID == userID {
+        minRole = models.WorkspaceRoleMember
+    }
+    _, role, err := s.authorize(workspaceID, userID, minRole)
+    if err != nil {
+        return err
+    }
+
+    return s.db.Transaction(func(tx *gorm.DB) error {
+        var member models.WorkspaceMember
+        if err := tx.Where("workspace_id = ? AND user_id = ?", workspaceID, memberUserID).First(&member).Error; err != nil {
+            return ErrMemberNotFound
+        }
+        if member.Role == models.WorkspaceRoleOwner {
+            if memberUserID != userID && role != models.WorkspaceRoleOwner {
+                return ErrWorkspaceRole
+            }
+            if err := checkOtherOwner(tx, workspaceID, memberUserID); err != nil {
+                return err
+            }
+        }
+        return tx.Delete(&member).Error
+    })
+}
+
+func checkOtherOwner(tx *gorm.DB, workspaceID, userID uint) error {
+    var owners int64
+    if err := tx.Model(&models.WorkspaceMember{}).
+        Where("workspace_id = ? AND role = ? AND user_id <> ?", workspaceID, models.WorkspaceRoleOwner, userID).
+        Count(&owners).Error; err != nil {
+        return err
+    }
+    if owners == 0 {
+        return ErrLastWorkspaceOwner
+    }
+    return nil
+}
+
+// checkSlugFree also counts deleted workspaces, whose slug stays in the
+// unique index.
+func checkSlugFree(db *gorm.DB, slug string, workspaceID uint) error {
+    var taken int64
+    if err := db.Unscoped().Model(&models.Workspace{}).
+        Where("slug = ? AND id <> ?", slug, workspaceID).
+        Count(&taken).Error; err != nil {
+        return err
+    }
+    if taken > 0 {
+        return ErrWorkspaceSlugTaken
+    }
+    return nil
+}
+
+// slugify turns a name into a URL-friendly slug, e.g. "Platform Team" into
+// "platform-team".
+func slugify(name string) string {
+    var b strings.Builder
+    dash := false
+    for _, r := range strings.ToLower(name) {
+        if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+            if dash && b.Len() > 0 {
+                b.WriteByte('-')
+            }
+            b.WriteRune(r)
+            dash = false
+        } else {
+            dash = true
+        }
+    }
+    slug := b.String()
+    if slug != "" && !strings.ContainsAny(slug, "abcdefghijklmnopqrstuvwxyz") {
+        slug = "workspace-" + slug // "2024" would read as an ID
+    }
+    if len(slug) > 50 {
+        slug = strings.TrimRight(slug[:50], "-")
+    }
+    return slug
+}
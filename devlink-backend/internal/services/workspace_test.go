@@ -0,0 +1,360 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "strconv"
+    "testing"
+    "time"
+
+    "devlink-backend/internal/models"
+    "devlink-backend/internal/searchindex"
+    "gorm.io/gorm"
+)
+
+// workspaceFixture is a workspace with one member who saved a public and a
+// private resource in it, and an outsider with a public resource of their own.
+type workspaceFixture struct {
+    db        *gorm.DB
+    resources *ResourceService
+    workspace *models.Workspace
+    member    *models.User
+    outsider  *models.User
+    shared    *models.Resource // public within the workspace
+    private   *models.Resource
+    own       *models.Resource // the outsider's public resource
+}
+
+func newWorkspaceFixture(t *testing.T) *workspaceFixture {
+    t.Helper()
+
+    db := newTestDB(t)
+    f := &workspaceFixture{
+        db:        db,
+        resources: NewResourceService(db, nil, nil, searchindex.New()),
+        member:    createTestUser(t, db, "member@example.com", true),
+        outsider:  createTestUser(t, db, "outsider@example.com", true),
+    }
+
+    workspace, err := NewWorkspaceService(db, nil).CreateWorkspace(f.member.ID, CreateWorkspaceRequest{Name: "Platform Team"})
+    if err != nil {
+        t.Fatalf("CreateWorkspace: %v", err)
+    }
+    f.workspace = workspace
+
+    f.shared = f.createResource(t, workspace.ID, f.member.ID, "Kubernetes runbook", "internal-ops", true)
+    f.private = f.createResource(t, workspace.ID, f.member.ID, "Kubernetes secrets", "internal-ops", false)
+    f.own = f.createResource(t, 0, f.outsider.ID, "Kubernetes basics", "tutorial", true)
+    return f
+}
+
+func (f *workspaceFixture) createResource(t *testing.T, workspaceID, userID uint, title, tag string, public bool) *models.Resource {
+    t.Helper()
+
+    resource, err := f.resources.CreateResource(context.Background(), workspaceID, userID, CreateResourceRequest{
+        Title:    title,
+        URL:      "https://example.com/" + tag,
+        Tags:     TagList{tag},
+        IsPublic: &public,
+    })
+    if err != nil {
+        t.Fatalf("CreateResource(%q): %v", title, err)
+    }
+    return resource
+}
+
+func resourceIDs(resources []models.Resource) map[uint]bool {
+    ids := make(map[uint]bool, len(resources))
+    for _, resource := range resources {
+        ids[resource.ID] = true
+    }
+    return ids
+}
+
+func TestWorkspaceResourcesHiddenFromOutsiders(t *testing.T) {
+    f := newWorkspaceFixture(t)
+
+    for _, resource := range []*models.Resource{f.shared, f.private} {
+        if _, err := f.resources.GetResourceByID(0, resource.ID, f.outsider.ID); err == nil {
+            t.Errorf("outsider read workspace resource %q from the personal space", resource.Title)
+        }
+        if _, err := f.resources.GetResourceByID(f.workspace.ID, resource.ID, f.outsider.ID); !errors.Is(err, ErrWorkspaceNotFound) {
+            t.Errorf("outsider read workspace resource %q: err = %v, want ErrWorkspaceNotFound", resource.Title, err)
+        }
+    }
+
+    if _, err := f.resources.GetUserResources(f.workspace.ID, f.outsider.ID, ResourceFilters{Page: 1, Limit: 20}); !errors.Is(err, ErrWorkspaceNotFound) {
+        t.Errorf("outsider listed the workspace: err = %v, want ErrWorkspaceNotFound", err)
+    }
+
+    page, err := f.resources.GetPublicResources(ResourceFilters{Page: 1, Limit: 20})
+    if err != nil {
+        t.Fatalf("GetPublicResources: %v", err)
+    }
+    if ids := resourceIDs(page.Resources); ids[f.shared.ID] || ids[f.private.ID] || !ids[f.own.ID] {
+        t.Errorf("public feed has %v, want only the outsider's resource %d", ids, f.own.ID)
+    }
+
+    // The member sees the workspace in it, and only their personal resources outside
+    page, err = f.resources.GetUserResources(f.workspace.ID, f.member.ID, ResourceFilters{Page: 1, Limit: 20})
+    if err != nil {
+        t.Fatalf("GetUserResources: %v", err)
+    }
+    if ids := resourceIDs(page.Resources); len(ids) != 2 || !ids[f.shared.ID] || !ids[f.private.ID] {
+        t.Errorf("member's workspace listing has %v, want both workspace resources", ids)
+    }
+    page, err = f.resources.GetUserResources(0, f.member.ID, ResourceFilters{Page: 1, Limit: 20})
+    if err != nil {
+        t.Fatalf("GetUserResources: %v", err)
+    }
+    if len(page.Resources) != 0 {
+        t.Errorf("member's personal listing has %d workspace resources", len(page.Resources))
+    }
+}
+
+func TestWorkspaceSearchHiddenFromOutsiders(t *testing.T) {
+    f := newWorkspaceFixture(t)
+
+    page, err := f.resources.GetPublicResources(ResourceFilters{Query: "kubernetes", Page: 1, Limit: 20})
+    if err != nil {
+        t.Fatalf("GetPublicResources: %v", err)
+    }
+    if ids := resourceIDs(page.Resources); len(ids) != 1 || !ids[f.own.ID] {
+        t.Errorf("public search found %v, want only the outsider's resource %d", ids, f.own.ID)
+    }
+    if page.Facets != nil {
+        for _, tag := range page.Facets.Tags {
+            if tag.Value == "internal-ops" {
+                t.Error("public search facets list a workspace tag")
+            }
+        }
+    }
+
+    page, err = f.resources.GetUserResources(0, f.outsider.ID, ResourceFilters{Query: "runbook", Page: 1, Limit: 20})
+    if err != nil {
+        t.Fatalf("GetUserResources: %v", err)
+    }
+    if len(page.Resources) != 0 {
+        t.Errorf("outsider's search found %d workspace resources", len(page.Resources))
+    }
+}
+
+func TestWorkspaceTagsHiddenFromOutsiders(t *testing.T) {
+    f := newWorkspaceFixture(t)
+
+    tags, err := NewTagService(f.db, nil).GetPopularTags(TagFilters{Limit: 100})
+    if err != nil {
+        t.Fatalf("GetPopularTags: %v", err)
+    }
+    if len(tags) != 1 || tags[0].Name != "tutorial" {
+        t.Errorf("popular tags = %v, want only the outsider's tag", tags)
+    }
+}
+
+func TestTagCurationStaysInItsSpace(t *testing.T) {
+    f := newWorkspaceFixture(t)
+    tags := NewTagService(f.db, nil)
+    personal := f.createResource(t, 0, f.member.ID, "Home lab notes", "internal-ops", false)
+
+    personalTags, err := tags.GetUserTags(0, f.member.ID, TagFilters{Limit: 100})
+    if err != nil {
+        t.Fatalf("GetUserTags: %v", err)
+    }
+    if len(personalTags) != 1 || personalTags[0].Count != 1 {
+        t.Errorf("personal tags = %v, want only the personal resource counted", personalTags)
+    }
+
+    // Renaming in the personal space leaves the workspace's tags alone
+    if _, err := tags.RenameTag(0, f.member.ID, "internal-ops", "ops"); err != nil {
+        t.Fatalf("RenameTag: %v", err)
+    }
+    workspaceTags, err := tags.GetUserTags(f.workspace.ID, f.member.ID, TagFilters{Limit: 100})
+    if err != nil {
+        t.Fatalf("GetUserTags in workspace: %v", err)
+    }
+    if len(workspaceTags) != 1 || workspaceTags[0].Name != "internal-ops" || workspaceTags[0].Count != 2 {
+        t.Errorf("workspace tags = %v, want internal-ops on both workspace resources", workspaceTags)
+    }
+    var renamed models.Resource
+    if err := f.db.Preload("Tags").First(&renamed, personal.ID).Error; err != nil {
+        t.Fatalf("load resource: %v", err)
+    }
+    if names := TagNames(renamed.Tags); len(names) != 1 || names[0] != "ops" {
+        t.Errorf("personal resource tags = %v, want [ops]", names)
+    }
+
+    if _, err := tags.GetUserTags(f.workspace.ID, f.outsider.ID, TagFilters{Limit: 100}); !errors.Is(err, ErrWorkspaceNotFound) {
+        t.Errorf("outsider GetUserTags: err = %v, want ErrWorkspaceNotFound", err)
+    }
+    if _, err := tags.MergeTags(f.workspace.ID, f.outsider.ID, []string{"internal-ops"}, "spam"); !errors.Is(err, ErrWorkspaceNotFound) {
+        t.Errorf("outsider MergeTags: err = %v, want ErrWorkspaceNotFound", err)
+    }
+}
+
+func TestWorkspaceResourcesHiddenFromModerators(t *testing.T) {
+    f := newWorkspaceFixture(t)
+    authService, _ := newTestAuthService(f.db)
+
+    resources, total, err := NewAdminService(f.db, authService, nil).GetPublicResources(AdminResourceFilters{Page: 1, Limit: 20})
+    if err != nil {
+        t.Fatalf("GetPublicResources: %v", err)
+    }
+    if ids := resourceIDs(resources); total != 1 || !ids[f.own.ID] {
+        t.Errorf("moderation listing has %v (total %d), want only the outsider's resource", ids, total)
+    }
+}
+
+func TestWorkspaceResourcesHiddenInSharedCollections(t *testing.T) {
+    f := newWorkspaceFixture(t)
+    collections := NewCollectionService(f.db, &testMailer{}, "http://localhost:3000")
+
+    // The member files workspace resources in a personal collection shared with the outsider
+    collection, err := collections.CreateCollection(f.member.ID, CreateCollectionRequest{Name: "Ops"})
+    if err != nil {
+        t.Fatalf("CreateCollection: %v", err)
+    }
+    if err := collections.AddResources(collection.ID, f.member.ID, []uint{f.shared.ID, f.private.ID}); err != nil {
+        t.Fatalf("AddResources: %v", err)
+    }
+    if err := f.db.Create(&models.CollectionMember{
+        CollectionID: collection.ID,
+        UserID:       f.outsider.ID,
+        Role:         models.CollectionViewer,
+        InvitedByID:  f.member.ID,
+    }).Error; err != nil {
+        t.Fatalf("add collection member: %v", err)
+    }
+
+    page, err := f.resources.GetUserResources(0, f.outsider.ID, ResourceFilters{Collection: &collection.ID, Page: 1, Limit: 20})
+    if err != nil {
+        t.Fatalf("GetUserResources: %v", err)
+    }
+    if len(page.Resources) != 0 {
+        t.Errorf("outsider sees %d workspace resources through a shared collection", len(page.Resources))
+    }
+    if _, err := f.resources.GetResourceByID(0, f.private.ID, f.outsider.ID); err == nil {
+        t.Error("outsider read a private workspace resource through a shared collection")
+    }
+}
+
+func TestFormerMembersCannotFileWorkspaceResources(t *testing.T) {
+    f := newWorkspaceFixture(t)
+    collections := NewCollectionService(f.db, &testMailer{}, "http://localhost:3000")
+
+    collection, err := collections.CreateCollection(f.member.ID, CreateCollectionRequest{Name: "Ops"})
+    if err != nil {
+        t.Fatalf("CreateCollection: %v", err)
+    }
+    if err := f.db.Where("workspace_id = ? AND user_id = ?", f.workspace.ID, f.member.ID).
+        Delete(&models.WorkspaceMember{}).Error; err != nil {
+        t.Fatalf("remove member: %v", err)
+    }
+
+    if err := collections.AddResources(collection.ID, f.member.ID, []uint{f.private.ID}); !errors.Is(err, ErrResourceNotFound) {
+        t.Errorf("AddResources after leaving: err = %v, want ErrResourceNotFound", err)
+    }
+}
+
+func TestAddMemberNeedsAConfirmedAddress(t *testing.T) {
+    f := newWorkspaceFixture(t)
+    workspaces := NewWorkspaceService(f.db, nil)
+    squatter := createTestUser(t, f.db, "newhire@example.com", false)
+
+    req := AddWorkspaceMemberRequest{Email: "newhire@example.com", Role: models.WorkspaceRoleMember}
+    if _, err := workspaces.AddMember(f.workspace.ID, f.member.ID, req); !errors.Is(err, ErrMemberEmailUnverified) {
+        t.Fatalf("AddMember with an unconfirmed address: err = %v, want ErrMemberEmailUnverified", err)
+    }
+    if _, err := workspaceRole(f.db, f.workspace.ID, squatter.ID); err == nil {
+        t.Fatal("unconfirmed account became a workspace member")
+    }
+
+    if err := f.db.Model(squatter).Update("email_verified_at", time.Now()).Error; err != nil {
+        t.Fatalf("verify: %v", err)
+    }
+    if _, err := workspaces.AddMember(f.workspace.ID, f.member.ID, req); err != nil {
+        t.Errorf("AddMember after confirming: %v", err)
+    }
+}
+
+func TestWorkspaceSlugs(t *testing.T) {
+    tests := []struct {
+        name string
+        want string
+    }{
+        {"Platform Team", "platform-team"},
+        {"  Ops & SRE!! ", "ops-sre"},
+        {"2024", "workspace-2024"},
+        {"42 - 7", "workspace-42-7"},
+        {"!!!", ""},
+    }
+    for _, tt := range tests {
+        got := slugify(tt.name)
+        if got != tt.want {
+            t.Errorf("slugify(%q) = %q, want %q", tt.name, got, tt.want)
+        }
+        if got != "" && !validWorkspaceSlug(got) {
+            t.Errorf("slugify(%q) = %q, which is not a valid slug", tt.name, got)
+        }
+    }
+
+    for _, slug := range []string{"12", "1-2", "Team", "team--a", "-team", ""} {
+        if validWorkspaceSlug(slug) {
+            t.Errorf("validWorkspaceSlug(%q) = true, want false", slug)
+        }
+    }
+}
+
+func TestResolvePrefersSlugs(t *testing.T) {
+    f := newWorkspaceFixture(t)
+    workspaces := NewWorkspaceService(f.db, nil)
+
+    // A workspace from before slugs needed a letter, whose slug is another workspace's ID
+    legacy, err := workspaces.CreateWorkspace(f.member.ID, CreateWorkspaceRequest{Name: "Legacy"})
+    if err != nil {
+        t.Fatalf("CreateWorkspace: %v", err)
+    }
+    ref := strconv.FormatUint(uint64(f.workspace.ID), 10)
+    if err := f.db.Model(legacy).Update("slug", ref).Error; err != nil {
+        t.Fatalf("set legacy slug: %v", err)
+    }
+
+    workspace, _, err := workspaces.Resolve(ref, f.member.ID)
+    if err != nil || workspace.ID != legacy.ID {
+        t.Errorf("Resolve(%q) = %v, %v, want the workspace with that slug", ref, workspace, err)
+    }
+    workspace, _, err = workspaces.Resolve(strconv.FormatUint(uint64(legacy.ID), 10), f.member.ID)
+    if err != nil || workspace.ID != legacy.ID {
+        t.Errorf("Resolve by ID = %v, %v, want the legacy workspace", workspace, err)
+    }
+
+    if _, err := workspaces.CreateWorkspace(f.member.ID, CreateWorkspaceRequest{Name: "Numbers", Slug: "2024"}); !errors.Is(err, ErrInvalidWorkspaceSlug) {
+        t.Errorf("CreateWorkspace with an all-digit slug: err = %v, want ErrInvalidWorkspaceSlug", err)
+    }
+}
+
+func TestDeleteWorkspaceRemovesIndexEntries(t *testing.T) {
+    db := newTestDB(t)
+    index := searchindex.New()
+    owner := createTestUser(t, db, "owner@example.com", true)
+    workspaces := NewWorkspaceService(db, index)
+
+    workspace, err := workspaces.CreateWorkspace(owner.ID, CreateWorkspaceRequest{Name: "Platform Team"})
+    if err != nil {
+        t.Fatalf("CreateWorkspace: %v", err)
+    }
+    public := true
+    if _, err := NewResourceService(db, nil, nil, index).CreateResource(context.Background(), workspace.ID, owner.ID, CreateResourceRequest{
+        Title:    "Kubernetes runbook",
+        URL:      "https://example.com/runbook",
+        IsPublic: &public,
+    }); err != nil {
+        t.Fatalf("CreateResource: %v", err)
+    }
+
+    if err := workspaces.DeleteWorkspace(workspace.ID, owner.ID); err != nil {
+        t.Fatalf("DeleteWorkspace: %v", err)
+    }
+    if hits := index.Search("runbook", 10); len(hits) != 0 {
+        t.Errorf("index still finds %v after the workspace was deleted", hits)
+    }
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+)
+
+// LocalStore keeps objects as files below a directory.
+type LocalStore struct {
+    dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+    if err := os.MkdirAll(dir, 0o750); err != nil {
+        return nil, err
+    }
+    return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte) error {
+    file, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+        return err
+    }
+
+    // Write to a temporary file first so readers never see half an object
+    tmp, err := os.CreateTemp(filepath.Dir(file), ".tmp-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), file)
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) ([]byte, error) {
+    file, err := s.path(key)
+    if err != nil {
+        return nil, err
+    }
+    data, err := os.ReadFile(file)
+    if errors.Is(err, os.ErrNotExist) {
+        return nil, ErrNotFound
+    }
+    return data, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+    file, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+        return err
+    }
+    return nil
+}
+
+// path maps a key to a file, refusing keys that would escape the directory.
+func (s *LocalStore) path(key string) (string, error) {
+    clean := path.Clean("/" + key)
+    if key == "" || clean != "/"+key || strings.Contains(key, "\\") {
+        return "", fmt.Errorf("invalid storage key %q", key)
+    }
+    return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
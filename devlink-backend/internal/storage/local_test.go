@@ -0,0 +1,79 @@
+package storage
+
+import (
+    "context"
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestLocalStorePath(t *testing.T) {
+    dir := t.TempDir()
+    store, err := NewLocalStore(dir)
+    if err != nil {
+        t.Fatalf("NewLocalStore: %v", err)
+    }
+
+    tests := []struct {
+        key  string
+        want string // empty when the key must be refused
+    }{
+        {"snapshots/1/page.html", filepath.Join(dir, "snapshots", "1", "page.html")},
+        {"page.html", filepath.Join(dir, "page.html")},
+        {"", ""},
+        {"../escape", ""},
+        {"snapshots/../../escape", ""},
+        {"snapshots/../page.html", ""},
+        {"/etc/passwd", ""},
+        {"snapshots//page.html", ""},
+        {"snapshots/./page.html", ""},
+        {"snapshots/", ""},
+        {`..\escape`, ""},
+        {`snapshots\page.html`, ""},
+    }
+    for _, tt := range tests {
+        got, err := store.path(tt.key)
+        if tt.want == "" {
+            if err == nil {
+                t.Errorf("path(%q) = %q, want an error", tt.key, got)
+            }
+            continue
+        }
+        if err != nil || got != tt.want {
+            t.Errorf("path(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+        }
+    }
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+    store, err := NewLocalStore(t.TempDir())
+    if err != nil {
+        t.Fatalf("NewLocalStore: %v", err)
+    }
+    ctx := context.Background()
+
+    if err := store.Put(ctx, "snapshots/1/page.txt", []byte("hello")); err != nil {
+        t.Fatalf("Put: %v", err)
+    }
+    data, err := store.Get(ctx, "snapshots/1/page.txt")
+    if err != nil || string(data) != "hello" {
+        t.Fatalf("Get = %q, %v, want hello", data, err)
+    }
+
+    if err := store.Delete(ctx, "snapshots/1/page.txt"); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if err := store.Delete(ctx, "snapshots/1/page.txt"); err != nil {
+        t.Errorf("deleting a missing key: %v", err)
+    }
+    if _, err := store.Get(ctx, "snapshots/1/page.txt"); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+    }
+    if err := store.Put(ctx, "../outside", []byte("x")); err == nil {
+        t.Error("Put accepted a key outside the store")
+    }
+    if _, err := os.Stat(filepath.Join(filepath.Dir(store.dir), "outside")); !os.IsNotExist(err) {
+        t.Error("Put wrote outside the store")
+    }
+}
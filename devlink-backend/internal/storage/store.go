@@ -0,0 +1,17 @@
+package storage
+
+import (
+    "context"
+    "errors"
+)
+
+// ErrNotFound is returned by Get for keys that hold nothing.
+var ErrNotFound = errors.New("object not found")
+
+// Store keeps blobs such as page snapshots under slash-separated keys.
+// Implementations must be safe for concurrent use.
+type Store interface {
+    Put(ctx context.Context, key string, data []byte) error
+    Get(ctx context.Context, key string) ([]byte, error)
+    Delete(ctx context.Context, key string) error // deleting a missing key is not an error
+}
@@ -0,0 +1,97 @@
+package throttle
+
+import (
+    "context"
+    "sync"
+    "time"
+)
+
+type memoryEntry struct {
+    count       int64
+    expiresAt   time.Time
+    lockedUntil time.Time
+}
+
+// MemoryStore keeps counters in process memory. It is the default and is
+// fine for a single instance; use RedisStore when running several.
+type MemoryStore struct {
+    mu      sync.Mutex
+    entries map[string]*memoryEntry
+    now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+    return &MemoryStore{
+        entries: make(map[string]*memoryEntry),
+        now:     time.Now,
+    }
+}
+
+func (s *MemoryStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := s.now()
+    s.sweep(now)
+
+    entry, ok := s.entries[key]
+    if !ok {
+        entry = &memoryEntry{}
+        s.entries[key] = entry
+    }
+    if entry.count == 0 || now.After(entry.expiresAt) {
+        entry.count = 0
+        entry.expiresAt = now.Add(window)
+    }
+    entry.count++
+
+    return entry.count, nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key string, duration time.Duration) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    entry, ok := s.entries[key]
+    if !ok {
+        entry = &memoryEntry{}
+        s.entries[key] = entry
+    }
+    entry.lockedUntil = s.now().Add(duration)
+
+    return nil
+}
+
+func (s *MemoryStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    entry, ok := s.entries[key]
+    if !ok {
+        return 0, nil
+    }
+
+    remaining := entry.lockedUntil.Sub(s.now())
+    if remaining < 0 {
+        return 0, nil
+    }
+    return remaining, nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    delete(s.entries, key)
+    return nil
+}
+
+// sweep drops entries whose counter and lock have both lapsed, so memory
+// doesn't grow with every IP that ever failed a login.
+func (s *MemoryStore) sweep(now time.Time) {
+    for key, entry := range s.entries {
+        if now.After(entry.expiresAt) && now.After(entry.lockedUntil) {
+            delete(s.entries, key)
+        }
+    }
+}
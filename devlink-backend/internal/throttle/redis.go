@@ -0,0 +1,57 @@
+package throttle
+
+import (
+    "context"
+    "time"
+
+    "github.com/redis/go-redis/v9"
+)
+
+// RedisStore shares counters between instances through Redis.
+type RedisStore struct {
+    client redis.UniversalClient
+    prefix string
+}
+
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+    return &RedisStore{
+        client: client,
+        prefix: prefix,
+    }
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+    countKey := s.prefix + key + ":count"
+
+    // INCR and a conditional PEXPIRE in one round trip; NX keeps the window
+    // anchored at the first failure instead of sliding with every attempt
+    pipe := s.client.TxPipeline()
+    incr := pipe.Incr(ctx, countKey)
+    pipe.ExpireNX(ctx, countKey, window)
+    if _, err := pipe.Exec(ctx); err != nil {
+        return 0, err
+    }
+
+    return incr.Val(), nil
+}
+
+func (s *RedisStore) Lock(ctx context.Context, key string, duration time.Duration) error {
+    return s.client.Set(ctx, s.prefix+key+":lock", 1, duration).Err()
+}
+
+func (s *RedisStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+    ttl, err := s.client.PTTL(ctx, s.prefix+key+":lock").Result()
+    if err != nil {
+        return 0, err
+    }
+
+    // Negative values mean the key is missing or has no expiry
+    if ttl < 0 {
+        return 0, nil
+    }
+    return ttl, nil
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+    return s.client.Del(ctx, s.prefix+key+":count", s.prefix+key+":lock").Err()
+}
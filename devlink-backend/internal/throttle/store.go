@@ -0,0 +1,25 @@
+// Package throttle keeps failure counters and temporary locks for rate
+// limiting, with interchangeable in-memory and Redis backends.
+package throttle
+
+import (
+    "context"
+    "time"
+)
+
+// Store holds per-key failure counters and locks. Keys are opaque strings
+// such as "login:ip:203.0.113.7".
+type Store interface {
+    // Incr bumps the counter for key and returns the new value. The counter
+    // expires window after its first increment.
+    Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+
+    // Lock blocks key for the given duration.
+    Lock(ctx context.Context, key string, duration time.Duration) error
+
+    // LockedFor returns how long key remains locked, or zero if it isn't.
+    LockedFor(ctx context.Context, key string) (time.Duration, error)
+
+    // Reset clears both the counter and any lock for key.
+    Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,152 @@
+package throttle
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/alicebob/miniredis/v2"
+    "github.com/redis/go-redis/v9"
+)
+
+// testStore is a store under test and a way to move its clock forward.
+type testStore struct {
+    Store
+    advance func(time.Duration)
+}
+
+func newMemoryTestStore(t *testing.T) testStore {
+    now := time.Now()
+    store := NewMemoryStore()
+    store.now = func() time.Time { return now }
+    return testStore{store, func(d time.Duration) { now = now.Add(d) }}
+}
+
+// newRedisTestStore runs against miniredis, an in-process Redis stand-in
+// whose clock only moves when told to.
+func newRedisTestStore(t *testing.T) testStore {
+    server := miniredis.RunT(t)
+    return testStore{newRedisStoreFor(t, server), server.FastForward}
+}
+
+func newRedisStoreFor(t *testing.T, server *miniredis.Miniredis) *RedisStore {
+    client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+    t.Cleanup(func() { client.Close() })
+    return NewRedisStore(client, "test:")
+}
+
+func forEachStore(t *testing.T, test func(t *testing.T, store testStore)) {
+    t.Run("memory", func(t *testing.T) { test(t, newMemoryTestStore(t)) })
+    t.Run("redis", func(t *testing.T) { test(t, newRedisTestStore(t)) })
+}
+
+func mustIncr(t *testing.T, store Store, key string, window time.Duration) int64 {
+    t.Helper()
+    count, err := store.Incr(context.Background(), key, window)
+    if err != nil {
+        t.Fatalf("Incr: %v", err)
+    }
+    return count
+}
+
+func mustLockedFor(t *testing.T, store Store, key string) time.Duration {
+    t.Helper()
+    remaining, err := store.LockedFor(context.Background(), key)
+    if err != nil {
+        t.Fatalf("LockedFor: %v", err)
+    }
+    return remaining
+}
+
+func TestStoreCounterWindow(t *testing.T) {
+    forEachStore(t, func(t *testing.T, store testStore) {
+        for want := int64(1); want <= 3; want++ {
+            if got := mustIncr(t, store, "key", time.Hour); got != want {
+                t.Fatalf("count = %d, want %d", got, want)
+            }
+        }
+
+        // The window is anchored at the first failure, later ones don't extend it
+        store.advance(45 * time.Minute)
+        if got := mustIncr(t, store, "key", time.Hour); got != 4 {
+            t.Fatalf("count within the window = %d, want 4", got)
+        }
+        store.advance(20 * time.Minute)
+        if got := mustIncr(t, store, "key", time.Hour); got != 1 {
+            t.Fatalf("count after the window = %d, want 1", got)
+        }
+
+        if got := mustIncr(t, store, "other", time.Hour); got != 1 {
+            t.Errorf("other key count = %d, want 1", got)
+        }
+    })
+}
+
+func TestStoreLock(t *testing.T) {
+    forEachStore(t, func(t *testing.T, store testStore) {
+        if got := mustLockedFor(t, store, "key"); got != 0 {
+            t.Fatalf("unlocked key locked for %s", got)
+        }
+        if err := store.Lock(context.Background(), "key", 15*time.Minute); err != nil {
+            t.Fatalf("Lock: %v", err)
+        }
+        if got := mustLockedFor(t, store, "key"); got <= 14*time.Minute || got > 15*time.Minute {
+            t.Fatalf("locked for %s, want about 15m", got)
+        }
+
+        store.advance(10 * time.Minute)
+        if got := mustLockedFor(t, store, "key"); got <= 4*time.Minute || got > 5*time.Minute {
+            t.Fatalf("locked for %s after 10m, want about 5m", got)
+        }
+        store.advance(6 * time.Minute)
+        if got := mustLockedFor(t, store, "key"); got != 0 {
+            t.Fatalf("lock outlived its duration by %s", got)
+        }
+    })
+}
+
+func TestStoreReset(t *testing.T) {
+    forEachStore(t, func(t *testing.T, store testStore) {
+        ctx := context.Background()
+        mustIncr(t, store, "key", time.Hour)
+        mustIncr(t, store, "key", time.Hour)
+        if err := store.Lock(ctx, "key", time.Minute); err != nil {
+            t.Fatal(err)
+        }
+
+        if err := store.Reset(ctx, "key"); err != nil {
+            t.Fatalf("Reset: %v", err)
+        }
+        if got := mustLockedFor(t, store, "key"); got != 0 {
+            t.Errorf("still locked for %s after reset", got)
+        }
+        if got := mustIncr(t, store, "key", time.Hour); got != 1 {
+            t.Errorf("count after reset = %d, want 1", got)
+        }
+    })
+}
+
+func TestRedisStoreSharedBetweenInstances(t *testing.T) {
+    server := miniredis.RunT(t)
+    first, second := newRedisStoreFor(t, server), newRedisStoreFor(t, server)
+    ctx := context.Background()
+
+    mustIncr(t, first, "login:account:a@example.com", time.Hour)
+    if got := mustIncr(t, second, "login:account:a@example.com", time.Hour); got != 2 {
+        t.Fatalf("second instance count = %d, want 2", got)
+    }
+
+    if err := first.Lock(ctx, "login:account:a@example.com", time.Minute); err != nil {
+        t.Fatal(err)
+    }
+    if got := mustLockedFor(t, second, "login:account:a@example.com"); got == 0 {
+        t.Fatal("lock taken by one instance is not seen by the other")
+    }
+
+    if err := second.Reset(ctx, "login:account:a@example.com"); err != nil {
+        t.Fatal(err)
+    }
+    if got := mustLockedFor(t, first, "login:account:a@example.com"); got != 0 {
+        t.Fatal("reset on one instance did not clear the other's lock")
+    }
+}
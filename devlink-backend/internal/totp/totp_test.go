@@ -0,0 +1,105 @@
+package totp
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+)
+
+// rfcSecret is the SHA-1 seed of RFC 6238 Appendix B, "12345678901234567890".
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+    // Appendix B lists 8-digit codes; 6-digit codes are their last six digits
+    tests := []struct {
+        unix int64
+        want string
+    }{
+        {59, "94287082"},
+        {1111111109, "07081804"},
+        {1111111111, "14050471"},
+        {1234567890, "89005924"},
+        {2000000000, "69279037"},
+        {20000000000, "65353130"},
+    }
+
+    for _, tt := range tests {
+        got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+        if err != nil {
+            t.Fatalf("Code at %d: %v", tt.unix, err)
+        }
+        if want := tt.want[2:]; got != want {
+            t.Errorf("Code at %d = %s, want %s", tt.unix, got, want)
+        }
+    }
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+    upper, _ := Code(rfcSecret, 1)
+    lower, err := Code(strings.ToLower(rfcSecret), 1)
+    if err != nil || lower != upper {
+        t.Errorf("Code with a lowercase secret = %q, %v; want %q", lower, err, upper)
+    }
+    if _, err := Code("not base32!", 1); err == nil {
+        t.Error("Code accepted an invalid secret")
+    }
+}
+
+func TestValidateWindow(t *testing.T) {
+    now := time.Unix(1234567890, 0)
+    current := Step(now)
+
+    for offset := int64(-2); offset <= 2; offset++ {
+        code, _ := Code(rfcSecret, current+offset)
+        step, ok := Validate(rfcSecret, " "+code+" ", now)
+        want := offset >= -1 && offset <= 1
+        if ok != want {
+            t.Errorf("code of step %+d: ok = %v, want %v", offset, ok, want)
+        }
+        if ok && step != current+offset {
+            t.Errorf("code of step %+d matched step %d, want %d", offset, step, current+offset)
+        }
+    }
+
+    for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+        if _, ok := Validate(rfcSecret, code, now); ok {
+            t.Errorf("Validate accepted %q", code)
+        }
+    }
+}
+
+// Validate itself is stateless; callers block replay by remembering the last
+// accepted step. A code must keep reporting the step it belongs to while it
+// is accepted, or such a check could be bypassed.
+func TestValidateReportsStepForReplayChecks(t *testing.T) {
+    now := time.Unix(1234567890, 0)
+    code, _ := Code(rfcSecret, Step(now))
+
+    lastStep := int64(0)
+    accept := func(at time.Time) bool {
+        step, ok := Validate(rfcSecret, code, at)
+        if !ok || step <= lastStep {
+            return false
+        }
+        lastStep = step
+        return true
+    }
+
+    if !accept(now) {
+        t.Fatal("fresh code rejected")
+    }
+    for _, later := range []time.Duration{0, 10 * time.Second, 30 * time.Second} {
+        if accept(now.Add(later)) {
+            t.Errorf("code replayed %s later was accepted", later)
+        }
+    }
+}
+
+func TestURI(t *testing.T) {
+    uri := URI("DevLink", "user@example.com", "ABC")
+    want := fmt.Sprintf("otpauth://totp/DevLink:user@example.com?algorithm=SHA1&digits=%d&issuer=DevLink&period=%d&secret=ABC", digits, period)
+    if uri != want {
+        t.Errorf("URI = %s, want %s", uri, want)
+    }
+}
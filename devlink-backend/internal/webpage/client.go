@@ -0,0 +1,102 @@
+package webpage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+    "net/netip"
+    "syscall"
+    "time"
+)
+
+// ErrBlockedAddress is returned when a URL resolves to an address that
+// user-supplied links must never reach, like localhost or the cloud
+// metadata service.
+var ErrBlockedAddress = errors.New("address is not publicly routable")
+
+const maxRedirects = 5
+
+// Ranges that IsPrivate and friends don't cover but that are still not the
+// public internet.
+var blockedPrefixes = []netip.Prefix{
+    netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+    netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+    netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+    netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+    netip.MustParsePrefix("240.0.0.0/4"),     // reserved
+    netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, can embed private IPv4
+    netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// NewSafeClient returns an HTTP client for fetching user-supplied URLs. It
+// refuses to connect to private, loopback, link-local and other
+// non-public addresses. The check runs on the resolved address at dial
+// time, so DNS tricks and redirects to internal hosts are caught too.
+func NewSafeClient(timeout time.Duration) *http.Client {
+    return newSafeClient(timeout, func(addr netip.AddrPort) bool {
+        return IsPublicAddr(addr.Addr())
+    })
+}
+
+// newSafeClient is NewSafeClient with the address check passed in, so
+// tests can stand in a localhost server for the public internet.
+func newSafeClient(timeout time.Duration, allow func(netip.AddrPort) bool) *http.Client {
+    dialer := &net.Dialer{
+        Timeout: timeout,
+        Control: func(network, address string, _ syscall.RawConn) error {
+            addr, err := netip.ParseAddrPort(address)
+            if err != nil {
+                return err
+            }
+            if !allow(addr) {
+                return fmt.Errorf("%w: %s", ErrBlockedAddress, addr.Addr())
+            }
+            return nil
+        },
+    }
+
+    transport := &http.Transport{
+        // A proxy would be dialed instead of the target and defeat the check
+        Proxy: nil,
+        DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+            return dialer.DialContext(ctx, network, address)
+        },
+        TLSHandshakeTimeout:   timeout,
+        ResponseHeaderTimeout: timeout,
+        MaxIdleConns:          10,
+        IdleConnTimeout:       30 * time.Second,
+    }
+
+    return &http.Client{
+        Timeout:   timeout,
+        Transport: transport,
+        CheckRedirect: func(req *http.Request, via []*http.Request) error {
+            if len(via) >= maxRedirects {
+                return fmt.Errorf("stopped after %d redirects", maxRedirects)
+            }
+            if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+                return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+            }
+            return nil
+        },
+    }
+}
+
+// IsPublicAddr reports whether addr is a unicast address on the public
+// internet.
+func IsPublicAddr(addr netip.Addr) bool {
+    addr = addr.Unmap()
+    if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+        addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+        addr.IsMulticast() {
+        return false
+    }
+    for _, prefix := range blockedPrefixes {
+        if prefix.Contains(addr) {
+            return false
+        }
+    }
+    return true
+}
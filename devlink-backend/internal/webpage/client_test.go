@@ -0,0 +1,144 @@
+package webpage
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "net/netip"
+    "net/url"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+    tests := []struct {
+        addr   string
+        public bool
+    }{
+        {"93.184.216.34", true},
+        {"2606:4700::1111", true},
+        {"127.0.0.1", false},
+        {"::1", false},
+        {"10.1.2.3", false},
+        {"172.16.0.1", false},
+        {"192.168.1.1", false},
+        {"169.254.169.254", false}, // cloud metadata service
+        {"fe80::1", false},
+        {"fc00::1", false},
+        {"0.0.0.0", false},
+        {"100.64.0.1", false},
+        {"198.18.0.1", false},
+        {"224.0.0.1", false},
+        {"255.255.255.255", false},
+        {"::ffff:127.0.0.1", false}, // IPv4-mapped loopback
+        {"::ffff:10.0.0.1", false},
+        {"64:ff9b::a00:1", false}, // NAT64 of 10.0.0.1
+    }
+
+    for _, tt := range tests {
+        if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.public {
+            t.Errorf("IsPublicAddr(%s) = %v, want %v", tt.addr, got, tt.public)
+        }
+    }
+}
+
+// newInternalServer stands in for a service on the private network and
+// records whether anything reached it.
+func newInternalServer(t *testing.T) (*httptest.Server, *atomic.Bool) {
+    t.Helper()
+
+    var hit atomic.Bool
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        hit.Store(true)
+        w.Write([]byte("<title>Internal admin</title>"))
+    }))
+    t.Cleanup(server.Close)
+    return server, &hit
+}
+
+// publicClient treats only server as a public host, blocking every other
+// address like NewSafeClient would.
+func publicClient(server *httptest.Server) *http.Client {
+    public := netip.MustParseAddrPort(server.Listener.Addr().String())
+    return newSafeClient(5*time.Second, func(addr netip.AddrPort) bool {
+        return addr == public || IsPublicAddr(addr.Addr())
+    })
+}
+
+func TestSafeClientBlocksPrivateAddresses(t *testing.T) {
+    internal, hit := newInternalServer(t)
+    fetcher := NewFetcher(nil, 5*time.Second)
+
+    port := url.URL{Host: internal.Listener.Addr().String()}
+    for _, target := range []string{
+        internal.URL,
+        "http://localhost:" + port.Port() + "/",
+        "http://[::1]:" + port.Port() + "/",
+        "http://10.0.0.1/",
+        "http://169.254.169.254/latest/meta-data/",
+        "http://0.0.0.0:" + port.Port() + "/",
+    } {
+        if _, err := fetcher.Fetch(context.Background(), target); !errors.Is(err, ErrBlockedAddress) {
+            t.Errorf("Fetch(%s) = %v, want ErrBlockedAddress", target, err)
+        }
+    }
+    if hit.Load() {
+        t.Error("a request reached the internal server")
+    }
+}
+
+func TestSafeClientBlocksRedirectsToPrivateAddresses(t *testing.T) {
+    internal, hit := newInternalServer(t)
+    redirects := map[string]string{
+        "/internal": internal.URL + "/admin",
+        "/metadata": "http://169.254.169.254/latest/meta-data/",
+        "/loopback": "http://127.0.0.1/",
+    }
+    public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        http.Redirect(w, r, redirects[r.URL.Path], http.StatusFound)
+    }))
+    defer public.Close()
+    fetcher := NewFetcher(publicClient(public), 0)
+
+    for path := range redirects {
+        if _, err := fetcher.Fetch(context.Background(), public.URL+path); !errors.Is(err, ErrBlockedAddress) {
+            t.Errorf("Fetch(%s) = %v, want ErrBlockedAddress", path, err)
+        }
+    }
+    if hit.Load() {
+        t.Error("a redirect reached the internal server")
+    }
+}
+
+func TestSafeClientRedirectLimits(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/file":
+            http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+        case "/loop":
+            http.Redirect(w, r, "/loop", http.StatusFound)
+        case "/hop":
+            http.Redirect(w, r, "/page", http.StatusMovedPermanently)
+        default:
+            w.Write([]byte("<title>Page</title>"))
+        }
+    }))
+    defer server.Close()
+    fetcher := NewFetcher(publicClient(server), 0)
+
+    for _, path := range []string{"/file", "/loop"} {
+        if _, err := fetcher.Fetch(context.Background(), server.URL+path); !errors.Is(err, ErrFetchFailed) {
+            t.Errorf("Fetch(%s) = %v, want ErrFetchFailed", path, err)
+        }
+    }
+
+    meta, err := fetcher.Fetch(context.Background(), server.URL+"/hop")
+    if err != nil {
+        t.Fatalf("Fetch(/hop): %v", err)
+    }
+    if meta.URL != server.URL+"/page" || meta.Title != "Page" {
+        t.Errorf("Fetch(/hop) = %q at %s, want the redirect target", meta.Title, meta.URL)
+    }
+}
@@ -0,0 +1,237 @@
+package webpage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "mime"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+
+    "golang.org/x/net/html"
+    "golang.org/x/net/html/charset"
+)
+
+// DefaultMaxBytes caps how much of a page is read. Everything the fetcher
+// looks for lives in <head>, which is almost always well within this.
+const DefaultMaxBytes = 1 << 20
+
+const userAgent = "DevLinkBot/1.0 (+link preview)"
+
+var (
+    ErrUnsupportedURL = errors.New("only http and https URLs can be fetched")
+    ErrFetchFailed    = errors.New("could not fetch the page")
+)
+
+// Metadata is what a page says about itself.
+type Metadata struct {
+    URL          string `json:"url"`           // final URL after redirects
+    CanonicalURL string `json:"canonical_url"` // from <link rel="canonical"> or og:url
+    Title        string `json:"title"`
+    Description  string `json:"description"`
+    SiteName     string `json:"site_name"`
+    Image        string `json:"image"`
+    FaviconURL   string `json:"favicon_url"`
+}
+
+// Fetcher downloads pages and extracts their metadata.
+type Fetcher struct {
+    client   *http.Client
+    maxBytes int64
+}
+
+// NewFetcher returns a fetcher using client, or a client from
+// NewSafeClient with the given timeout when client is nil. Tests pass
+// their own client to reach httptest servers on localhost.
+func NewFetcher(client *http.Client, timeout time.Duration) *Fetcher {
+    if client == nil {
+        client = NewSafeClient(timeout)
+    }
+    return &Fetcher{
+        client:   client,
+        maxBytes: DefaultMaxBytes,
+    }
+}
+
+// Fetch retrieves rawURL and parses its metadata. Pages that aren't HTML
+// yield just the final URL and the site's default favicon.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Metadata, error) {
+    resp, err := f.get(ctx, rawURL)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    meta := &Metadata{URL: resp.Request.URL.String()}
+
+    contentType := resp.Header.Get("Content-Type")
+    if !isHTML(contentType) {
+        meta.FaviconURL = resolve(resp.Request.URL, "/favicon.ico")
+        return meta, nil
+    }
+
+    body, err := charset.NewReader(io.LimitReader(resp.Body, f.maxBytes), contentType)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+    }
+    parseHead(body, resp.Request.URL, meta)
+
+    return meta, nil
+}
+
+// get requests an http(s) URL and fails on error statuses. The caller
+// closes the body.
+func (f *Fetcher) get(ctx context.Context, rawURL string) (*http.Response, error) {
+    target, err := url.Parse(rawURL)
+    if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+        return nil, ErrUnsupportedURL
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("User-Agent", userAgent)
+    req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+
+    resp, err := f.client.Do(req)
+    if err != nil {
+        if errors.Is(err, ErrBlockedAddress) {
+            return nil, ErrBlockedAddress
+        }
+        return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+    }
+
+    if resp.StatusCode >= 400 {
+        resp.Body.Close()
+        return nil, fmt.Errorf("%w: status %d", ErrFetchFailed, resp.StatusCode)
+    }
+
+    return resp, nil
+}
+
+// isHTML treats a missing Content-Type as HTML, as browsers would sniff it.
+func isHTML(contentType string) bool {
+    mediaType, _, _ := mime.ParseMediaType(contentType)
+    return mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// parseHead fills meta from the document's <head>, preferring Open Graph
+// and Twitter card values over the plain title and description. A
+// truncated document is fine; parsing just stops early.
+func parseHead(r io.Reader, base *url.URL, meta *Metadata) {
+    var title, description, canonical, icon string
+    og := make(map[string]string)
+
+    tokenizer := html.NewTokenizer(r)
+    inTitle := false
+scan:
+    for {
+        tt := tokenizer.Next()
+        if tt == html.ErrorToken {
+            break
+        }
+
+        token := tokenizer.Token()
+        switch tt {
+        case html.StartTagToken, html.SelfClosingTagToken:
+            switch token.Data {
+            case "title":
+                inTitle = title == ""
+            case "meta":
+                key := strings.ToLower(attr(token, "property"))
+                if key == "" {
+                    key = strings.ToLower(attr(token, "name"))
+                }
+                content := strings.TrimSpace(attr(token, "content"))
+                if content == "" {
+                    continue
+                }
+                if key == "description" && description == "" {
+                    description = content
+                } else if strings.HasPrefix(key, "og:") || strings.HasPrefix(key, "twitter:") {
+                    if _, seen := og[key]; !seen {
+                        og[key] = content
+                    }
+                }
+            case "link":
+                href := strings.TrimSpace(attr(token, "href"))
+                if href == "" {
+                    continue
+                }
+                for _, rel := range strings.Fields(strings.ToLower(attr(token, "rel"))) {
+                    switch {
+                    case rel == "canonical" && canonical == "":
+                        canonical = href
+                    case rel == "icon" && icon == "":
+                        icon = href
+                    case rel == "apple-touch-icon" && icon == "":
+                        icon = href
+                    }
+                }
+            case "body":
+                // Everything of interest is in <head>
+                break scan
+            }
+        case html.TextToken:
+            if inTitle {
+                title += token.Data
+            }
+        case html.EndTagToken:
+            if token.Data == "title" {
+                inTitle = false
+            } else if token.Data == "head" {
+                break scan
+            }
+        }
+    }
+
+    meta.Title = first(og["og:title"], og["twitter:title"], collapseSpace(title))
+    meta.Description = first(og["og:description"], og["twitter:description"], description)
+    meta.SiteName = og["og:site_name"]
+    if image := first(og["og:image"], og["og:image:url"], og["twitter:image"], og["twitter:image:src"]); image != "" {
+        meta.Image = resolve(base, image)
+    }
+    if canonical = first(canonical, og["og:url"]); canonical != "" {
+        meta.CanonicalURL = resolve(base, canonical)
+    }
+    meta.FaviconURL = resolve(base, first(icon, "/favicon.ico"))
+}
+
+func attr(token html.Token, name string) string {
+    for _, a := range token.Attr {
+        if a.Key == name {
+            return a.Val
+        }
+    }
+    return ""
+}
+
+// resolve makes ref absolute against base. Only http(s) results are kept,
+// so a page can't smuggle in javascript: or data: URLs.
+func resolve(base *url.URL, ref string) string {
+    if ref == "" {
+        return ""
+    }
+    u, err := base.Parse(ref)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+        return ""
+    }
+    return u.String()
+}
+
+func first(values ...string) string {
+    for _, v := range values {
+        if v != "" {
+            return v
+        }
+    }
+    return ""
+}
+
+func collapseSpace(s string) string {
+    return strings.Join(strings.Fields(s), " ")
+}
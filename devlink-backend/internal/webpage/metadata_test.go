@@ -0,0 +1,138 @@
+package webpage
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>
+    Plain   title
+  </title>
+  <meta name="description" content="Plain description">
+  <meta property="og:title" content="Open Graph title">
+  <meta property="og:site_name" content="Example Docs">
+  <meta name="twitter:image" content="/images/card.png">
+  <link rel="canonical" href="/guide">
+  <link rel="shortcut icon" href="/static/icon.svg">
+  <link rel="stylesheet" href="javascript:alert(1)">
+</head>
+<body>
+  <meta property="og:description" content="Ignored, outside head">
+</body>
+</html>`
+
+func newPageServer(t *testing.T) *httptest.Server {
+    t.Helper()
+
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("User-Agent") != userAgent {
+            http.Error(w, "unknown client", http.StatusForbidden)
+            return
+        }
+        switch r.URL.Path {
+        case "/article":
+            w.Header().Set("Content-Type", "text/html; charset=utf-8")
+            w.Write([]byte(articlePage))
+        case "/moved":
+            http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+        case "/latin1":
+            w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+            w.Write([]byte("<title>Caf\xe9 cr\xe8me</title>"))
+        case "/script":
+            w.Header().Set("Content-Type", "text/html")
+            w.Write([]byte(`<title>x</title><link rel="icon" href="javascript:alert(1)">` +
+                `<meta property="og:image" content="data:image/png;base64,AAAA">`))
+        case "/report.pdf":
+            w.Header().Set("Content-Type", "application/pdf")
+            w.Write([]byte("%PDF-1.7"))
+        case "/huge":
+            w.Header().Set("Content-Type", "text/html")
+            w.Write([]byte("<head>" + strings.Repeat("<meta name=x content=y>", 10000) + "<title>Too far</title>"))
+        default:
+            http.NotFound(w, r)
+        }
+    }))
+    t.Cleanup(server.Close)
+    return server
+}
+
+func TestFetchMetadata(t *testing.T) {
+    server := newPageServer(t)
+    fetcher := NewFetcher(publicClient(server), 0)
+
+    meta, err := fetcher.Fetch(context.Background(), server.URL+"/moved")
+    if err != nil {
+        t.Fatalf("Fetch: %v", err)
+    }
+    want := Metadata{
+        URL:          server.URL + "/article",
+        CanonicalURL: server.URL + "/guide",
+        Title:        "Open Graph title",
+        Description:  "Plain description",
+        SiteName:     "Example Docs",
+        Image:        server.URL + "/images/card.png",
+        FaviconURL:   server.URL + "/static/icon.svg",
+    }
+    if *meta != want {
+        t.Errorf("Fetch = %+v\nwant %+v", *meta, want)
+    }
+}
+
+func TestFetchMetadataEdgeCases(t *testing.T) {
+    server := newPageServer(t)
+    fetcher := NewFetcher(publicClient(server), 0)
+    fetcher.maxBytes = 4096
+
+    meta, err := fetcher.Fetch(context.Background(), server.URL+"/latin1")
+    if err != nil {
+        t.Fatalf("Fetch(/latin1): %v", err)
+    }
+    if meta.Title != "Café crème" {
+        t.Errorf("Latin-1 title = %q, want it decoded", meta.Title)
+    }
+
+    meta, err = fetcher.Fetch(context.Background(), server.URL+"/script")
+    if err != nil {
+        t.Fatalf("Fetch(/script): %v", err)
+    }
+    if meta.Image != "" || meta.FaviconURL != "" {
+        t.Errorf("non-http URLs kept: image %q, favicon %q", meta.Image, meta.FaviconURL)
+    }
+
+    meta, err = fetcher.Fetch(context.Background(), server.URL+"/report.pdf")
+    if err != nil {
+        t.Fatalf("Fetch(/report.pdf): %v", err)
+    }
+    if meta.Title != "" || meta.FaviconURL != server.URL+"/favicon.ico" {
+        t.Errorf("non-HTML page = %+v, want only the URL and default favicon", *meta)
+    }
+
+    meta, err = fetcher.Fetch(context.Background(), server.URL+"/huge")
+    if err != nil {
+        t.Fatalf("Fetch(/huge): %v", err)
+    }
+    if meta.Title != "" {
+        t.Errorf("title past maxBytes was read: %q", meta.Title)
+    }
+}
+
+func TestFetchErrors(t *testing.T) {
+    server := newPageServer(t)
+    fetcher := NewFetcher(publicClient(server), 0)
+
+    if _, err := fetcher.Fetch(context.Background(), server.URL+"/missing"); !errors.Is(err, ErrFetchFailed) {
+        t.Errorf("Fetch of a 404 = %v, want ErrFetchFailed", err)
+    }
+    for _, target := range []string{"ftp://example.com/file", "file:///etc/passwd", "javascript:alert(1)", "http://", "not a url"} {
+        if _, err := fetcher.Fetch(context.Background(), target); !errors.Is(err, ErrUnsupportedURL) {
+            t.Errorf("Fetch(%q) = %v, want ErrUnsupportedURL", target, err)
+        }
+    }
+}
@@ -0,0 +1,336 @@
+package webpage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net/url"
+    "regexp"
+    "strings"
+
+    "golang.org/x/net/html"
+    "golang.org/x/net/html/atom"
+    "golang.org/x/net/html/charset"
+)
+
+// MaxArticleBytes caps how much of a page is read for a snapshot.
+const MaxArticleBytes = 5 << 20
+
+var (
+    ErrNotHTML   = errors.New("the page is not an HTML document")
+    ErrNoContent = errors.New("no readable content found on the page")
+)
+
+// Article is the main readable content of a page, without navigation,
+// ads, scripts and styling.
+type Article struct {
+    URL   string // final URL after redirects
+    Title string
+    HTML  string // cleaned-up markup of the content, with absolute links
+    Text  string // plain text, paragraphs separated by blank lines
+}
+
+// Elements that never hold the article.
+var strippedTags = map[atom.Atom]bool{
+    atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+    atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Svg: true, atom.Canvas: true,
+    atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true,
+    atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true, atom.Dialog: true,
+}
+
+// Elements kept in the cleaned markup; anything else is unwrapped.
+var keptTags = map[atom.Atom]bool{
+    atom.P: true, atom.Br: true, atom.Hr: true, atom.A: true, atom.Img: true,
+    atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+    atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+    atom.Pre: true, atom.Code: true, atom.Blockquote: true, atom.Em: true, atom.Strong: true,
+    atom.B: true, atom.I: true, atom.Sub: true, atom.Sup: true, atom.Figure: true, atom.Figcaption: true,
+    atom.Table: true, atom.Thead: true, atom.Tbody: true, atom.Tr: true, atom.Th: true, atom.Td: true,
+}
+
+// Elements that start a new paragraph in the plain text.
+var blockTags = map[atom.Atom]bool{
+    atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true,
+    atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+    atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+    atom.Pre: true, atom.Blockquote: true, atom.Figure: true, atom.Figcaption: true,
+    atom.Table: true, atom.Tr: true, atom.Hr: true, atom.Br: true,
+}
+
+var (
+    unlikelyCandidate = regexp.MustCompile(`(?i)comment|sidebar|footer|masthead|menu|nav|share|social|promo|advert|sponsor|cookie|banner|related|subscribe|newsletter|popup|modal|breadcrumb`)
+    maybeCandidate    = regexp.MustCompile(`(?i)article|content|main|post|entry|body|story|text`)
+)
+
+// Article fetches rawURL and extracts its readable content.
+func (f *Fetcher) Article(ctx context.Context, rawURL string) (*Article, error) {
+    resp, err := f.get(ctx, rawURL)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+
+    contentType := resp.Header.Get("Content-Type")
+    if !isHTML(contentType) {
+        return nil, ErrNotHTML
+    }
+
+    body, err := charset.NewReader(io.LimitReader(resp.Body, MaxArticleBytes), contentType)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+    }
+
+    article, err := Extract(body, resp.Request.URL)
+    if err != nil {
+        return nil, err
+    }
+    article.URL = resp.Request.URL.String()
+    return article, nil
+}
+
+// Extract finds the main content of an HTML document, readability style:
+// an <article> or <main> element when the page has one, otherwise the
+// element whose paragraphs hold the most text and the fewest links.
+func Extract(r io.Reader, base *url.URL) (*Article, error) {
+    doc, err := html.Parse(r)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
+    }
+
+    title := ""
+    if node := findFirst(doc, func(n *html.Node) bool { return n.DataAtom == atom.Title }); node != nil {
+        title = collapseSpace(textContent(node))
+    }
+
+    body := findFirst(doc, func(n *html.Node) bool { return n.DataAtom == atom.Body })
+    if body == nil {
+        body = doc
+    }
+    strip(body)
+
+    content := findFirst(body, func(n *html.Node) bool {
+        return n.DataAtom == atom.Article || n.DataAtom == atom.Main || attr(nodeToken(n), "role") == "main"
+    })
+    if content == nil || len(strings.TrimSpace(textContent(content))) < 200 {
+        if best := bestCandidate(body); best != nil {
+            content = best
+        } else if content == nil {
+            content = body
+        }
+    }
+
+    var markup strings.Builder
+    renderClean(&markup, content, base)
+
+    var text strings.Builder
+    writeText(&text, content, false)
+
+    article := &Article{
+        Title: title,
+        HTML:  strings.TrimSpace(markup.String()),
+        Text:  normalizeText(text.String()),
+    }
+    if article.Text == "" {
+        return nil, ErrNoContent
+    }
+    return article, nil
+}
+
+// strip removes elements that are never content, along with blocks whose
+// class or id marks them as page furniture.
+func strip(n *html.Node) {
+    for child := n.FirstChild; child != nil; {
+        next := child.NextSibling
+        if child.Type == html.CommentNode {
+            n.RemoveChild(child)
+        } else if child.Type == html.ElementNode {
+            hint := attr(nodeToken(child), "class") + " " + attr(nodeToken(child), "id")
+            if strippedTags[child.DataAtom] ||
+                (child.DataAtom != atom.Body && child.DataAtom != atom.Article && child.DataAtom != atom.Main &&
+                    unlikelyCandidate.MatchString(hint) && !maybeCandidate.MatchString(hint)) {
+                n.RemoveChild(child)
+            } else {
+                strip(child)
+            }
+        }
+        child = next
+    }
+}
+
+// bestCandidate scores the parents of every substantial paragraph by how
+// much text they hold and returns the highest scorer, discounted by how
+// much of its text is link text.
+func bestCandidate(root *html.Node) *html.Node {
+    scores := make(map[*html.Node]float64)
+    var walk func(*html.Node)
+    walk = func(n *html.Node) {
+        if n.Type == html.ElementNode && (n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Td) {
+            text := strings.TrimSpace(textContent(n))
+            if len(text) >= 25 {
+                score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+                if parent := n.Parent; parent != nil {
+                    scores[parent] += score
+                    if grandparent := parent.Parent; grandparent != nil {
+                        scores[grandparent] += score / 2
+                    }
+                }
+            }
+        }
+        for child := n.FirstChild; child != nil; child = child.NextSibling {
+            walk(child)
+        }
+    }
+    walk(root)
+
+    var best *html.Node
+    bestScore := 0.0
+    for node, score := range scores {
+        score *= 1 - linkDensity(node)
+        if score > bestScore {
+            best, bestScore = node, score
+        }
+    }
+    return best
+}
+
+func linkDensity(n *html.Node) float64 {
+    total := len(textContent(n))
+    if total == 0 {
+        return 0
+    }
+    links := 0
+    var walk func(*html.Node)
+    walk = func(n *html.Node) {
+        if n.Type == html.ElementNode && n.DataAtom == atom.A {
+            links += len(textContent(n))
+            return
+        }
+        for child := n.FirstChild; child != nil; child = child.NextSibling {
+            walk(child)
+        }
+    }
+    walk(n)
+    return float64(links) / float64(total)
+}
+
+// renderClean writes n's content keeping only structural tags and the
+// attributes needed for links and images, so a snapshot can't run scripts
+// or pull in the original site's styling.
+func renderClean(w *strings.Builder, n *html.Node, base *url.URL) {
+    for child := n.FirstChild; child != nil; child = child.NextSibling {
+        switch child.Type {
+        case html.TextNode:
+            w.WriteString(html.EscapeString(child.Data))
+        case html.ElementNode:
+            if !keptTags[child.DataAtom] {
+                renderClean(w, child, base)
+                continue
+            }
+
+            tag := child.DataAtom.String()
+            w.WriteString("<" + tag)
+            switch child.DataAtom {
+            case atom.A:
+                if href := resolve(base, attr(nodeToken(child), "href")); href != "" {
+                    w.WriteString(` href="` + html.EscapeString(href) + `" rel="nofollow noopener"`)
+                }
+            case atom.Img:
+                src := resolve(base, attr(nodeToken(child), "src"))
+                if src == "" {
+                    w.WriteString(">")
+                    continue
+                }
+                w.WriteString(` src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(attr(nodeToken(child), "alt")) + `"`)
+            }
+            w.WriteString(">")
+
+            if child.DataAtom == atom.Br || child.DataAtom == atom.Hr || child.DataAtom == atom.Img {
+                continue
+            }
+            renderClean(w, child, base)
+            w.WriteString("</" + tag + ">")
+        }
+    }
+}
+
+// writeText appends n's text, marking paragraph breaks with blank lines.
+// Whitespace is kept as is inside <pre>.
+func writeText(w *strings.Builder, n *html.Node, pre bool) {
+    for child := n.FirstChild; child != nil; child = child.NextSibling {
+        switch child.Type {
+        case html.TextNode:
+            if pre {
+                w.WriteString(child.Data)
+            } else {
+                words := strings.Fields(child.Data)
+                if len(words) == 0 {
+                    writeSpace(w)
+                    continue
+                }
+                if strings.TrimLeft(child.Data, " \t\r\n") != child.Data {
+                    writeSpace(w)
+                }
+                w.WriteString(strings.Join(words, " "))
+                if strings.TrimRight(child.Data, " \t\r\n") != child.Data {
+                    writeSpace(w)
+                }
+            }
+        case html.ElementNode:
+            block := blockTags[child.DataAtom]
+            if block {
+                w.WriteString("\n\n")
+            }
+            writeText(w, child, pre || child.DataAtom == atom.Pre)
+            if block {
+                w.WriteString("\n\n")
+            }
+        }
+    }
+}
+
+// writeSpace separates words without doubling up on whitespace.
+func writeSpace(w *strings.Builder) {
+    if s := w.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+        w.WriteString(" ")
+    }
+}
+
+// normalizeText trims every paragraph and collapses runs of blank lines.
+func normalizeText(s string) string {
+    var paragraphs []string
+    for _, paragraph := range strings.Split(s, "\n\n") {
+        if paragraph = strings.Trim(paragraph, " \t\r\n"); paragraph != "" {
+            paragraphs = append(paragraphs, paragraph)
+        }
+    }
+    return strings.Join(paragraphs, "\n\n")
+}
+
+func findFirst(n *html.Node, match func(*html.Node) bool) *html.Node {
+    if n.Type == html.ElementNode && match(n) {
+        return n
+    }
+    for child := n.FirstChild; child != nil; child = child.NextSibling {
+        if found := findFirst(child, match); found != nil {
+            return found
+        }
+    }
+    return nil
+}
+
+func textContent(n *html.Node) string {
+    if n.Type == html.TextNode {
+        return n.Data
+    }
+    var b strings.Builder
+    for child := n.FirstChild; child != nil; child = child.NextSibling {
+        b.WriteString(textContent(child))
+    }
+    return b.String()
+}
+
+// nodeToken adapts a parsed node for attr.
+func nodeToken(n *html.Node) html.Token {
+    return html.Token{Attr: n.Attr}
+}
@@ -0,0 +1,143 @@
+package webpage
+
+import (
+    "errors"
+    "net/url"
+    "strings"
+    "testing"
+)
+
+// filler is one substantial paragraph, long enough to count as content.
+const filler = "Goroutines are cheap, so a server can start one per request, and the scheduler spreads them over a few threads."
+
+func TestExtract(t *testing.T) {
+    base, _ := url.Parse("https://example.com/blog/post")
+
+    tests := []struct {
+        name    string
+        page    string
+        text    []string // substrings the text must contain
+        notText []string // substrings it must not contain
+        html    []string // substrings the markup must contain
+        notHTML []string // substrings it must not contain
+        wantErr error
+    }{
+        {
+            name: "article element wins over the rest of the page",
+            page: `<html><head><title> Go  notes </title></head><body>
+                <nav><p>Home, Blog, About, Contact, and a long list of other pages</p></nav>
+                <article><h1>Concurrency</h1><p>` + filler + `</p><p>` + filler + `</p></article>
+                <div class="comments"><p>` + filler + ` Great post!</p></div>
+                </body></html>`,
+            text:    []string{"Concurrency", "Goroutines are cheap"},
+            notText: []string{"Home, Blog", "Great post"},
+        },
+        {
+            name: "without an article the densest text block wins",
+            page: `<html><body>
+                <div id="links"><p><a href="/a">` + filler + `</a></p><p><a href="/b">` + filler + `</a></p></div>
+                <div id="story"><p>` + filler + `</p><p>Channels, mutexes, and wait groups, each with its place.</p></div>
+                </body></html>`,
+            text:    []string{"Channels, mutexes"},
+            html:    []string{"<p>Channels, mutexes"},
+            notHTML: []string{`href="https://example.com/a"`},
+        },
+        {
+            name: "a short main element falls back to the best candidate",
+            page: `<html><body>
+                <main><p>Loading...</p></main>
+                <div class="post"><p>` + filler + `</p><p>` + filler + `</p></div>
+                </body></html>`,
+            text:    []string{"Goroutines are cheap"},
+            notText: []string{"Loading"},
+        },
+        {
+            name: "scripts, styles, forms and page furniture are stripped",
+            page: `<html><body><article>
+                <script>alert("script")</script><style>p { color: red }</style>
+                <form><input value="form"><button>Subscribe</button></form>
+                <iframe src="https://ads.example.com">frame</iframe>
+                <div class="sidebar">Popular posts</div>
+                <div class="content sidebar"><p>` + filler + `</p></div>
+                <!-- a comment -->
+                <p style="color: red" onclick="steal()">` + filler + `</p>
+                </article></body></html>`,
+            text:    []string{"Goroutines are cheap"},
+            notText: []string{"alert", "color", "Subscribe", "frame", "Popular posts", "a comment"},
+            notHTML: []string{"<script", "<style", "<form", "<iframe", "style=", "onclick", "<div"},
+        },
+        {
+            name: "links are made absolute and unsafe ones lose their href",
+            page: `<html><body><article><p>` + filler + `</p><p>
+                <a href="javascript:alert(1)">run me</a>
+                <a href="../about">about</a>
+                <img src="data:image/png;base64,AAAA" alt="inline">
+                <img src="/img/gopher.png" alt="gopher">
+                </p></article></body></html>`,
+            html: []string{
+                "<a>run me</a>",
+                `<a href="https://example.com/about" rel="nofollow noopener">about</a>`,
+                `<img src="https://example.com/img/gopher.png" alt="gopher">`,
+            },
+            notHTML: []string{"javascript:", "data:"},
+        },
+        {
+            name:    "pages without text have no content",
+            page:    `<html><body><script>document.write("hi")</script><nav>Menu</nav></body></html>`,
+            wantErr: ErrNoContent,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            article, err := Extract(strings.NewReader(tt.page), base)
+            if tt.wantErr != nil {
+                if !errors.Is(err, tt.wantErr) {
+                    t.Fatalf("err = %v, want %v", err, tt.wantErr)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Extract: %v", err)
+            }
+
+            for _, want := range tt.text {
+                if !strings.Contains(article.Text, want) {
+                    t.Errorf("text is missing %q:\n%s", want, article.Text)
+                }
+            }
+            for _, unwanted := range tt.notText {
+                if strings.Contains(article.Text, unwanted) {
+                    t.Errorf("text contains %q:\n%s", unwanted, article.Text)
+                }
+            }
+            for _, want := range tt.html {
+                if !strings.Contains(article.HTML, want) {
+                    t.Errorf("markup is missing %q:\n%s", want, article.HTML)
+                }
+            }
+            for _, unwanted := range tt.notHTML {
+                if strings.Contains(article.HTML, unwanted) {
+                    t.Errorf("markup contains %q:\n%s", unwanted, article.HTML)
+                }
+            }
+        })
+    }
+}
+
+func TestExtractTitleAndParagraphs(t *testing.T) {
+    base, _ := url.Parse("https://example.com/")
+    article, err := Extract(strings.NewReader(`<html><head><title>
+        Go   notes
+    </title></head><body><article><h1>Intro</h1><p>First   line</p><pre>  keep
+  spacing</pre></article></body></html>`), base)
+    if err != nil {
+        t.Fatalf("Extract: %v", err)
+    }
+    if article.Title != "Go notes" {
+        t.Errorf("title = %q, want %q", article.Title, "Go notes")
+    }
+    if want := "Intro\n\nFirst line\n\nkeep\n  spacing"; article.Text != want {
+        t.Errorf("text = %q, want %q", article.Text, want)
+    }
+}
@@ -41,6 +41,7 @@ export default function ResourceCard({ resource, onEdit, onDelete, onVisit }) {
 
   const formatTags = (tags) => {
     if (!tags) return [];
+    if (Array.isArray(tags)) return tags;
     return tags
       .split(",")
       .map((tag) => tag.trim())
@@ -57,7 +58,12 @@ export default function ResourceCard({ resource, onEdit, onDelete, onVisit }) {
         <div className="flex items-start justify-between">
           <div className="flex-1 min-w-0">
             <CardTitle className="text-lg leading-6 truncate">
-              {resource.title}
+              {resource.highlight ? (
+                // The API escapes highlights and only adds <mark> tags
+                <span dangerouslySetInnerHTML={{ __html: resource.highlight.title }} />
+              ) : (
+                resource.title
+              )}
             </CardTitle>
             <CardDescription className="mt-1">
               <div className="flex items-center space-x-2">
@@ -108,10 +114,17 @@ export default function ResourceCard({ resource, onEdit, onDelete, onVisit }) {
       </CardHeader>
 
       <CardContent>
-        {resource.description && (
-          <p className="text-sm text-gray-600 mb-3 line-clamp-2">
-            {resource.description}
-          </p>
+        {resource.highlight?.snippet ? (
+          <p
+            className="text-sm text-gray-600 mb-3 line-clamp-2"
+            dangerouslySetInnerHTML={{ __html: resource.highlight.snippet }}
+          />
+        ) : (
+          resource.description && (
+            <p className="text-sm text-gray-600 mb-3 line-clamp-2">
+              {resource.description}
+            </p>
+          )
         )}
 
         <div className="flex flex-wrap gap-1 mb-3">
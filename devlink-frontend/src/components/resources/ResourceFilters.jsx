@@ -82,7 +82,7 @@ export default function ResourceFilters({ onFilter, initialFilters = {} }) {
                 name="search"
                 value={filters.search}
                 onChange={handleInputChange}
-                placeholder="Search resources..."
+                placeholder='Search, e.g. tag:go is:public "context cancel"'
                 className="pl-10"
               />
             </div>
@@ -48,12 +48,18 @@ export default function ResourceForm({
     category: "",
     tags: "",
     is_public: false,
+    archive: false,
   });
   const [error, setError] = useState("");
 
   useEffect(() => {
     if (initialData) {
-      setFormData(initialData);
+      setFormData({
+        ...initialData,
+        tags: Array.isArray(initialData.tags)
+          ? initialData.tags.join(", ")
+          : initialData.tags || "",
+      });
     } else {
       setFormData({
         title: "",
@@ -62,6 +68,7 @@ export default function ResourceForm({
         category: "",
         tags: "",
         is_public: false,
+        archive: false,
       });
     }
     setError("");
@@ -86,9 +93,9 @@ export default function ResourceForm({
     e.preventDefault();
     setError("");
 
-    // Basic validation
-    if (!formData.title.trim() || !formData.url.trim()) {
-      setError("Title and URL are required");
+    // Basic validation (new resources get their title from the page)
+    if (!formData.url.trim() || (initialData && !formData.title.trim())) {
+      setError(initialData ? "Title and URL are required" : "URL is required");
       return;
     }
 
@@ -132,14 +139,14 @@ export default function ResourceForm({
 
           <div className="grid grid-cols-2 gap-4">
             <div className="col-span-2">
-              <Label htmlFor="title" className="mb-2">Title *</Label>
+              <Label htmlFor="title" className="mb-2">Title{initialData && " *"}</Label>
               <Input
                 id="title"
                 name="title"
                 value={formData.title}
                 onChange={handleChange}
-                placeholder="e.g., React Official Documentation"
-                required
+                placeholder={initialData ? "e.g., React Official Documentation" : "Leave empty to use the page's title"}
+                required={!!initialData}
               />
             </div>
 
@@ -212,6 +219,18 @@ export default function ResourceForm({
               />
               <Label htmlFor="is_public">Make this resource public</Label>
             </div>
+
+            <div className="col-span-2 flex items-center space-x-2">
+              <input
+                id="archive"
+                name="archive"
+                type="checkbox"
+                checked={formData.archive}
+                onChange={handleChange}
+                className="rounded"
+              />
+              <Label htmlFor="archive">Keep snapshots of this page</Label>
+            </div>
           </div>
 
           <div className="flex justify-end space-x-2 pt-4">
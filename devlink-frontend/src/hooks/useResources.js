@@ -1,6 +1,27 @@
 import { useState, useEffect } from "react";
 import { resourceAPI } from "../services/api";
 
+// Folds the filter controls into the search syntax the API expects in `q`,
+// e.g. `tag:go is:public "context cancel"`.
+const quote = (value) => (/[\s,]/.test(value) ? `"${value}"` : value);
+
+export function toQuery({ search, category, tags, is_public }) {
+  const terms = [];
+  if (search?.trim()) terms.push(search.trim());
+  if (category) terms.push(`category:${quote(category)}`);
+  if (tags?.trim()) {
+    tags
+      .split(",")
+      .map((tag) => tag.trim())
+      .filter(Boolean)
+      .forEach((tag) => terms.push(`tag:${quote(tag)}`));
+  }
+  if (is_public !== null && is_public !== undefined) {
+    terms.push(is_public ? "is:public" : "is:private");
+  }
+  return terms.join(" ");
+}
+
 export function useResources() {
   const [resources, setResources] = useState([]);
   const [loading, setLoading] = useState(false);
@@ -21,7 +42,7 @@ export function useResources() {
     setLoading(true);
     try {
       const params = {
-        ...newFilters,
+        q: toQuery(newFilters),
         page: newPage,
         limit: pagination.limit,
       };